@@ -1,27 +1,159 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+	"github.com/its-jojoo/otterclip/internal/adapter/paste"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/adapter/tray"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/dbusapi"
+	"github.com/its-jojoo/otterclip/internal/desktop"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/queue"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-// App struct
-type App struct {
-	ctx context.Context
+// defaultIgnore matches the CLI's default privacy patterns; more can be
+// added from the app's settings.
+var defaultIgnore = []string{"password=", "token=", "apikey=", "secret=", "authorization: bearer"}
+
+// dbPath is where the desktop app keeps its history: $OTTERCLIP_DB, or
+// otterclip.db in the user config directory.
+func dbPath() (string, error) {
+	if p := os.Getenv("OTTERCLIP_DB"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "otterclip")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "otterclip.db"), nil
+}
+
+// backupDir is where the desktop app keeps daily snapshots of the history:
+// $OTTERCLIP_BACKUP_DIR ("off" disables them), or backups next to the
+// default database. Point it at a synced or external folder to survive a
+// reinstall.
+func backupDir() (string, error) {
+	if d := os.Getenv("OTTERCLIP_BACKUP_DIR"); d != "" {
+		return d, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "otterclip", "backups"), nil
 }
 
-// NewApp creates a new App application struct
-func NewApp() *App {
-	return &App{}
+// maxItems is the history cap: $OTTERCLIP_MAX_ITEMS, or the capture
+// default. Raise it before importing a long history.
+func maxItems() int {
+	if n, err := strconv.Atoi(os.Getenv("OTTERCLIP_MAX_ITEMS")); err == nil && n > 0 {
+		return n
+	}
+	return capture.DefaultMaxItems
 }
 
-// startup is called when the app starts. The context is saved
-// so we can call the runtime methods
-func (a *App) startup(ctx context.Context) {
-	a.ctx = ctx
+// newApp opens the history and builds the bound App.
+func newApp() (*desktop.App, *sqlite.Store, error) {
+	path, err := dbPath()
+	if err != nil {
+		return nil, nil, fmt.Errorf("locate db: %w", err)
+	}
+	store, err := sqlite.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open db: %w", err)
+	}
+
+	pf, err := core.NewPrivacyFilter(defaultIgnore, false)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+	captureSvc := capture.New(store, pf, capture.Config{
+		MaxItems:          maxItems(),
+		DedupeConsecutive: true,
+		TrashTTL:          30 * 24 * time.Hour,
+	})
+
+	cb := clipboard.NewSystem(350 * time.Millisecond)
+	app := desktop.New(store, captureSvc, search.New(store), cb, runtime.EventsEmit)
+	win := desktop.Window{Show: runtime.WindowShow, Hide: runtime.WindowHide, Quit: runtime.Quit}
+	// the queue mode lives in the database; the CLI's `queue fifo` sets it
+	desktop.UseQueue(app, queue.New(store, cb))
+	switch dir, err := backupDir(); {
+	case err != nil:
+		println("backups:", err.Error())
+	case dir != "off":
+		desktop.UseBackups(app, backup.New(store, backup.Config{
+			Dir:        dir,
+			Keep:       7,
+			Passphrase: []byte(os.Getenv(backup.PassphraseEnv)),
+			Database:   path,
+		}), 24*time.Hour)
+	}
+	if m := newHotkeys(); m != nil {
+		desktop.UseHotkeys(app, m, win)
+	}
+	if goruntime.GOOS == "linux" {
+		if svc, err := dbusapi.Connect(app); err != nil {
+			println("dbus:", err.Error())
+		} else {
+			desktop.Listen(app, svc.Forward)
+		}
+	}
+	switch t, err := tray.Connect(); {
+	case err == nil:
+		desktop.UseTray(app, t, win)
+	case !errors.Is(err, tray.ErrUnsupported):
+		println("tray:", err.Error())
+	}
+	// without a paster, choosing an item only copies it; say why unless
+	// the platform simply has none
+	switch p, err := paste.Native(); {
+	case err == nil:
+		desktop.UsePaster(app, p)
+	case err != paste.ErrUnsupported:
+		println("paste:", err.Error())
+	}
+	return app, store, nil
 }
 
-// Greet returns a greeting for the given name
-func (a *App) Greet(name string) string {
-	return fmt.Sprintf("Hello %s, It's show time!", name)
+// newHotkeys combines the native key grabber, when the session has one,
+// with the command socket behind `otterclip toggle`, which compositors
+// (and Wayland users) can bind to any shortcut.
+func newHotkeys() *hotkey.Manager {
+	var backends []hotkey.Backend
+	native, err := hotkey.Native()
+	switch {
+	case err == nil:
+		backends = append(backends, native)
+	case !errors.Is(err, hotkey.ErrUnsupported):
+		println("hotkeys:", err.Error())
+	}
+	sock, err := hotkey.ListenSocket(hotkey.SocketPath())
+	if err != nil {
+		println("hotkeys:", err.Error())
+	} else {
+		backends = append(backends, sock)
+	}
+	if len(backends) == 0 {
+		return nil
+	}
+	return hotkey.NewManager(backends...)
 }
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/tui"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+// Exit codes of the scriptable subcommands, grep style.
+const (
+	exitOK       = 0
+	exitNoResult = 1 // nothing matched, no such item, or the clip was ignored
+	exitError    = 2 // bad usage or a failure
+)
+
+const commandsUsage = `Subcommands (run without one for the interactive prompt):
+  add [text...]          save text (from args, or stdin when none or "-")
+  list [--limit N] [--pinned] [--trash]
+  search <query...> [--limit N]
+  get <id>               print an item's content
+  copy <id>              put an item on the system clipboard
+  pin <id...>            pin items (--off to unpin)
+  rm <id...>             move items to the trash (--hard deletes permanently)
+  tui                    full-screen browser with live search (--osc52 copies via the terminal)
+  toggle                 show/hide the running desktop app (bind it in your compositor on Wayland)
+
+Output flags (add, list, search, get, pin):
+  --json                 JSON (an array for list/search)
+  --format <template>    Go template per item, e.g. '{{.ShortID}} {{.Title}}'
+  -0, --null             end records with NUL instead of newline
+
+Ids are full item ids or unique prefixes of at least 4 characters.
+Exit status: 0 ok, 1 nothing found/ignored, 2 error.
+`
+
+// cli runs one non-interactive subcommand.
+type cli struct {
+	store   *sqlite.Store
+	capture *capture.Service
+	search  *search.Service
+	cb      clipboard.Writer
+
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func (c *cli) run(ctx context.Context, args []string) int {
+	name, args := args[0], args[1:]
+	var code int
+	var err error
+	switch name {
+	case "add":
+		code, err = c.add(ctx, args)
+	case "list":
+		code, err = c.list(ctx, args)
+	case "search":
+		code, err = c.searchCmd(ctx, args)
+	case "get":
+		code, err = c.get(ctx, args)
+	case "copy":
+		code, err = c.copy(ctx, args)
+	case "pin":
+		code, err = c.pin(ctx, args)
+	case "rm":
+		code, err = c.rm(ctx, args)
+	case "tui":
+		code, err = c.tui(ctx, args)
+	case "toggle":
+		code, err = c.toggle(ctx, args)
+	default:
+		fmt.Fprintf(c.stderr, "unknown command %q\n\n%s", name, commandsUsage)
+		return exitError
+	}
+	if err != nil {
+		fmt.Fprintf(c.stderr, "otterclip %s: %v\n", name, err)
+		if code == exitOK {
+			code = exitError
+		}
+	}
+	return code
+}
+
+// output renders items for scripts.
+type output struct {
+	json   bool
+	format string
+	null   bool
+	tmpl   *template.Template
+}
+
+func (o *output) register(fs *flag.FlagSet, defaultFormat string) {
+	fs.BoolVar(&o.json, "json", false, "print JSON")
+	fs.StringVar(&o.format, "format", defaultFormat, "Go template applied to each item")
+	fs.BoolVar(&o.null, "0", false, "end records with NUL instead of newline")
+	fs.BoolVar(&o.null, "null", false, "same as -0")
+}
+
+var templateFuncs = template.FuncMap{
+	"preview": func(n int, s string) string { return preview(s, n) },
+	"join":    strings.Join,
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func (o *output) prepare() error {
+	t, err := template.New("format").Funcs(templateFuncs).Parse(o.format)
+	if err != nil {
+		return fmt.Errorf("invalid --format: %w", err)
+	}
+	o.tmpl = t
+	return nil
+}
+
+func (o *output) items(w io.Writer, items []core.Item) error {
+	if o.json {
+		if items == nil {
+			items = []core.Item{}
+		}
+		return writeJSON(w, items)
+	}
+	for _, it := range items {
+		if err := o.record(w, it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *output) item(w io.Writer, it core.Item) error {
+	if o.json {
+		return writeJSON(w, it)
+	}
+	return o.record(w, it)
+}
+
+func (o *output) record(w io.Writer, it core.Item) error {
+	if err := o.tmpl.Execute(w, it); err != nil {
+		return err
+	}
+	end := "\n"
+	if o.null {
+		end = "\x00"
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+const listFormat = `{{.ShortID}}	{{.Type}}	{{if .Title}}{{.Title}} — {{end}}{{preview 80 .Content}}`
+
+// parse parses subcommand flags. Flags may follow positional arguments
+// ("get abc1 --json"), which the flag package alone doesn't allow.
+func parse(fs *flag.FlagSet, out *output, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		// Parse consumes a "--" terminator; everything after it is
+		// positional
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			pos = append(pos, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		pos = append(pos, rest[0])
+		args = rest[1:]
+	}
+	if out != nil {
+		if err := out.prepare(); err != nil {
+			return nil, err
+		}
+	}
+	return pos, nil
+}
+
+func (c *cli) add(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	var out output
+	out.register(fs, "{{.ShortID}}")
+	pos, err := parse(fs, &out, args)
+	if err != nil {
+		return exitError, err
+	}
+
+	text := strings.Join(pos, " ")
+	if len(pos) == 0 || text == "-" {
+		b, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return exitError, err
+		}
+		text = string(b)
+	}
+
+	it, saved, err := c.capture.ProcessText(ctx, text)
+	if err != nil {
+		return exitError, err
+	}
+	if !saved {
+		fmt.Fprintln(c.stderr, "ignored (empty, duplicate or filtered)")
+		return exitNoResult, nil
+	}
+	// a duplicate merges into the existing row; report that one
+	stored, err := c.store.FindByFingerprint(ctx, it.Fingerprint)
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, out.item(c.stdout, stored)
+}
+
+func (c *cli) list(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "max items")
+	pinned := fs.Bool("pinned", false, "only pinned items")
+	trash := fs.Bool("trash", false, "list the trash instead")
+	var out output
+	out.register(fs, listFormat)
+	if _, err := parse(fs, &out, args); err != nil {
+		return exitError, err
+	}
+
+	var items []core.Item
+	var err error
+	switch {
+	case *trash:
+		items, err = c.store.ListTrash(ctx, *limit)
+	case *pinned:
+		items, err = c.store.ListRecent(ctx, max(*limit, 200))
+		items = filterPinned(items, *limit)
+	default:
+		items, err = c.store.ListRecent(ctx, *limit)
+	}
+	if err != nil {
+		return exitError, err
+	}
+	if err := out.items(c.stdout, items); err != nil {
+		return exitError, err
+	}
+	if len(items) == 0 {
+		return exitNoResult, nil
+	}
+	return exitOK, nil
+}
+
+func filterPinned(items []core.Item, limit int) []core.Item {
+	out := items[:0]
+	for _, it := range items {
+		if it.Pinned && len(out) < limit {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func (c *cli) searchCmd(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "max results")
+	var out output
+	out.register(fs, listFormat)
+	pos, err := parse(fs, &out, args)
+	if err != nil {
+		return exitError, err
+	}
+	if len(pos) == 0 {
+		return exitError, errors.New("usage: search <query...>")
+	}
+
+	results, err := c.search.Query(ctx, strings.Join(pos, " "), search.Options{ScanLimit: max(80, *limit*4), OutLimit: *limit})
+	if err != nil {
+		return exitError, err
+	}
+	if err := out.items(c.stdout, results); err != nil {
+		return exitError, err
+	}
+	if len(results) == 0 {
+		return exitNoResult, nil
+	}
+	return exitOK, nil
+}
+
+// resolve looks up a live item for a subcommand.
+func (c *cli) resolve(ctx context.Context, ref string) (core.Item, int, error) {
+	it, err := findByID(ctx, c.store, ref)
+	if errors.Is(err, errNoItem) {
+		return it, exitNoResult, err
+	}
+	if err != nil {
+		return it, exitError, err
+	}
+	if !it.DeletedAt.IsZero() {
+		return it, exitNoResult, fmt.Errorf("item %s is in the trash", it.ShortID())
+	}
+	return it, exitOK, nil
+}
+
+func (c *cli) get(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	var out output
+	out.register(fs, "{{.Content}}")
+	pos, err := parse(fs, &out, args)
+	if err != nil {
+		return exitError, err
+	}
+	if len(pos) != 1 {
+		return exitError, errors.New("usage: get <id>")
+	}
+
+	it, code, err := c.resolve(ctx, pos[0])
+	if err != nil {
+		return code, err
+	}
+	return exitOK, out.item(c.stdout, it)
+}
+
+func (c *cli) copy(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("copy", flag.ContinueOnError)
+	pos, err := parse(fs, nil, args)
+	if err != nil {
+		return exitError, err
+	}
+	if len(pos) != 1 {
+		return exitError, errors.New("usage: copy <id>")
+	}
+
+	it, code, err := c.resolve(ctx, pos[0])
+	if err != nil {
+		return code, err
+	}
+	if err := c.cb.WriteText(it.Content); err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
+
+func (c *cli) pin(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("pin", flag.ContinueOnError)
+	off := fs.Bool("off", false, "unpin instead")
+	var out output
+	out.register(fs, "{{.ShortID}}")
+	pos, err := parse(fs, &out, args)
+	if err != nil {
+		return exitError, err
+	}
+	if len(pos) == 0 {
+		return exitError, errors.New("usage: pin [--off] <id...>")
+	}
+
+	return c.each(ctx, pos, func(it core.Item) error {
+		if err := c.store.SetPinned(ctx, it.ID, !*off); err != nil {
+			return err
+		}
+		it.Pinned = !*off
+		return out.item(c.stdout, it)
+	})
+}
+
+func (c *cli) rm(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	hard := fs.Bool("hard", false, "delete permanently instead of moving to the trash")
+	pos, err := parse(fs, nil, args)
+	if err != nil {
+		return exitError, err
+	}
+	if len(pos) == 0 {
+		return exitError, errors.New("usage: rm [--hard] <id...>")
+	}
+
+	return c.each(ctx, pos, func(it core.Item) error {
+		if *hard {
+			return c.store.Delete(ctx, it.ID)
+		}
+		if it.Pinned {
+			return fmt.Errorf("item %s is pinned (unpin first)", it.ShortID())
+		}
+		return c.store.Trash(ctx, it.ID)
+	})
+}
+
+// each applies fn to every referenced item, reporting failures as it goes.
+// The worst exit code wins.
+func (c *cli) each(ctx context.Context, refs []string, fn func(core.Item) error) (int, error) {
+	code := exitOK
+	for _, ref := range refs {
+		it, rc, err := c.resolve(ctx, ref)
+		if err == nil {
+			rc, err = exitError, fn(it)
+		}
+		if err != nil {
+			fmt.Fprintln(c.stderr, "error:", err)
+			code = max(code, rc)
+		}
+	}
+	return code, nil
+}
+
+func (c *cli) tui(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	limit := fs.Int("limit", 200, "max items listed")
+	refresh := fs.Duration("refresh", time.Second, "how often to look for new captures")
+	osc52 := fs.Bool("osc52", false, "copy through the terminal (OSC 52); the default over SSH")
+	if _, err := parse(fs, nil, args); err != nil {
+		return exitError, err
+	}
+
+	// Over SSH the system clipboard is the remote machine's; OSC 52 asks
+	// the local terminal to copy instead. It's also the fallback when no
+	// clipboard tool is available.
+	viaTerminal := tui.OSC52(os.Stdout)
+	copy := func(text string) error {
+		if err := c.cb.WriteText(text); err != nil {
+			return viaTerminal(text)
+		}
+		return nil
+	}
+	if *osc52 || os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		copy = viaTerminal
+	}
+
+	_, err := tui.Run(ctx, os.Stdin, os.Stdout, c.store, c.search, copy, tui.Config{Limit: *limit, Refresh: *refresh})
+	if errors.Is(err, context.Canceled) {
+		return exitOK, nil
+	}
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
+
+func (c *cli) toggle(ctx context.Context, args []string) (int, error) {
+	fs := flag.NewFlagSet("toggle", flag.ContinueOnError)
+	if pos, err := parse(fs, nil, args); err != nil || len(pos) > 0 {
+		return exitError, errors.New("usage: toggle")
+	}
+	err := hotkey.Send(ctx, hotkey.SocketPath(), "toggle")
+	if errors.Is(err, hotkey.ErrNotRunning) {
+		return exitNoResult, err
+	}
+	if err != nil {
+		return exitError, err
+	}
+	return exitOK, nil
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+type fakeClipboard struct {
+	text string
+}
+
+func (f *fakeClipboard) WriteText(text string) error {
+	f.text = text
+	return nil
+}
+
+func newTestCLI(t *testing.T) *cli {
+	t.Helper()
+	st, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return &cli{
+		store:   st,
+		capture: capture.New(st, nil, capture.Config{MaxItems: 100}),
+		search:  search.New(st),
+		cb:      &fakeClipboard{},
+	}
+}
+
+// exec runs one subcommand with stdin as input.
+func (c *cli) exec(t *testing.T, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	c.stdin, c.stdout, c.stderr = strings.NewReader(stdin), &out, &errOut
+	code = c.run(context.Background(), args)
+	return code, out.String(), errOut.String()
+}
+
+// addItem saves text and returns its full id.
+func (c *cli) addItem(t *testing.T, text string) string {
+	t.Helper()
+	// timestamps are kept in milliseconds; keep listing order stable
+	time.Sleep(2 * time.Millisecond)
+	code, out, stderr := c.exec(t, "", "add", "--format", "{{.ID}}", text)
+	if code != exitOK {
+		t.Fatalf("add %q: exit %d, %s", text, code, stderr)
+	}
+	return strings.TrimSpace(out)
+}
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		name   string
+		setup  []string // items to add, oldest first
+		pinned bool     // pin the newest item
+		args   []string // "$1", "$2"... are replaced by the setup items' ids
+		stdin  string
+
+		code   int
+		stdout string
+		stderr string // substring
+	}{
+		{name: "add from args", args: []string{"add", "--format", "{{.Content}}", "hello", "otter"}, stdout: "hello otter\n"},
+		{name: "add from stdin", args: []string{"add", "--format", "{{.Content}}", "-"}, stdin: "  piped\ntext\n", stdout: "piped text\n"},
+		{name: "add ignores empty", args: []string{"add"}, stdin: " \n", code: exitNoResult, stderr: "ignored"},
+		{name: "add duplicate reports the stored item", setup: []string{"dup", "other"}, args: []string{"add", "--format", "{{.ID}}", "dup"}, stdout: "$1\n"},
+		{name: "add consecutive duplicate", setup: []string{"dup"}, args: []string{"add", "dup"}, code: exitNoResult, stderr: "ignored"},
+
+		{name: "list", setup: []string{"a", "b"}, args: []string{"list", "--format", "{{.Content}}"}, stdout: "b\na\n"},
+		{name: "list -0", setup: []string{"a", "b"}, args: []string{"list", "-0", "--format", "{{.Content}}"}, stdout: "b\x00a\x00"},
+		{name: "list --null", setup: []string{"a"}, args: []string{"list", "--null", "--format", "{{.Content}}"}, stdout: "a\x00"},
+		{name: "list empty --json", args: []string{"list", "--json"}, code: exitNoResult, stdout: "[]\n"},
+		{name: "list --pinned", setup: []string{"a", "b"}, pinned: true, args: []string{"list", "--pinned", "--format", "{{.Content}}"}, stdout: "b\n"},
+		{name: "list bad flag", args: []string{"list", "--nope"}, code: exitError, stderr: "flag provided but not defined"},
+		{name: "list bad format", args: []string{"list", "--format", "{{"}, code: exitError, stderr: "invalid --format"},
+
+		{name: "search", setup: []string{"alpha", "beta"}, args: []string{"search", "alp", "--format", "{{.Content}}"}, stdout: "alpha\n"},
+		{name: "search no match --json", setup: []string{"alpha"}, args: []string{"search", "zzz", "--json"}, code: exitNoResult, stdout: "[]\n"},
+		{name: "search without query", args: []string{"search"}, code: exitError, stderr: "usage: search"},
+
+		{name: "get flags after id", setup: []string{"content"}, args: []string{"get", "$1", "--format", "{{.Content}}!"}, stdout: "content!\n"},
+		{name: "get --", setup: []string{"content"}, args: []string{"get", "--format", "{{.Type}}", "--", "$1"}, stdout: "text\n"},
+		{name: "get unknown id", args: []string{"get", "abcdef"}, code: exitNoResult, stderr: "no such item"},
+		{name: "get short id", args: []string{"get", "ab"}, code: exitError, stderr: "invalid item id"},
+		{name: "get two ids", args: []string{"get", "abcd", "efgh"}, code: exitError, stderr: "usage: get"},
+
+		{name: "pin", setup: []string{"a"}, args: []string{"pin", "$1", "--format", "{{.Pinned}}"}, stdout: "true\n"},
+		{name: "pin --off", setup: []string{"a"}, pinned: true, args: []string{"pin", "--off", "$1", "--format", "{{.Pinned}}"}, stdout: "false\n"},
+		{name: "pin mixed refs", setup: []string{"a"}, args: []string{"pin", "$1", "abcdef", "--format", "{{.Content}}"}, code: exitNoResult, stdout: "a\n", stderr: "no such item"},
+
+		{name: "rm", setup: []string{"a"}, args: []string{"rm", "$1"}},
+		{name: "rm refuses pinned", setup: []string{"a"}, pinned: true, args: []string{"rm", "$1"}, code: exitError, stderr: "is pinned (unpin first)"},
+		{name: "rm --hard deletes pinned", setup: []string{"a"}, pinned: true, args: []string{"rm", "--hard", "$1"}},
+		{name: "rm without ids", args: []string{"rm"}, code: exitError, stderr: "usage: rm"},
+
+		{name: "unknown command", args: []string{"frobnicate"}, code: exitError, stderr: `unknown command "frobnicate"`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTestCLI(t)
+			var ids []string
+			for i, text := range tc.setup {
+				ids = append(ids, fmt.Sprintf("$%d", i+1), c.addItem(t, text))
+			}
+			if tc.pinned {
+				if err := c.store.SetPinned(context.Background(), ids[len(ids)-1], true); err != nil {
+					t.Fatal(err)
+				}
+			}
+			withIDs := strings.NewReplacer(ids...)
+			args := make([]string, len(tc.args))
+			for i, a := range tc.args {
+				args[i] = withIDs.Replace(a)
+			}
+
+			code, stdout, stderr := c.exec(t, tc.stdin, args...)
+			if code != tc.code {
+				t.Fatalf("exit %d, want %d (stderr %q)", code, tc.code, stderr)
+			}
+			if want := withIDs.Replace(tc.stdout); stdout != want {
+				t.Fatalf("stdout = %q, want %q", stdout, want)
+			}
+			if !strings.Contains(stderr, tc.stderr) || tc.stderr == "" && stderr != "" {
+				t.Fatalf("stderr = %q, want %q", stderr, tc.stderr)
+			}
+		})
+	}
+}
+
+func TestRun_JSON(t *testing.T) {
+	c := newTestCLI(t)
+	id := c.addItem(t, "https://example.com")
+
+	code, out, _ := c.exec(t, "", "get", id[:core.MinIDPrefix+2], "--json")
+	if code != exitOK {
+		t.Fatalf("exit %d", code)
+	}
+	var it core.Item
+	if err := json.Unmarshal([]byte(out), &it); err != nil {
+		t.Fatal(err)
+	}
+	if it.ID != id || it.Type != core.ContentTypeURL {
+		t.Fatalf("get --json = %+v", it)
+	}
+
+	code, out, _ = c.exec(t, "", "list", "--json")
+	var items []core.Item
+	if err := json.Unmarshal([]byte(out), &items); err != nil || code != exitOK || len(items) != 1 || items[0].ID != id {
+		t.Fatalf("list --json = %s (exit %d, %v)", out, code, err)
+	}
+}
+
+func TestRun_RmMovesToTrash(t *testing.T) {
+	c := newTestCLI(t)
+	id := c.addItem(t, "gone soon")
+
+	if code, _, stderr := c.exec(t, "", "rm", id); code != exitOK {
+		t.Fatalf("rm: exit %d, %s", code, stderr)
+	}
+	if code, _, stderr := c.exec(t, "", "get", id); code != exitNoResult || !strings.Contains(stderr, "in the trash") {
+		t.Fatalf("get trashed: exit %d, %q", code, stderr)
+	}
+	code, out, _ := c.exec(t, "", "list", "--trash", "--format", "{{.ID}}")
+	if code != exitOK || out != id+"\n" {
+		t.Fatalf("list --trash: exit %d, %q", code, out)
+	}
+}
+
+func TestRun_Copy(t *testing.T) {
+	c := newTestCLI(t)
+	id := c.addItem(t, "to the clipboard")
+
+	if code, _, stderr := c.exec(t, "", "copy", id); code != exitOK {
+		t.Fatalf("copy: exit %d, %s", code, stderr)
+	}
+	if got := c.cb.(*fakeClipboard).text; got != "to the clipboard" {
+		t.Fatalf("clipboard = %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		args []string
+		pos  []string
+		json bool
+	}{
+		{[]string{"a", "b"}, []string{"a", "b"}, false},
+		{[]string{"a", "--json", "b"}, []string{"a", "b"}, true},
+		{[]string{"--json", "a"}, []string{"a"}, true},
+		{[]string{"a", "--", "--json", "-0"}, []string{"a", "--json", "-0"}, false},
+		{nil, nil, false},
+	}
+	for _, tc := range cases {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		var out output
+		out.register(fs, "{{.ID}}")
+		pos, err := parse(fs, &out, tc.args)
+		if err != nil {
+			t.Fatalf("parse(%q): %v", tc.args, err)
+		}
+		if !reflect.DeepEqual(pos, tc.pos) || out.json != tc.json {
+			t.Fatalf("parse(%q) = %q, json %v; want %q, json %v", tc.args, pos, out.json, tc.pos, tc.json)
+		}
+	}
+}
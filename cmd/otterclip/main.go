@@ -3,31 +3,69 @@ package main
 import (
 	"bufio"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
 	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
 	"github.com/its-jojoo/otterclip/internal/core"
 	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/edit"
+	"github.com/its-jojoo/otterclip/internal/usecase/queue"
 	"github.com/its-jojoo/otterclip/internal/usecase/search"
+	"github.com/its-jojoo/otterclip/internal/usecase/snippet"
+	"github.com/its-jojoo/otterclip/internal/usecase/transform"
 )
 
+const helpText = "Commands: add <text> | paste | list | pins | query <text> | count | pin <item> | unpin <item> | del <item> |\n" +
+	"          tag <item> <tags...> | untag <item> <tags...> | tags | collections [name] | collect <item> <name> | uncollect <item> <name> |\n" +
+	"          snip <item> <name> | snippets | expand <name> | unsnip <name> |\n" +
+	"          queue [fifo|lifo|off|clear] | next | transform <item> <name> [save] | transforms |\n" +
+	"          edit <item> | revisions <item> | diff <item> [rev] [rev] | revert <item> <rev> | title <item> [text] | note <item> [text] |\n" +
+	"          trash | restore <item> | undo | empty-trash |\n" +
+	"          pause | resume | help | quit\n" +
+	"<item> is a number from the last listing or an item id (at least 4 characters)"
+
 func main() {
 	var (
 		dbPath       = flag.String("db", "./otterclip.dev.db", "sqlite db path")
-		maxItems     = flag.Int("max-items", 5000, "max clipboard history items")
+		maxItems     = flag.Int("max-items", capture.DefaultMaxItems, "max clipboard history items")
 		ignoreCSV    = flag.String("ignore", "password=,token=,apikey=,secret=,authorization: bearer", "comma-separated ignore patterns (substring match)")
 		useRegex     = flag.Bool("ignore-regex", false, "treat ignore patterns as regex")
 		dedupeConsec = flag.Bool("dedupe-consecutive", true, "dedupe consecutive items")
-
-		watch    = flag.Bool("watch", false, "watch system clipboard and capture automatically (darwin only for now)")
-		interval = flag.Duration("interval", 350*time.Millisecond, "clipboard polling interval (darwin)")
+		appRulesCSV  = flag.String("app-rules", "", "comma-separated per-app rules, e.g. keepassxc=ignore,kitty=command")
+		stripParams  = flag.String("strip-params", "", "comma-separated URL query params to strip before saving (trailing * = prefix), e.g. utm_*,fbclid")
+		tagRulesStr  = flag.String("tag-rules", "", `semicolon-separated auto-tag rules, e.g. "jira=re:[A-Z]+-[0-9]+;shell=app:kitty+type:command"`)
+		concealedTTL = flag.Duration("concealed-ttl", 0, "keep clips marked concealed by password managers for this long (0 = never capture them)")
+		trashTTL     = flag.Duration("trash-ttl", 30*24*time.Hour, "permanently delete trashed items after this long (0 = keep until empty-trash)")
+		trashEvicted = flag.Bool("trash-evicted", false, "move items evicted by --max-items to the trash instead of deleting them")
+
+		watch      = flag.Bool("watch", false, "watch system clipboard and capture automatically (darwin and linux)")
+		interval   = flag.Duration("interval", 350*time.Millisecond, "clipboard polling interval")
+		sourceHint = flag.String("source-hint", "", "read the clipboard source app from this key=value file instead of the window system (linux)")
+
+		backupDir   = flag.String("backup-dir", "", "in watch mode, keep rotating snapshots of the db here (encrypted when $"+backup.PassphraseEnv+" is set)")
+		backupEvery = flag.Duration("backup-every", 24*time.Hour, "how often to snapshot the db into --backup-dir")
+		backupKeep  = flag.Int("backup-keep", 7, "snapshots to keep in --backup-dir (0 = all)")
+
+		metaRegex stringList
 	)
+	flag.Var(&metaRegex, "meta-regex", "key=regex annotation, e.g. jira=[A-Z]{2,5}-\\d+ (repeatable)")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s [flags] [command [args...]]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\n%s", commandsUsage)
+	}
 	flag.Parse()
 
 	patterns := splitCSV(*ignoreCSV)
@@ -38,6 +76,18 @@ func main() {
 		os.Exit(1)
 	}
 
+	appRules, err := core.ParseAppRules(*appRulesCSV)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid app rules: %v\n", err)
+		os.Exit(1)
+	}
+
+	tagRules, err := core.ParseTagRules(*tagRulesStr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid tag rules: %v\n", err)
+		os.Exit(1)
+	}
+
 	store, err := sqlite.Open(*dbPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
@@ -48,27 +98,80 @@ func main() {
 	captureSvc := capture.New(store, pf, capture.Config{
 		MaxItems:          *maxItems,
 		DedupeConsecutive: *dedupeConsec,
+		AppRules:          appRules,
+		TagRules:          tagRules,
+		ConcealedTTL:      *concealedTTL,
+		TrashEvicted:      *trashEvicted,
+		TrashTTL:          *trashTTL,
+		OnError: func(processor string, err error) {
+			fmt.Fprintf(os.Stderr, "processor %s: %v\n", processor, err)
+		},
 	})
+	if params := splitCSV(*stripParams); len(params) > 0 {
+		captureSvc.Use(capture.StageTransform, capture.StripQueryParams(params...))
+	}
+	for _, kv := range metaRegex {
+		key, expr, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			fmt.Fprintf(os.Stderr, "invalid --meta-regex entry %q (expected key=regex)\n", kv)
+			os.Exit(1)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --meta-regex %q: %v\n", key, err)
+			os.Exit(1)
+		}
+		captureSvc.Use(capture.StageEnrich, capture.MatchMeta(strings.TrimSpace(key), re))
+	}
 	searchSvc := search.New(store)
+	snippetSvc := snippet.New(store)
+	cb := clipboard.NewSystem(*interval)
+	queueSvc := queue.New(store, cb)
+	transformSvc := transform.New(store)
+	editSvc := edit.New(store)
+	captureSvc.Use(capture.StageSink, queueSvc.Sink())
 
 	// Cancelable context (Ctrl+C friendly)
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	if flag.NArg() > 0 {
+		c := &cli{
+			store: store, capture: captureSvc, search: searchSvc, cb: cb,
+			stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr,
+		}
+		code := c.run(ctx, flag.Args())
+		cancel()
+		store.Close()
+		os.Exit(code)
+	}
+
 	if *watch {
 		fmt.Println("OtterClip (watch mode)")
 		fmt.Println("DB:", *dbPath)
-		runWatchMode(ctx, captureSvc, *interval) // implemented via build tags
+		if *backupDir != "" && *backupEvery > 0 {
+			m := backup.New(store, backup.Config{
+				Dir:        *backupDir,
+				Keep:       *backupKeep,
+				Passphrase: []byte(os.Getenv(backup.PassphraseEnv)),
+				Database:   *dbPath,
+			})
+			go m.Run(ctx, *backupEvery, func(err error) { fmt.Fprintln(os.Stderr, "backup error:", err) })
+			fmt.Println("Backups:", *backupDir)
+		}
+		runWatchMode(ctx, captureSvc, *interval, *sourceHint) // implemented via build tags
 		return
 	}
 
 	fmt.Println("OtterClip (dev mode)")
 	fmt.Println("DB:", *dbPath)
-	fmt.Println("Commands: add <text> | paste | list | pins | query <text> | count | pin <n> | unpin <n> | del <n> | pause | resume | help | quit")
+	fmt.Println(helpText)
 	fmt.Println("Tip: 'paste' lets you type/paste a full line, then hit Enter.")
-	fmt.Println("Tip: run with --watch to capture the real clipboard (macOS only for now).")
+	fmt.Println("Tip: run with --watch to capture the real clipboard (macOS and Linux).")
+	fmt.Println("Tip: query supports app:<name>, lang:<language>, type:<type> and tag:<tag> filters.")
 
 	paused := false
+	refs := &itemRefs{store: store}
 	sc := bufio.NewScanner(os.Stdin)
 
 	for {
@@ -88,7 +191,7 @@ func main() {
 			return
 
 		case "help":
-			fmt.Println("Commands: add <text> | paste | list | pins | query <text> | count | pin <n> | unpin <n> | del <n> | pause | resume | help | quit")
+			fmt.Println(helpText)
 
 		case "pause":
 			paused = true
@@ -127,7 +230,7 @@ func main() {
 				fmt.Println("error:", err)
 				continue
 			}
-			printItems(items)
+			refs.show(items)
 
 		case "pins":
 			items, err := store.ListRecent(ctx, 200) // scan more, then filter
@@ -144,7 +247,7 @@ func main() {
 					}
 				}
 			}
-			printItems(pinned)
+			refs.show(pinned)
 
 		case "query", "q":
 			if arg == "" {
@@ -160,7 +263,7 @@ func main() {
 				fmt.Println("(no matches)")
 				continue
 			}
-			printItems(results)
+			refs.show(results)
 
 		case "count":
 			n, err := store.Count(ctx)
@@ -170,39 +273,445 @@ func main() {
 			}
 			fmt.Println(n)
 
-		case "pin":
-			n, ok := parseIndex(arg)
-			if !ok {
-				fmt.Println("usage: pin <n>")
+		case "pin", "unpin":
+			if arg == "" {
+				fmt.Printf("usage: %s <item>\n", cmd)
+				continue
+			}
+			it, err := refs.resolve(ctx, arg)
+			if err != nil {
+				fmt.Println("error:", err)
 				continue
 			}
-			if err := setPinnedByIndex(ctx, store, n, true); err != nil {
+			if err := store.SetPinned(ctx, it.ID, cmd == "pin"); err != nil {
 				fmt.Println("error:", err)
 			}
 
-		case "unpin":
-			n, ok := parseIndex(arg)
-			if !ok {
-				fmt.Println("usage: unpin <n>")
+		case "del":
+			if arg == "" {
+				fmt.Println("usage: del <item>")
 				continue
 			}
-			if err := setPinnedByIndex(ctx, store, n, false); err != nil {
+			it, err := refs.resolve(ctx, arg)
+			if err != nil {
 				fmt.Println("error:", err)
+				continue
+			}
+			if it.Pinned {
+				fmt.Println("error: refusing to delete pinned item (unpin first)")
+				continue
 			}
+			if err := store.Trash(ctx, it.ID); err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Printf("(%s moved to trash; 'undo' restores it)\n", it.ShortID())
 
-		case "del":
-			n, ok := parseIndex(arg)
+		case "trash":
+			items, err := store.ListTrash(ctx, 50)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			refs.show(items)
+
+		case "restore", "undo":
+			var it core.Item
+			var err error
+			if cmd == "restore" {
+				if arg == "" {
+					fmt.Println("usage: restore <item>  (see 'trash')")
+					continue
+				}
+				it, err = refs.resolveTrashed(ctx, arg)
+			} else {
+				// undo restores the most recently trashed item
+				var items []core.Item
+				items, err = store.ListTrash(ctx, 1)
+				if err == nil && len(items) == 0 {
+					err = errors.New("trash is empty")
+				}
+				if err == nil {
+					it = items[0]
+				}
+			}
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if err := store.Restore(ctx, it.ID); err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Println("restored:", it.ShortID(), preview(it.Content, 70))
+
+		case "empty-trash":
+			n, err := store.PurgeTrash(ctx, time.Time{})
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Printf("(deleted %d items)\n", n)
+
+		case "tag", "untag":
+			ref, rest, _ := strings.Cut(arg, " ")
+			tags := strings.Fields(rest)
+			if len(tags) == 0 {
+				fmt.Printf("usage: %s <item> <tags...>\n", cmd)
+				continue
+			}
+			it, err := refs.resolve(ctx, ref)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if cmd == "tag" {
+				err = store.AddTags(ctx, it.ID, tags...)
+			} else {
+				err = store.RemoveTags(ctx, it.ID, tags...)
+			}
+			if err != nil {
+				fmt.Println("error:", err)
+			}
+
+		case "tags":
+			tags, err := store.ListTags(ctx)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if len(tags) == 0 {
+				fmt.Println("(no tags)")
+				continue
+			}
+			for _, t := range tags {
+				fmt.Printf("#%s (%d)\n", t.Name, t.Count)
+			}
+
+		case "collections":
+			if arg != "" {
+				items, err := store.CollectionItems(ctx, arg)
+				if err != nil {
+					fmt.Println("error:", err)
+					continue
+				}
+				refs.show(items)
+				continue
+			}
+			cols, err := store.ListCollections(ctx)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if len(cols) == 0 {
+				fmt.Println("(no collections)")
+				continue
+			}
+			for _, c := range cols {
+				fmt.Printf("%s (%d)\n", c.Name, c.Count)
+			}
+
+		case "collect", "uncollect":
+			ref, name, _ := strings.Cut(arg, " ")
+			name = strings.TrimSpace(name)
+			if name == "" {
+				fmt.Printf("usage: %s <item> <name>\n", cmd)
+				continue
+			}
+			it, err := refs.resolve(ctx, ref)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if cmd == "collect" {
+				err = store.AddToCollection(ctx, name, it.ID)
+			} else {
+				err = store.RemoveFromCollection(ctx, name, it.ID)
+			}
+			if err != nil {
+				fmt.Println("error:", err)
+			}
+
+		case "snip":
+			ref, name, _ := strings.Cut(arg, " ")
+			name = strings.TrimSpace(name)
+			if name == "" {
+				fmt.Println("usage: snip <item> <name>")
+				continue
+			}
+			it, err := refs.resolve(ctx, ref)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if _, err := snippetSvc.FromItem(ctx, name, it); err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Println("snippet saved:", name)
+
+		case "snippets":
+			list, err := store.ListSnippets(ctx)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if len(list) == 0 {
+				fmt.Println("(no snippets)")
+				continue
+			}
+			for _, sn := range list {
+				fmt.Printf("%-16s %s\n", sn.Name, preview(sn.Body, 70))
+			}
+
+		case "expand":
+			if arg == "" {
+				fmt.Println("usage: expand <name>")
+				continue
+			}
+			env := core.TemplateEnv{
+				Clipboard: cb.ReadText,
+				Input: func(name string) (string, error) {
+					fmt.Printf("(%s) ", name)
+					if !sc.Scan() {
+						return "", fmt.Errorf("no input")
+					}
+					return sc.Text(), nil
+				},
+			}
+			out, err := snippetSvc.ExpandAndCopy(ctx, arg, env, cb)
+			if out != "" {
+				fmt.Println(out)
+			}
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Println("(copied)")
+
+		case "unsnip":
+			if arg == "" {
+				fmt.Println("usage: unsnip <name>")
+				continue
+			}
+			if err := store.DeleteSnippet(ctx, arg); err != nil {
+				fmt.Println("error:", err)
+			}
+
+		case "queue":
+			if arg == "clear" {
+				if err := queueSvc.Clear(ctx); err != nil {
+					fmt.Println("error:", err)
+				}
+				continue
+			}
+			if arg != "" {
+				m, ok := queue.ParseMode(arg)
+				if !ok {
+					fmt.Println("usage: queue [fifo|lifo|off|clear]")
+					continue
+				}
+				if err := queueSvc.SetMode(ctx, m); err != nil {
+					fmt.Println("error:", err)
+					continue
+				}
+			}
+			m, err := queueSvc.Mode(ctx)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			items, err := queueSvc.List(ctx)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Printf("queue: %s (%d queued)\n", m, len(items))
+			refs.show(items)
+
+		case "next":
+			it, err := queueSvc.Next(ctx)
+			if queue.IsEmpty(err) {
+				fmt.Println("(queue empty)")
+				continue
+			}
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Println(preview(it.Content, 70))
+			fmt.Println("(copied)")
+
+		case "transform", "tf":
+			fields := strings.Fields(arg)
+			save := len(fields) == 3 && fields[2] == "save"
+			if len(fields) != 2 && !save {
+				fmt.Println("usage: transform <item> <name> [save]  (see 'transforms')")
+				continue
+			}
+			it, err := refs.resolve(ctx, fields[0])
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if save {
+				saved, err := transformSvc.Save(ctx, it, fields[1])
+				if errors.Is(err, transform.ErrUnchanged) {
+					fmt.Println("(unchanged)")
+					continue
+				}
+				if err != nil {
+					fmt.Println("error:", err)
+					continue
+				}
+				fmt.Println(saved.Content)
+				fmt.Println("(saved)")
+				continue
+			}
+			out, err := transformSvc.Copy(it, fields[1], cb)
+			if out != "" {
+				fmt.Println(out)
+			}
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Println("(copied)")
+
+		case "transforms":
+			for _, t := range transform.All() {
+				fmt.Printf("%-14s %s\n", t.Name, t.Description)
+			}
+
+		case "edit":
+			if arg == "" {
+				fmt.Println("usage: edit <item>")
+				continue
+			}
+			it, err := refs.resolve(ctx, arg)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			text, err := editText(it.Content)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			if _, err := editSvc.Edit(ctx, it, text); errors.Is(err, edit.ErrUnchanged) {
+				fmt.Println("(unchanged)")
+			} else if err != nil {
+				fmt.Println("error:", err)
+			} else {
+				fmt.Println("(saved)")
+			}
+
+		case "title", "note":
+			ref, text, _ := strings.Cut(arg, " ")
+			if ref == "" {
+				fmt.Printf("usage: %s <item> [text]  (no text clears it)\n", cmd)
+				continue
+			}
+			it, err := refs.resolve(ctx, ref)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			set := store.SetTitle
+			if cmd == "note" {
+				set = store.SetNote
+			}
+			if err := set(ctx, it.ID, strings.TrimSpace(text)); err != nil {
+				fmt.Println("error:", err)
+			}
+
+		case "revisions":
+			if arg == "" {
+				fmt.Println("usage: revisions <item>")
+				continue
+			}
+			it, err := refs.resolve(ctx, arg)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			revs, err := editSvc.Revisions(ctx, it.ID)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			for _, r := range revs {
+				fmt.Printf("r%-3d %s  %s\n", r.Rev, r.ReplacedAt.Format("2006-01-02 15:04"), preview(r.Content, 60))
+			}
+			fmt.Printf("now  %s\n", preview(it.Content, 60))
+
+		case "diff":
+			fields := strings.Fields(arg)
+			ok := len(fields) >= 1 && len(fields) <= 3
+			revArgs := make([]int, 0, 2)
+			for _, f := range fields[min(1, len(fields)):] {
+				r, rok := parseRev(f)
+				ok = ok && rok
+				revArgs = append(revArgs, r)
+			}
 			if !ok {
-				fmt.Println("usage: del <n>")
+				fmt.Println("usage: diff <item> [rev] [rev]  (default: latest revision vs current; rev 0 = current)")
+				continue
+			}
+			it, err := refs.resolve(ctx, fields[0])
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			from, to := -1, 0
+			switch len(revArgs) {
+			case 1:
+				from = revArgs[0]
+			case 2:
+				from, to = revArgs[0], revArgs[1]
+			}
+			if from < 0 {
+				revs, err := editSvc.Revisions(ctx, it.ID)
+				if err != nil {
+					fmt.Println("error:", err)
+					continue
+				}
+				if len(revs) == 0 {
+					fmt.Println("(no revisions)")
+					continue
+				}
+				from = revs[len(revs)-1].Rev
+			}
+			d, err := editSvc.Diff(ctx, it, from, to)
+			if err != nil {
+				fmt.Println("error:", err)
+				continue
+			}
+			fmt.Print(core.FormatDiff(d))
+
+		case "revert":
+			ref, rev, _ := strings.Cut(arg, " ")
+			r, ok := parseRev(strings.TrimSpace(rev))
+			if !ok || r == 0 {
+				fmt.Println("usage: revert <item> <rev>")
+				continue
+			}
+			it, err := refs.resolve(ctx, ref)
+			if err != nil {
+				fmt.Println("error:", err)
 				continue
 			}
-			if err := deleteByIndex(ctx, store, n); err != nil {
+			if _, err := editSvc.Revert(ctx, it, r); errors.Is(err, edit.ErrUnchanged) {
+				fmt.Println("(unchanged)")
+			} else if err != nil {
 				fmt.Println("error:", err)
+			} else {
+				fmt.Printf("(reverted to r%d)\n", r)
 			}
 
 		default:
 			fmt.Println("unknown command:", cmd)
-			fmt.Println("Commands: add <text> | paste | list | pins | query <text> | count | pin <n> | unpin <n> | del <n> | pause | resume | help | quit")
+			fmt.Println(helpText)
 		}
 	}
 
@@ -221,7 +730,26 @@ func printItems(items []core.Item) {
 		if it.Pinned {
 			pin = "★"
 		}
-		fmt.Printf("%2d %s [%s] %s\n", i+1, pin, it.Type, preview(it.Content, 80))
+		src := ""
+		if !it.Source.IsZero() {
+			src = " (" + it.Source.String() + ")"
+		}
+		typ := string(it.Type)
+		if lang := it.Meta[core.MetaLanguage]; lang != "" {
+			typ += ":" + lang
+		}
+		tags := ""
+		for _, t := range it.Tags {
+			tags += " #" + t
+		}
+		text := preview(it.Content, 80)
+		if it.Title != "" {
+			text = it.Title + " — " + preview(it.Content, 50)
+		}
+		fmt.Printf("%2d %-7s %s [%s]%s %s%s\n", i+1, it.ShortID(), pin, typ, src, text, tags)
+		if it.Note != "" {
+			fmt.Printf("               ✎ %s\n", preview(it.Note, 76))
+		}
 	}
 }
 
@@ -238,57 +766,6 @@ func saveOne(ctx context.Context, svc *capture.Service, raw string) {
 	fmt.Println("saved")
 }
 
-func parseIndex(s string) (int, bool) {
-	s = strings.TrimSpace(s)
-	if s == "" {
-		return 0, false
-	}
-	n := 0
-	for _, r := range s {
-		if r < '0' || r > '9' {
-			return 0, false
-		}
-		n = n*10 + int(r-'0')
-	}
-	if n <= 0 {
-		return 0, false
-	}
-	return n, true
-}
-
-type pinStore interface {
-	ListRecent(ctx context.Context, limit int) ([]core.Item, error)
-	SetPinned(ctx context.Context, id string, pinned bool) error
-	Delete(ctx context.Context, id string) error
-}
-
-func setPinnedByIndex(ctx context.Context, st pinStore, n int, pinned bool) error {
-	items, err := st.ListRecent(ctx, 50)
-	if err != nil {
-		return err
-	}
-	if n > len(items) {
-		return fmt.Errorf("index out of range (have %d)", len(items))
-	}
-	it := items[n-1]
-	return st.SetPinned(ctx, it.ID, pinned)
-}
-
-func deleteByIndex(ctx context.Context, st pinStore, n int) error {
-	items, err := st.ListRecent(ctx, 50)
-	if err != nil {
-		return err
-	}
-	if n > len(items) {
-		return fmt.Errorf("index out of range (have %d)", len(items))
-	}
-	it := items[n-1]
-	if it.Pinned {
-		return fmt.Errorf("refusing to delete pinned item (unpin first)")
-	}
-	return st.Delete(ctx, it.ID)
-}
-
 func splitCmd(s string) (cmd, arg string) {
 	parts := strings.Fields(s)
 	cmd = strings.ToLower(parts[0])
@@ -298,6 +775,17 @@ func splitCmd(s string) (cmd, arg string) {
 	return cmd, arg
 }
 
+// stringList is a repeatable string flag. Unlike the comma-separated
+// flags, values may contain commas (regexes such as [A-Z]{2,5}).
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, " ") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
 func splitCSV(s string) []string {
 	raw := strings.Split(s, ",")
 	out := make([]string, 0, len(raw))
@@ -310,6 +798,12 @@ func splitCSV(s string) []string {
 	return out
 }
 
+// parseRev parses a revision number, with or without the "r" prefix.
+func parseRev(s string) (int, bool) {
+	r, err := strconv.Atoi(strings.TrimPrefix(s, "r"))
+	return r, err == nil && r >= 0
+}
+
 func preview(s string, max int) string {
 	s = strings.ReplaceAll(s, "\n", " ")
 	s = strings.TrimSpace(s)
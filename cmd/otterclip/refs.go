@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+type refStore interface {
+	FindByIDPrefix(ctx context.Context, prefix string, limit int) ([]core.Item, error)
+}
+
+// itemRefs resolves item references typed in REPL commands. A small number
+// is a position in the last printed listing, so captures made since then
+// don't shift it; anything else is an ID prefix (see core.Item.ShortID).
+// Either way the item is re-read so commands see its current state.
+type itemRefs struct {
+	store  refStore
+	last   []core.Item
+	listed bool
+}
+
+// show prints items and makes them the target of index references.
+func (r *itemRefs) show(items []core.Item) {
+	r.last, r.listed = items, true
+	printItems(items)
+}
+
+// resolve returns a live (not trashed) item.
+func (r *itemRefs) resolve(ctx context.Context, ref string) (core.Item, error) {
+	it, err := r.find(ctx, ref)
+	if err == nil && !it.DeletedAt.IsZero() {
+		return core.Item{}, fmt.Errorf("item %s is in the trash (restore it first)", it.ShortID())
+	}
+	return it, err
+}
+
+// resolveTrashed returns an item that is in the trash.
+func (r *itemRefs) resolveTrashed(ctx context.Context, ref string) (core.Item, error) {
+	it, err := r.find(ctx, ref)
+	if err == nil && it.DeletedAt.IsZero() {
+		return core.Item{}, fmt.Errorf("item %s is not in the trash", it.ShortID())
+	}
+	return it, err
+}
+
+func (r *itemRefs) find(ctx context.Context, ref string) (core.Item, error) {
+	ref = strings.TrimSpace(ref)
+	n, ok := parseIndex(ref)
+	if !ok || len(ref) >= core.MinIDPrefix {
+		return findByID(ctx, r.store, ref)
+	}
+	if !r.listed {
+		return core.Item{}, fmt.Errorf("no listing to index into yet; run 'list' or use an item id")
+	}
+	if n > len(r.last) {
+		return core.Item{}, fmt.Errorf("index out of range (last listing had %d)", len(r.last))
+	}
+	return findByID(ctx, r.store, r.last[n-1].ID)
+}
+
+// errNoItem is returned when an ID prefix matches nothing.
+var errNoItem = errors.New("no such item")
+
+// findByID resolves a full ID or a unique prefix of at least
+// core.MinIDPrefix characters, trashed items included.
+func findByID(ctx context.Context, st refStore, ref string) (core.Item, error) {
+	if len(ref) < core.MinIDPrefix {
+		return core.Item{}, fmt.Errorf("invalid item id %q (need at least %d characters)", ref, core.MinIDPrefix)
+	}
+	items, err := st.FindByIDPrefix(ctx, ref, 2)
+	if err != nil {
+		return core.Item{}, err
+	}
+	switch len(items) {
+	case 0:
+		return core.Item{}, fmt.Errorf("%w: %q (it may have been deleted)", errNoItem, ref)
+	case 1:
+		return items[0], nil
+	default:
+		return core.Item{}, fmt.Errorf("ambiguous id %q; type more characters", ref)
+	}
+}
+
+func parseIndex(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+		n = n*10 + int(r-'0')
+	}
+	if n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
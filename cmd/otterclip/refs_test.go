@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestItemRefs(t *testing.T) {
+	st, err := sqlite.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	put := func(id, content string) core.Item {
+		t.Helper()
+		it := core.Item{ID: id, Content: content, Type: core.ContentTypeText, Fingerprint: core.Fingerprint(content), CreatedAt: now, LastSeenAt: now}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+		return it
+	}
+	first := put("aaaa1111", "first")
+	second := put("aaaa2222", "second")
+
+	refs := &itemRefs{store: st}
+	if _, err := refs.resolve(ctx, "1"); err == nil || !strings.Contains(err.Error(), "no listing") {
+		t.Fatalf("index before any listing: %v", err)
+	}
+
+	// a listing binds indexes; later captures don't shift them
+	refs.last, refs.listed = []core.Item{second, first}, true
+	put("bbbb3333", "third")
+	if it, err := refs.resolve(ctx, "2"); err != nil || it.ID != first.ID {
+		t.Fatalf("index 2 = %+v, %v", it, err)
+	}
+	if _, err := refs.resolve(ctx, "3"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("index 3: %v", err)
+	}
+
+	// indexes re-read the item
+	if err := st.SetTitle(ctx, second.ID, "renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if it, err := refs.resolve(ctx, "1"); err != nil || it.Title != "renamed" {
+		t.Fatalf("index 1 = %+v, %v", it, err)
+	}
+
+	// id prefixes
+	if it, err := refs.resolve(ctx, "bbbb"); err != nil || it.Content != "third" {
+		t.Fatalf("prefix bbbb = %+v, %v", it, err)
+	}
+	if _, err := refs.resolve(ctx, "aaaa"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Fatalf("prefix aaaa: %v", err)
+	}
+	if _, err := refs.resolve(ctx, "cccc"); !errors.Is(err, errNoItem) {
+		t.Fatalf("prefix cccc: %v", err)
+	}
+
+	// trash
+	if err := st.Trash(ctx, first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := refs.resolve(ctx, "2"); err == nil || !strings.Contains(err.Error(), "in the trash") {
+		t.Fatalf("trashed item resolved: %v", err)
+	}
+	if it, err := refs.resolveTrashed(ctx, "aaaa1111"); err != nil || it.ID != first.ID {
+		t.Fatalf("resolveTrashed = %+v, %v", it, err)
+	}
+	if _, err := refs.resolveTrashed(ctx, "bbbb3333"); err == nil {
+		t.Fatalf("resolveTrashed accepted a live item")
+	}
+}
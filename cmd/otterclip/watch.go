@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+)
+
+// watchLoop captures every clipboard change reported by w until ctx is done.
+func watchLoop(ctx context.Context, svc *capture.Service, w clipboard.Watcher) {
+	events, err := w.Watch(ctx)
+	if err != nil {
+		fmt.Println("watch error:", err)
+		return
+	}
+
+	sr, _ := w.(clipboard.SourceReader)
+	tr, _ := w.(clipboard.TargetReader)
+
+	// expired (concealed) items must go away even if nothing new is copied
+	purge := time.NewTicker(10 * time.Second)
+	defer purge.Stop()
+
+	fmt.Println("watching clipboard... (Ctrl+C to exit)")
+	for {
+		select {
+		case <-purge.C:
+			if err := svc.PurgeExpired(ctx); err != nil {
+				fmt.Println("purge error:", err)
+			}
+			continue
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		}
+
+		txt, err := w.ReadText()
+		if err != nil {
+			continue
+		}
+		clip := capture.Clip{Text: txt}
+		if sr != nil {
+			// best effort: a missing source just means no per-app rules apply
+			clip.Source, _ = sr.ReadSource()
+		}
+		if tr != nil {
+			clip.Targets, _ = tr.ReadTargets()
+		}
+		_, saved, err := svc.ProcessClip(ctx, clip)
+		if err != nil {
+			fmt.Println("capture error:", err)
+			continue
+		}
+		if saved {
+			if clip.Source.IsZero() {
+				fmt.Println("captured:", preview(txt, 60))
+			} else {
+				fmt.Printf("captured (%s): %s\n", clip.Source, preview(txt, 60))
+			}
+		}
+	}
+}
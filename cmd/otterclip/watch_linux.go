@@ -0,0 +1,17 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+)
+
+func runWatchMode(ctx context.Context, svc *capture.Service, interval time.Duration, sourceHint string) {
+	w := clipboard.NewLinuxWatcher(interval)
+	w.HintPath = sourceHint
+	watchLoop(ctx, svc, w)
+}
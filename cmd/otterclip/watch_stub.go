@@ -1,4 +1,4 @@
-//go:build !darwin
+//go:build !darwin && !linux
 
 package main
 
@@ -10,9 +10,10 @@ import (
 	"github.com/its-jojoo/otterclip/internal/usecase/capture"
 )
 
-func runWatchMode(ctx context.Context, svc *capture.Service, interval time.Duration) {
+func runWatchMode(ctx context.Context, svc *capture.Service, interval time.Duration, sourceHint string) {
 	_ = ctx
 	_ = svc
 	_ = interval
-	fmt.Println("watch mode is not supported on this OS yet (darwin only for now).")
+	_ = sourceHint
+	fmt.Println("watch mode is not supported on this OS yet (darwin and linux only for now).")
 }
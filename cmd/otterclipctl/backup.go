@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+)
+
+// defaultDB matches the desktop app's database, which the snapshots in
+// defaultBackupDir are taken from.
+func defaultDB() string {
+	if p := os.Getenv("OTTERCLIP_DB"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "otterclip.db"
+	}
+	return filepath.Join(dir, "otterclip", "otterclip.db")
+}
+
+// defaultBackupDir matches the desktop app's backup folder.
+func defaultBackupDir() string {
+	if d := os.Getenv("OTTERCLIP_BACKUP_DIR"); d != "" && d != "off" {
+		return d
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "backups"
+	}
+	return filepath.Join(dir, "otterclip", "backups")
+}
+
+// passphrase reads the backup passphrase from file (first line), or from
+// the environment when file is empty. It is never taken as a flag value,
+// which would leak it into the process list and shell history.
+func passphrase(file string) []byte {
+	if file == "" {
+		return []byte(os.Getenv(backup.PassphraseEnv))
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "passphrase file error: %v\n", err)
+		os.Exit(1)
+	}
+	line, _, _ := bytes.Cut(b, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+func backupCmd(args []string) {
+	list := len(args) > 0 && args[0] == "list"
+	if list {
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	var (
+		dbPath   = fs.String("db", defaultDB(), "sqlite db path")
+		dir      = fs.String("dir", defaultBackupDir(), "backup directory")
+		keep     = fs.Int("keep", 0, "delete all but the newest N snapshots afterwards (0 = keep all)")
+		passFile = fs.String("passphrase-file", "", "encrypt with the passphrase in this file (default $"+backup.PassphraseEnv+"; unset = no encryption)")
+	)
+	_ = fs.Parse(args)
+
+	if list {
+		snaps, err := backup.New(nil, backup.Config{Dir: *dir}).List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "list error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, s := range snaps {
+			enc := ""
+			if s.Encrypted {
+				enc = "\tencrypted"
+			}
+			fmt.Printf("%s\t%s\t%d KiB%s\n", s.Time.Local().Format("2006-01-02 15:04:05"), s.Path, (s.Size+1023)/1024, enc)
+		}
+		return
+	}
+
+	st, err := sqlite.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	m := backup.New(st, backup.Config{Dir: *dir, Keep: *keep, Passphrase: passphrase(*passFile), Database: *dbPath})
+	snap, err := m.Create(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "backup error: %v\n", err)
+		os.Exit(1)
+	}
+	what := "backed up"
+	if snap.Encrypted {
+		what = "backed up (encrypted)"
+	}
+	fmt.Println(what, *dbPath, "to", snap.Path)
+
+	removed, err := m.Rotate()
+	for _, s := range removed {
+		fmt.Println("removed", s.Path)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "rotate error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func restoreCmd(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	var (
+		dbPath   = fs.String("db", defaultDB(), "sqlite db path to replace")
+		dir      = fs.String("dir", defaultBackupDir(), "backup directory, for --at")
+		at       = fs.String("at", "", "restore the newest snapshot taken at or before this time (RFC 3339, or 2006-01-02 15:04 local)")
+		check    = fs.Bool("check", false, "only verify the snapshot; don't restore it")
+		passFile = fs.String("passphrase-file", "", "passphrase for encrypted snapshots (default $"+backup.PassphraseEnv+")")
+	)
+	_ = fs.Parse(args)
+
+	var path string
+	switch {
+	case fs.NArg() == 1 && *at == "":
+		path = fs.Arg(0)
+	case fs.NArg() == 0 && *at != "":
+		t, err := parseAt(*at)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --at: %v\n", err)
+			os.Exit(2)
+		}
+		s, err := backup.New(nil, backup.Config{Dir: *dir, Database: *dbPath}).At(t)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "restore error: %v\n", err)
+			os.Exit(1)
+		}
+		path = s.Path
+	default:
+		fmt.Fprintln(os.Stderr, "restore takes a snapshot file or --at <time>")
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	pass := passphrase(*passFile)
+	if *check {
+		n, err := backup.Check(ctx, path, pass)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: ok, %d items\n", path, n)
+		return
+	}
+
+	r, err := backup.Restore(ctx, path, *dbPath, pass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("restored %d items from %s into %s\n", r.Items, path, *dbPath)
+	if r.Previous != "" {
+		fmt.Println("the replaced database was kept as", r.Previous)
+	}
+}
+
+func parseAt(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
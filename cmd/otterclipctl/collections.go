@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/its-jojoo/otterclip/internal/adapter/exportfmt"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+type ExportCollection struct {
+	Name  string           `json:"name"`
+	Items []exportfmt.Item `json:"items"`
+}
+
+func collectionsCmd(args []string) {
+	if len(args) < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("collections "+args[0], flag.ExitOnError)
+	var (
+		dbPath = fs.String("db", "./otterclip.dev.db", "sqlite db path")
+		name   = fs.String("name", "", "collection name")
+		out    = fs.String("out", "", "output json file path (export)")
+		in     = fs.String("in", "", "input json file path (import)")
+	)
+	_ = fs.Parse(args[1:])
+
+	st, err := sqlite.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "list":
+		cols, err := st.ListCollections(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "list error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, c := range cols {
+			fmt.Printf("%s\t%d\n", c.Name, c.Count)
+		}
+
+	case "export":
+		if *name == "" {
+			fmt.Fprintln(os.Stderr, "--name is required")
+			os.Exit(2)
+		}
+		if *out == "" {
+			*out = "otterclip-collection-" + *name + ".json"
+		}
+		if err := exportCollection(ctx, st, *name, *out); err != nil {
+			fmt.Fprintf(os.Stderr, "export error: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "import":
+		if *in == "" {
+			fmt.Fprintln(os.Stderr, "--in is required")
+			os.Exit(2)
+		}
+		if err := importCollection(ctx, st, *in, *name); err != nil {
+			fmt.Fprintf(os.Stderr, "import error: %v\n", err)
+			os.Exit(1)
+		}
+
+	default:
+		usage()
+		os.Exit(2)
+	}
+}
+
+func exportCollection(ctx context.Context, st *sqlite.Store, name, out string) error {
+	items, err := st.CollectionItems(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	col := ExportCollection{Name: name, Items: make([]exportfmt.Item, 0, len(items))}
+	for _, it := range items {
+		col.Items = append(col.Items, exportfmt.FromItem(it))
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(col); err != nil {
+		return err
+	}
+
+	fmt.Println("exported", len(col.Items), "items from", name, "to", out)
+	return nil
+}
+
+// importCollection adds the file's items to history (deduped by fingerprint)
+// and to the collection, named after the file unless name is given.
+func importCollection(ctx context.Context, st *sqlite.Store, in, name string) error {
+	b, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	var col ExportCollection
+	if err := json.Unmarshal(b, &col); err != nil {
+		return err
+	}
+	if name == "" {
+		name = col.Name
+	}
+	if name == "" {
+		return fmt.Errorf("collection has no name (use --name)")
+	}
+
+	ids := make([]string, 0, len(col.Items))
+	for _, ei := range col.Items {
+		content := core.Normalize(ei.Content)
+		if content == "" {
+			continue
+		}
+		fp := core.Fingerprint(content)
+
+		typ, ok := core.ParseContentType(ei.Type)
+		if !ok {
+			typ = core.DetectType(content)
+		}
+		now := st.Now()
+		it := core.Item{
+			ID:          uuid.NewString(),
+			Content:     content,
+			Type:        typ,
+			Fingerprint: fp,
+			CreatedAt:   parseTimeOr(ei.CreatedAt, now),
+			LastSeenAt:  parseTimeOr(ei.LastSeenAt, now),
+			Pinned:      ei.Pinned,
+			Title:       ei.Title,
+			Note:        ei.Note,
+			Source:      ei.Source,
+			Tags:        ei.Tags,
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			return err
+		}
+
+		stored, err := st.FindByFingerprint(ctx, fp)
+		if err != nil {
+			return err
+		}
+		if ei.Pinned && !stored.Pinned {
+			if err := st.SetPinned(ctx, stored.ID, true); err != nil {
+				return err
+			}
+		}
+		ids = append(ids, stored.ID)
+	}
+
+	if err := st.AddToCollection(ctx, name, ids...); err != nil {
+		return err
+	}
+	fmt.Println("imported", len(ids), "items into", name)
+	return nil
+}
+
+func parseTimeOr(s string, def time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return def
+	}
+	return t
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func doctorCmd(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	var (
+		dbPath   = fs.String("db", "./otterclip.dev.db", "sqlite db path")
+		dryRun   = fs.Bool("dry-run", false, "report what would change without changing anything")
+		noVacuum = fs.Bool("no-vacuum", false, "skip compacting the database file")
+	)
+	_ = fs.Parse(args)
+
+	st, err := sqlite.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	fail := func(step string, err error) {
+		fmt.Fprintf(os.Stderr, "%s error: %v\n", step, err)
+		st.Close()
+		os.Exit(1)
+	}
+	would := func(did, would string) string {
+		if *dryRun {
+			return would
+		}
+		return did
+	}
+
+	problems, err := st.IntegrityCheck(ctx)
+	if err != nil {
+		fail("integrity check", err)
+	}
+	if len(problems) > 0 {
+		// rewriting a damaged file can make things worse
+		fmt.Println("integrity: FAILED")
+		for _, p := range problems {
+			fmt.Println("  " + p)
+		}
+		fmt.Println("nothing was changed; restore a backup with 'otterclipctl restore'")
+		st.Close()
+		os.Exit(1)
+	}
+	fmt.Println("integrity: ok")
+
+	o, err := st.RemoveOrphans(ctx, *dryRun)
+	if err != nil {
+		fail("orphans", err)
+	}
+	if o.Total() == 0 {
+		fmt.Println("orphans: none")
+	} else {
+		var parts []string
+		for _, c := range []struct {
+			n    int
+			what string
+		}{
+			{o.ItemTags, "tag links"},
+			{o.CollectionItems, "collection entries"},
+			{o.Revisions, "revisions"},
+			{o.QueueEntries, "paste queue entries"},
+			{o.Tags, "unused tags"},
+		} {
+			if c.n > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", c.n, c.what))
+			}
+		}
+		fmt.Printf("orphans: %s %s\n", would("removed", "would remove"), strings.Join(parts, ", "))
+	}
+
+	big, err := st.Oversized(ctx, core.MaxContentLen)
+	if err != nil {
+		fail("oversized", err)
+	}
+	fmt.Printf("oversized: %d items over %d bytes\n", len(big), core.MaxContentLen)
+	for _, is := range big {
+		fmt.Printf("  %s\t%d KiB\n", shortID(is.ID), (is.Bytes+1023)/1024)
+	}
+
+	r, err := st.Refingerprint(ctx, *dryRun)
+	if err != nil {
+		fail("refingerprint", err)
+	}
+	fmt.Printf("fingerprints: %d %s\n", r.Fingerprints, would("updated", "would be updated"))
+	fmt.Printf("types: %d %s\n", r.Types, would("updated", "would be updated"))
+	for _, m := range r.Merges {
+		from := make([]string, len(m.From))
+		for i, id := range m.From {
+			from[i] = shortID(id)
+		}
+		fmt.Printf("  %s %s into %s\n", would("merged", "would merge"), strings.Join(from, ", "), shortID(m.Into))
+	}
+
+	if *dryRun || *noVacuum {
+		return
+	}
+	before, err := st.Size(ctx)
+	if err != nil {
+		fail("vacuum", err)
+	}
+	if err := st.Vacuum(ctx); err != nil {
+		fail("vacuum", err)
+	}
+	after, err := st.Size(ctx)
+	if err != nil {
+		fail("vacuum", err)
+	}
+	fmt.Printf("vacuum: %d KiB -> %d KiB\n", (before+1023)/1024, (after+1023)/1024)
+}
+
+func shortID(id string) string {
+	return core.Item{ID: id}.ShortID()
+}
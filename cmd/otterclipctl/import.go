@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/importfmt"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/importer"
+)
+
+// defaultMaxItems matches the desktop app's history cap.
+func defaultMaxItems() int {
+	if n, err := strconv.Atoi(os.Getenv("OTTERCLIP_MAX_ITEMS")); err == nil && n > 0 {
+		return n
+	}
+	return capture.DefaultMaxItems
+}
+
+func importCmd(args []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case "formats":
+			for _, f := range importfmt.All() {
+				fmt.Printf("%-10s %s\n", f.Name, f.Description)
+			}
+			return
+		case "copyq-script":
+			fmt.Println(importfmt.CopyQScript)
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	var (
+		dbPath   = fs.String("db", "./otterclip.dev.db", "sqlite db path")
+		from     = fs.String("from", "otterclip", "source format (see: otterclipctl import formats)")
+		dryRun   = fs.Bool("dry-run", false, "report what would be added, merged or skipped without writing")
+		verbose  = fs.Bool("verbose", false, "print every entry, not just the totals (implied by --dry-run)")
+		maxItems = fs.Int("max-items", defaultMaxItems(), "history cap of the app using the db, to warn about (default $OTTERCLIP_MAX_ITEMS, as the desktop app)")
+	)
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "import takes one input file (- for stdin)")
+		os.Exit(2)
+	}
+	f, ok := importfmt.Lookup(*from)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown --from %q (one of: %v)\n", *from, importfmt.Names())
+		os.Exit(2)
+	}
+
+	var in io.Reader = os.Stdin
+	if name := fs.Arg(0); name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "open error: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	st, err := sqlite.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	opts := importer.Options{DryRun: *dryRun, MaxItems: *maxItems}
+	if *dryRun || *verbose {
+		opts.OnEntry = func(e importer.Entry) {
+			fmt.Printf("%-5s %q", e.Outcome, e.Preview)
+			if e.Reason != "" {
+				fmt.Printf("  (%s)", e.Reason)
+			}
+			fmt.Println()
+		}
+	}
+
+	rep, err := importer.New(st).Import(context.Background(), f, in, opts)
+	verb := "imported"
+	if *dryRun {
+		verb = "would import"
+	}
+	fmt.Printf("%s from %s: %d added, %d merged, %d skipped\n", verb, f.Name, rep.Added, rep.Merged, rep.Skipped)
+	if rep.OverCap > 0 {
+		holds := "holds"
+		if *dryRun {
+			holds = "would hold"
+		}
+		fmt.Fprintf(os.Stderr, "warning: the history %s %d items, over the %d-item cap; the next captures delete the %d oldest unpinned ones.\n"+
+			"Raise the cap first ($OTTERCLIP_MAX_ITEMS for the desktop app, --max-items for otterclip), or pin what you want to keep.\n",
+			holds, rep.Items, *maxItems, rep.OverCap)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import error: %v\n", err)
+		os.Exit(1)
+	}
+}
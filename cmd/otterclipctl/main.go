@@ -1,27 +1,22 @@
 package main
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+	"github.com/its-jojoo/otterclip/internal/adapter/exportfmt"
 	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
 )
 
-type ExportItem struct {
-	ID          string `json:"id"`
-	Type        string `json:"type"`
-	Content     string `json:"content"`
-	Fingerprint string `json:"fingerprint"`
-	CreatedAt   string `json:"created_at"`
-	LastSeenAt  string `json:"last_seen_at"`
-	Pinned      bool   `json:"pinned"`
-}
-
 func main() {
 	if len(os.Args) < 2 {
 		usage()
@@ -31,6 +26,18 @@ func main() {
 	switch os.Args[1] {
 	case "export":
 		exportCmd(os.Args[2:])
+	case "collections":
+		collectionsCmd(os.Args[2:])
+	case "import":
+		importCmd(os.Args[2:])
+	case "backup":
+		backupCmd(os.Args[2:])
+	case "restore":
+		restoreCmd(os.Args[2:])
+	case "doctor":
+		doctorCmd(os.Args[2:])
+	case "stats":
+		statsCmd(os.Args[2:])
 	default:
 		usage()
 		os.Exit(2)
@@ -41,12 +48,39 @@ func usage() {
 	fmt.Println("otterclipctl")
 	fmt.Println("")
 	fmt.Println("Usage:")
-	fmt.Println("  otterclipctl export --db <path> [--out file] [--limit N] [--pinned-only] [--type t] [--since dur]")
+	fmt.Println("  otterclipctl export --db <path> [--format f] [--out file|-] [--separator s] [--limit N] [--pinned-only] [--type t] [--since dur]")
+	fmt.Println("  otterclipctl collections list --db <path>")
+	fmt.Println("  otterclipctl collections export --db <path> --name <collection> [--out file]")
+	fmt.Println("  otterclipctl collections import --db <path> --in <file> [--name <collection>]")
+	fmt.Println("  otterclipctl import --db <path> --from <format> [--dry-run] [--verbose] [--max-items N] <file|->")
+	fmt.Println("  otterclipctl import formats")
+	fmt.Println("  otterclipctl import copyq-script")
+	fmt.Println("  otterclipctl backup [--db <path>] [--dir d] [--keep N] [--passphrase-file f]")
+	fmt.Println("  otterclipctl backup list [--dir d]")
+	fmt.Println("  otterclipctl restore [--db <path>] [--check] [--passphrase-file f] (<snapshot> | --at <time> [--dir d])")
+	fmt.Println("  otterclipctl doctor --db <path> [--dry-run] [--no-vacuum]")
+	fmt.Println("  otterclipctl stats --db <path> [--days N] [--top N] [--json]")
+	fmt.Println("")
+	fmt.Println("backup is safe while OtterClip runs; quit it before restore. Snapshots are")
+	fmt.Println("encrypted when a passphrase is given (or $" + backup.PassphraseEnv + " is set).")
+	fmt.Println("backup and restore default to the desktop app's database and backup folder;")
+	fmt.Println("a backup folder only takes snapshots of the database it was first used for.")
+	fmt.Println("doctor checks the database and rewrites fingerprints after normalization")
+	fmt.Println("changes; quit OtterClip first, and try --dry-run to see what it would do.")
 	fmt.Println("")
 	fmt.Println("Examples:")
 	fmt.Println("  otterclipctl export --db ./otterclip.dev.db --out export.json --limit 2000")
 	fmt.Println("  otterclipctl export --db ./otterclip.dev.db --pinned-only --out pins.json")
 	fmt.Println("  otterclipctl export --db ./otterclip.dev.db --type url --since 168h")
+	fmt.Println("  otterclipctl export --db ./otterclip.dev.db --format ndjson --out - | gzip > history.ndjson.gz")
+	fmt.Println("  otterclipctl export --db ./otterclip.dev.db --format text --separator '\\x00' --out clips.txt")
+	fmt.Println("  otterclipctl collections export --db ./otterclip.dev.db --name onboarding --out onboarding.json")
+	fmt.Println("  otterclipctl import --db ./otterclip.dev.db --from clipman --dry-run ~/.local/share/clipman.json")
+	fmt.Println("  otterclipctl backup --db ~/.config/otterclip/otterclip.db --dir /mnt/usb/otterclip --keep 14")
+	fmt.Println("  otterclipctl restore --db ~/.config/otterclip/otterclip.db --at '2026-10-01 09:00'")
+	fmt.Println("  otterclipctl doctor --db ~/.config/otterclip/otterclip.db --dry-run")
+	fmt.Println("  otterclipctl stats --db ~/.config/otterclip/otterclip.db --days 7")
+	fmt.Println("  copyq eval \"$(otterclipctl import copyq-script)\" | otterclipctl import --db ./otterclip.dev.db --from copyq -")
 }
 
 func exportCmd(args []string) {
@@ -54,27 +88,26 @@ func exportCmd(args []string) {
 
 	var (
 		dbPath     = fs.String("db", "./otterclip.dev.db", "sqlite db path")
-		out        = fs.String("out", "otterclip-export.json", "output json file path")
-		limit      = fs.Int("limit", 5000, "max items to export (scanned)")
+		format     = fs.String("format", "json", "output format: "+strings.Join(exportfmt.Names(), "|"))
+		out        = fs.String("out", "", "output file path, - for stdout (default otterclip-export.<ext>)")
+		sep        = fs.String("separator", "", "item separator for --format text, with Go escapes such as \\n and \\x00 (default \\n---\\n)")
+		limit      = fs.Int("limit", 0, "max items to export (scanned); 0 exports everything")
 		pinnedOnly = fs.Bool("pinned-only", false, "export only pinned items")
-		typeFilter = fs.String("type", "", "filter by type: text|url|code|command")
+		typeFilter = fs.String("type", "", "filter by type: "+core.ContentTypeNames("|"))
 		sinceStr   = fs.String("since", "", "filter by last_seen_at age, e.g. 24h, 30m, 168h")
 	)
 
 	_ = fs.Parse(args)
 
-	st, err := sqlite.Open(*dbPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
-		os.Exit(1)
+	f, ok := exportfmt.Lookup(*format)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "invalid --format: %q (expected %s)\n", *format, strings.Join(exportfmt.Names(), "|"))
+		os.Exit(2)
 	}
-	defer st.Close()
-
-	ctx := context.Background()
-	items, err := st.ListRecent(ctx, *limit)
+	separator, err := strconv.Unquote(`"` + strings.ReplaceAll(*sep, `"`, `\"`) + `"`)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "list error: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "invalid --separator: %v\n", err)
+		os.Exit(2)
 	}
 
 	var since time.Time
@@ -87,48 +120,65 @@ func exportCmd(args []string) {
 		since = time.Now().Add(-d)
 	}
 
-	tf := strings.TrimSpace(strings.ToLower(*typeFilter))
-	if tf != "" && tf != "text" && tf != "url" && tf != "code" && tf != "command" {
-		fmt.Fprintf(os.Stderr, "invalid --type: %q (expected text|url|code|command)\n", *typeFilter)
-		os.Exit(2)
+	var tf core.ContentType
+	if strings.TrimSpace(*typeFilter) != "" {
+		t, ok := core.ParseContentType(*typeFilter)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "invalid --type: %q (expected %s)\n", *typeFilter, core.ContentTypeNames("|"))
+			os.Exit(2)
+		}
+		tf = t
+	}
+
+	st, err := sqlite.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	if *out == "" {
+		*out = "otterclip-export." + f.Ext
 	}
+	var dst io.Writer = os.Stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "create output error: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		dst = file
+	}
+	bw := bufio.NewWriter(dst)
 
-	export := make([]ExportItem, 0, len(items))
-	for _, it := range items {
+	w := f.New(bw, exportfmt.Options{Separator: separator})
+	n := 0
+	err = st.WalkRecent(context.Background(), *limit, func(it core.Item) error {
 		if *pinnedOnly && !it.Pinned {
-			continue
+			return nil
 		}
-		if tf != "" && strings.ToLower(string(it.Type)) != tf {
-			continue
+		if tf != "" && it.Type != tf {
+			return nil
 		}
 		if !since.IsZero() && it.LastSeenAt.Before(since) {
-			continue
+			return nil
 		}
-
-		export = append(export, ExportItem{
-			ID:          it.ID,
-			Type:        string(it.Type),
-			Content:     it.Content,
-			Fingerprint: it.Fingerprint,
-			CreatedAt:   it.CreatedAt.UTC().Format(time.RFC3339Nano),
-			LastSeenAt:  it.LastSeenAt.UTC().Format(time.RFC3339Nano),
-			Pinned:      it.Pinned,
-		})
+		n++
+		return w.Write(it)
+	})
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		err = bw.Flush()
 	}
-
-	f, err := os.Create(*out)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "create output error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "export error: %v\n", err)
 		os.Exit(1)
 	}
-	defer f.Close()
 
-	enc := json.NewEncoder(f)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(export); err != nil {
-		fmt.Fprintf(os.Stderr, "encode error: %v\n", err)
-		os.Exit(1)
+	if *out != "-" {
+		fmt.Println("exported", n, "items to", *out)
 	}
-
-	fmt.Println("exported", len(export), "items to", *out)
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func statsCmd(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	var (
+		dbPath = fs.String("db", "./otterclip.dev.db", "sqlite db path")
+		days   = fs.Int("days", 30, "days covered by the activity figures; 0 covers all time")
+		top    = fs.Int("top", 10, "items listed as most copied and largest")
+		asJSON = fs.Bool("json", false, "print JSON instead of text")
+	)
+	_ = fs.Parse(args)
+
+	st, err := sqlite.Open(*dbPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "db open error: %v\n", err)
+		os.Exit(1)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	var since time.Time
+	if *days > 0 {
+		y, m, d := time.Now().Date()
+		since = time.Date(y, m, d-*days+1, 0, 0, 0, 0, time.Local)
+	}
+	s, err := st.Stats(ctx, sqlite.StatsOptions{Since: since, Top: *top})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stats error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s); err != nil {
+			fmt.Fprintf(os.Stderr, "stats error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printStats(ctx, st, s)
+}
+
+func printStats(ctx context.Context, st *sqlite.Store, s sqlite.Stats) {
+	fmt.Printf("history   %d items (%d pinned, %d in trash), %s of content; database %s\n",
+		s.Items, s.Pinned, s.Trashed, byteSize(s.ContentBytes), byteSize(s.DBBytes))
+	if !s.Since.IsZero() {
+		fmt.Printf("since     %s (%d days)\n", s.Since.Format("2006-01-02"), len(s.Days))
+	}
+
+	c := s.Counters
+	copies := c[core.CountStored] + c[core.CountDeduped]
+	fmt.Printf("copies    %d (%d new, %d repeats: %.1f%% deduped)\n",
+		copies, c[core.CountStored], c[core.CountDeduped], 100*s.DedupeRate)
+	fmt.Printf("ignored   %d by the privacy filter, %d by app rules, %d from password managers, %d by processors\n",
+		c[core.CountIgnoredPrivacy], c[core.CountIgnoredApp], c[core.CountIgnoredConcealed], c[core.CountFiltered])
+
+	fmt.Println("\ntypes")
+	for _, tc := range s.Types {
+		fmt.Printf("  %-12s %6d  %5.1f%%\n", tc.Type, tc.Count, percent(tc.Count, s.Items))
+	}
+
+	fmt.Println("\nper day        copies  added")
+	for _, d := range s.Days {
+		fmt.Printf("  %s  %6d %6d\n", d.Day, d.Copies, d.Added)
+	}
+
+	fmt.Println("\nper hour       copies  added")
+	peak := 0
+	for _, h := range s.Hours {
+		peak = max(peak, h.Copies+h.Added)
+	}
+	for _, h := range s.Hours {
+		line := fmt.Sprintf("  %02d:00       %6d %6d  %s", h.Hour, h.Copies, h.Added, bar(h.Copies+h.Added, peak, 30))
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	if len(s.MostCopied) > 0 {
+		fmt.Println("\nmost copied")
+		for _, ic := range s.MostCopied {
+			fmt.Printf("  %s  %5d×  %s\n", ic.Item.ShortID(), ic.Count, oneLine(ic.Item.Content, 60))
+		}
+	}
+	if len(s.Largest) > 0 {
+		fmt.Println("\nlargest")
+		for _, is := range s.Largest {
+			text := ""
+			if items, err := st.FindByIDPrefix(ctx, is.ID, 1); err == nil && len(items) == 1 {
+				text = oneLine(items[0].Content, 60)
+			}
+			fmt.Printf("  %s  %9s  %s\n", shortID(is.ID), byteSize(int64(is.Bytes)), text)
+		}
+	}
+}
+
+func percent(n, of int) float64 {
+	if of == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(of)
+}
+
+// bar draws n out of peak as up to width blocks.
+func bar(n, peak, width int) string {
+	if peak == 0 {
+		return ""
+	}
+	return strings.Repeat("█", (n*width+peak-1)/peak)
+}
+
+func byteSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func oneLine(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
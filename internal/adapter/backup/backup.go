@@ -0,0 +1,423 @@
+// Package backup keeps point-in-time snapshots of the history database:
+// consistent copies taken while the app runs, optionally encrypted,
+// rotated on a schedule, and restored only once they verify.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+)
+
+// Source takes a consistent copy of the live database; *sqlite.Store is
+// one.
+type Source interface {
+	Backup(ctx context.Context, path string) error
+}
+
+type Config struct {
+	// Dir holds the snapshots, one file each.
+	Dir string
+	// Keep is how many snapshots Rotate keeps; zero keeps them all.
+	Keep int
+	// Passphrase encrypts new snapshots when set.
+	Passphrase []byte
+	// Database is the path of the database the snapshots are taken from.
+	// When set, Dir is tagged with it, and the Manager refuses to add to,
+	// rotate or restore from a Dir tagged with another database.
+	Database string
+}
+
+// Snapshot is a backup file in Dir.
+type Snapshot struct {
+	Path      string
+	Time      time.Time
+	Size      int64
+	Encrypted bool
+}
+
+// Snapshot files are named after the UTC time they were taken, so they
+// sort chronologically.
+const (
+	namePrefix = "otterclip-"
+	nameLayout = "20060102-150405"
+	plainExt   = ".db"
+	cryptExt   = ".db.enc"
+)
+
+var ErrNoSnapshot = errors.New("no snapshot")
+
+// ErrOtherDatabase is returned for a Dir that holds the snapshots of
+// another database than Config.Database.
+var ErrOtherDatabase = errors.New("backup directory holds snapshots of another database")
+
+// sourceFile in Dir names the database its snapshots are taken from.
+const sourceFile = ".source"
+
+// PassphraseEnv names the environment variable the app and the CLIs read
+// the backup passphrase from, so it never shows up in a process list.
+const PassphraseEnv = "OTTERCLIP_BACKUP_PASSPHRASE"
+
+type Manager struct {
+	src Source
+	cfg Config
+	now func() time.Time
+}
+
+// New manages the snapshots of src in cfg.Dir. src may be nil when only
+// listing and restoring.
+func New(src Source, cfg Config) *Manager {
+	return &Manager{src: src, cfg: cfg, now: time.Now}
+}
+
+// Create takes a snapshot and verifies it before it appears in Dir.
+func (m *Manager) Create(ctx context.Context) (Snapshot, error) {
+	if err := os.MkdirAll(m.cfg.Dir, 0o700); err != nil {
+		return Snapshot{}, err
+	}
+	if err := m.claim(); err != nil {
+		return Snapshot{}, err
+	}
+	ext := plainExt
+	if len(m.cfg.Passphrase) > 0 {
+		ext = cryptExt
+	}
+	t := m.now().UTC().Truncate(time.Second)
+	path := m.path(t, ext)
+	for exists(path) || exists(m.path(t, plainExt)) || exists(m.path(t, cryptExt)) {
+		t = t.Add(time.Second)
+		path = m.path(t, ext)
+	}
+
+	// VACUUM INTO refuses to overwrite, so clear a copy left by a crash
+	tmp := filepath.Join(m.cfg.Dir, ".tmp-"+filepath.Base(path))
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+	if err := m.src.Backup(ctx, tmp); err != nil {
+		return Snapshot{}, fmt.Errorf("backup: %w", err)
+	}
+	if _, err := sqlite.Verify(ctx, tmp); err != nil {
+		return Snapshot{}, fmt.Errorf("backup: %w", err)
+	}
+
+	if ext == cryptExt {
+		enc := tmp + ".enc"
+		defer os.Remove(enc)
+		if err := encryptFile(enc, tmp, m.cfg.Passphrase); err != nil {
+			return Snapshot{}, fmt.Errorf("backup: encrypt: %w", err)
+		}
+		tmp = enc
+	}
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		return Snapshot{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Snapshot{}, err
+	}
+	return stat(path, t, ext == cryptExt)
+}
+
+// claim tags Dir with Config.Database, unless it is tagged already.
+func (m *Manager) claim() error {
+	db, tag, err := m.checkSource()
+	if err != nil || db == "" || tag != "" {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.cfg.Dir, sourceFile), []byte(db+"\n"), 0o600)
+}
+
+// checkSource returns Config.Database as an absolute path and the
+// database Dir is tagged with, if any. It returns ErrOtherDatabase when
+// both are set and differ.
+func (m *Manager) checkSource() (db, tag string, err error) {
+	if m.cfg.Database == "" {
+		return "", "", nil
+	}
+	if db, err = filepath.Abs(m.cfg.Database); err != nil {
+		return "", "", err
+	}
+	b, err := os.ReadFile(filepath.Join(m.cfg.Dir, sourceFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return db, "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if tag = strings.TrimSpace(string(b)); tag != db {
+		return "", "", fmt.Errorf("%w: %s is for %s", ErrOtherDatabase, m.cfg.Dir, tag)
+	}
+	return db, tag, nil
+}
+
+func (m *Manager) path(t time.Time, ext string) string {
+	return filepath.Join(m.cfg.Dir, namePrefix+t.Format(nameLayout)+ext)
+}
+
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func stat(path string, t time.Time, encrypted bool) (Snapshot, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Path: path, Time: t, Size: fi.Size(), Encrypted: encrypted}, nil
+}
+
+func encryptFile(dst, src string, passphrase []byte) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := Encrypt(out, in, passphrase); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// List returns the snapshots in Dir, newest first.
+func (m *Manager) List() ([]Snapshot, error) {
+	entries, err := os.ReadDir(m.cfg.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Snapshot
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, namePrefix) {
+			continue
+		}
+		stamp, encrypted := strings.CutSuffix(strings.TrimPrefix(name, namePrefix), cryptExt)
+		if !encrypted {
+			var ok bool
+			if stamp, ok = strings.CutSuffix(stamp, plainExt); !ok {
+				continue
+			}
+		}
+		t, err := time.Parse(nameLayout, stamp)
+		if err != nil {
+			continue
+		}
+		s, err := stat(filepath.Join(m.cfg.Dir, name), t, encrypted)
+		if err != nil {
+			continue // removed meanwhile
+		}
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b Snapshot) int { return b.Time.Compare(a.Time) })
+	return out, nil
+}
+
+// At returns the newest snapshot taken at or before t.
+func (m *Manager) At(t time.Time) (Snapshot, error) {
+	if _, _, err := m.checkSource(); err != nil {
+		return Snapshot{}, err
+	}
+	snaps, err := m.List()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	for _, s := range snaps {
+		if !s.Time.After(t) {
+			return s, nil
+		}
+	}
+	return Snapshot{}, fmt.Errorf("%w taken before %s", ErrNoSnapshot, t.Format(time.RFC3339))
+}
+
+// Rotate deletes all but the newest Keep snapshots and returns the ones it
+// deleted.
+func (m *Manager) Rotate() ([]Snapshot, error) {
+	if m.cfg.Keep > 0 {
+		if _, _, err := m.checkSource(); err != nil {
+			return nil, err
+		}
+	}
+	snaps, err := m.List()
+	if err != nil || m.cfg.Keep <= 0 || len(snaps) <= m.cfg.Keep {
+		return nil, err
+	}
+	var removed []Snapshot
+	for _, s := range snaps[m.cfg.Keep:] {
+		if err := os.Remove(s.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, s)
+	}
+	return removed, nil
+}
+
+// Run takes a snapshot whenever the newest is older than every, then
+// rotates, until ctx is done. It checks at least hourly rather than
+// sleeping for the whole interval, so a laptop that was suspended catches
+// up soon after it wakes. Errors go to onErr, which may be nil.
+func (m *Manager) Run(ctx context.Context, every time.Duration, onErr func(error)) {
+	if onErr == nil {
+		onErr = func(error) {}
+	}
+	check := min(every, time.Hour)
+	tick := time.NewTicker(check)
+	defer tick.Stop()
+	for {
+		if err := m.runDue(ctx, every); err != nil && ctx.Err() == nil {
+			onErr(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+func (m *Manager) runDue(ctx context.Context, every time.Duration) error {
+	snaps, err := m.List()
+	if err != nil {
+		return err
+	}
+	if len(snaps) > 0 && m.now().Sub(snaps[0].Time) < every {
+		return nil
+	}
+	if _, err := m.Create(ctx); err != nil {
+		return err
+	}
+	_, err = m.Rotate()
+	return err
+}
+
+// Restored describes a completed restore.
+type Restored struct {
+	// Items is how many history items the restored database holds.
+	Items int
+	// Previous is a copy of the database the restore replaced, if there
+	// was one.
+	Previous string
+}
+
+// Restore replaces the database at dbPath with the snapshot at path,
+// decrypting it with passphrase if needed. The snapshot is verified before
+// anything is touched, and the current database is first copied next to
+// it. OtterClip must not be running: Restore returns sqlite.ErrInUse
+// while anything has the database open.
+func Restore(ctx context.Context, path, dbPath string, passphrase []byte) (Restored, error) {
+	if exists(dbPath) {
+		if err := sqlite.CheckUnused(ctx, dbPath); errors.Is(err, sqlite.ErrInUse) {
+			return Restored{}, fmt.Errorf("restore: %w (quit OtterClip first)", err)
+		}
+	}
+	tmp, items, err := prepare(ctx, path, filepath.Dir(dbPath), passphrase)
+	if err != nil {
+		return Restored{}, fmt.Errorf("restore: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	r := Restored{Items: items}
+	if exists(dbPath) {
+		r.Previous = dbPath + ".before-restore-" + time.Now().UTC().Format(nameLayout)
+		if err := copyLive(ctx, dbPath, r.Previous); err != nil {
+			// too damaged to open: move the files aside as they are
+			if err := os.Rename(dbPath, r.Previous); err != nil {
+				return Restored{}, fmt.Errorf("restore: keep current database: %w", err)
+			}
+			os.Rename(dbPath+"-wal", r.Previous+"-wal")
+		}
+	}
+	// a stale WAL would be replayed into the restored file
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return r, err
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// Check verifies the snapshot at path as Restore would, without
+// restoring it, and returns how many items it holds.
+func Check(ctx context.Context, path string, passphrase []byte) (int, error) {
+	tmp, items, err := prepare(ctx, path, "", passphrase)
+	if err != nil {
+		return 0, err
+	}
+	os.Remove(tmp)
+	return items, nil
+}
+
+// prepare unpacks the snapshot at path into a temporary file in dir and
+// verifies it.
+func prepare(ctx context.Context, path, dir string, passphrase []byte) (string, int, error) {
+	tmp, err := os.CreateTemp(dir, ".otterclip-restore-*.db")
+	if err != nil {
+		return "", 0, err
+	}
+	err = unpack(tmp, path, passphrase)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	var items int
+	if err == nil {
+		items, err = sqlite.Verify(ctx, tmp.Name())
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", 0, err
+	}
+	return tmp.Name(), items, nil
+}
+
+// unpack writes the snapshot at path to dst, decrypting it if needed.
+func unpack(dst io.Writer, path string, passphrase []byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header := make([]byte, len(magic))
+	n, _ := io.ReadFull(f, header)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if !IsEncrypted(header[:n]) {
+		_, err = io.Copy(dst, f)
+		return err
+	}
+	if len(passphrase) == 0 {
+		return errors.New("snapshot is encrypted; a passphrase is required")
+	}
+	return Decrypt(dst, f, passphrase)
+}
+
+// copyLive copies the database at src, WAL included, to dst.
+func copyLive(ctx context.Context, src, dst string) error {
+	st, err := sqlite.Open(src)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	return st.Backup(ctx, dst)
+}
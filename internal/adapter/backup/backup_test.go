@@ -0,0 +1,292 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func openStore(t *testing.T, path string, contents ...string) *sqlite.Store {
+	t.Helper()
+	st, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	for _, c := range contents {
+		add(t, st, c)
+	}
+	return st
+}
+
+func add(t *testing.T, st *sqlite.Store, content string) {
+	t.Helper()
+	now := time.Now()
+	it := core.Item{
+		ID: content, Content: content, Type: core.ContentTypeText,
+		Fingerprint: core.Fingerprint(content), CreatedAt: now, LastSeenAt: now,
+	}
+	if err := st.Put(context.Background(), it, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// clock returns a Manager clock starting at start and advancing by step
+// on each reading.
+func clock(start time.Time, step time.Duration) func() time.Time {
+	t := start.Add(-step)
+	return func() time.Time { t = t.Add(step); return t }
+}
+
+func TestManager_CreateListRotateAt(t *testing.T) {
+	dir := t.TempDir()
+	st := openStore(t, filepath.Join(dir, "live.db"), "one")
+	m := New(st, Config{Dir: filepath.Join(dir, "backups"), Keep: 2})
+	start := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	m.now = clock(start, time.Hour)
+
+	ctx := context.Background()
+	for range 3 {
+		if _, err := m.Create(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snaps, err := m.List()
+	if err != nil || len(snaps) != 3 {
+		t.Fatalf("List = %v, %v", snaps, err)
+	}
+	if !snaps[0].Time.Equal(start.Add(2*time.Hour)) || filepath.Base(snaps[0].Path) != "otterclip-20261019-100000.db" {
+		t.Fatalf("newest = %+v", snaps[0])
+	}
+	if fi, _ := os.Stat(snaps[0].Path); fi.Mode().Perm() != 0o600 {
+		t.Fatalf("mode = %v", fi.Mode())
+	}
+
+	s, err := m.At(start.Add(90 * time.Minute))
+	if err != nil || !s.Time.Equal(start.Add(time.Hour)) {
+		t.Fatalf("At = %+v, %v", s, err)
+	}
+	if _, err := m.At(start.Add(-time.Minute)); !errors.Is(err, ErrNoSnapshot) {
+		t.Fatalf("At before first: %v", err)
+	}
+
+	removed, err := m.Rotate()
+	if err != nil || len(removed) != 1 || !removed[0].Time.Equal(start) {
+		t.Fatalf("Rotate = %v, %v", removed, err)
+	}
+	if snaps, _ := m.List(); len(snaps) != 2 {
+		t.Fatalf("kept %d snapshots", len(snaps))
+	}
+
+	// no leftovers from the temporary copies
+	entries, _ := os.ReadDir(m.cfg.Dir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") {
+			t.Fatalf("leftover %s", e.Name())
+		}
+	}
+}
+
+func TestManager_SameSecond(t *testing.T) {
+	dir := t.TempDir()
+	st := openStore(t, filepath.Join(dir, "live.db"), "one")
+	m := New(st, Config{Dir: dir})
+	m.now = clock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+
+	a, err := m.Create(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Create(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Path == b.Path || !b.Time.After(a.Time) {
+		t.Fatalf("snapshots collide: %s, %s", a.Path, b.Path)
+	}
+}
+
+func TestManager_RefusesOtherDatabase(t *testing.T) {
+	dir := t.TempDir()
+	backups := filepath.Join(dir, "backups")
+	ctx := context.Background()
+	app := filepath.Join(dir, "app.db")
+	m := New(openStore(t, app, "one"), Config{Dir: backups, Keep: 1, Database: app})
+	m.now = clock(time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC), time.Hour)
+	if _, err := m.Create(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Create(ctx); err != nil {
+		t.Fatalf("second snapshot of the same database: %v", err)
+	}
+
+	dev := filepath.Join(dir, "dev.db")
+	other := New(openStore(t, dev, "two"), Config{Dir: backups, Keep: 1, Database: dev})
+	if _, err := other.Create(ctx); !errors.Is(err, ErrOtherDatabase) {
+		t.Fatalf("Create into another database's dir: %v", err)
+	}
+	if _, err := other.Rotate(); !errors.Is(err, ErrOtherDatabase) {
+		t.Fatalf("Rotate another database's dir: %v", err)
+	}
+	if _, err := other.At(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrOtherDatabase) {
+		t.Fatalf("At in another database's dir: %v", err)
+	}
+	if snaps, _ := m.List(); len(snaps) != 2 {
+		t.Fatalf("expected both snapshots kept, got %d", len(snaps))
+	}
+
+	// without a database to compare, the dir is open to anyone
+	if _, err := New(nil, Config{Dir: backups}).At(time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManager_RunDue(t *testing.T) {
+	dir := t.TempDir()
+	st := openStore(t, filepath.Join(dir, "live.db"), "one")
+	m := New(st, Config{Dir: filepath.Join(dir, "backups"), Keep: 2})
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	steps := []struct {
+		advance time.Duration
+		want    int
+	}{
+		{0, 1},              // nothing yet: back up right away
+		{time.Hour, 1},      // not due
+		{23 * time.Hour, 2}, // a day after the first
+		{48 * time.Hour, 2}, // rotated down to Keep
+	}
+	for i, s := range steps {
+		now = now.Add(s.advance)
+		if err := m.runDue(ctx, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		if snaps, _ := m.List(); len(snaps) != s.want {
+			t.Fatalf("step %d: %d snapshots, want %d", i, len(snaps), s.want)
+		}
+	}
+}
+
+func TestRestore_Encrypted(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "live.db")
+	st := openStore(t, dbPath, "keep me", "and me")
+	if err := st.SetPinned(context.Background(), "keep me", true); err != nil {
+		t.Fatal(err)
+	}
+	pass := []byte("s3cret")
+	m := New(st, Config{Dir: filepath.Join(dir, "backups"), Passphrase: pass})
+	ctx := context.Background()
+	snap, err := m.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.Encrypted || !strings.HasSuffix(snap.Path, ".db.enc") {
+		t.Fatalf("snapshot = %+v", snap)
+	}
+	raw, _ := os.ReadFile(snap.Path)
+	if strings.Contains(string(raw), "keep me") {
+		t.Fatal("encrypted snapshot holds plaintext")
+	}
+
+	// the history moves on after the snapshot
+	add(t, st, "later")
+	st.Close()
+
+	if _, err := Check(ctx, snap.Path, nil); err == nil {
+		t.Fatal("checked an encrypted snapshot without a passphrase")
+	}
+	if _, err := Restore(ctx, snap.Path, dbPath, []byte("wrong")); !errors.Is(err, ErrPassphrase) {
+		t.Fatalf("wrong passphrase: %v", err)
+	}
+	if n, err := Check(ctx, snap.Path, pass); err != nil || n != 2 {
+		t.Fatalf("Check = %d, %v", n, err)
+	}
+
+	r, err := Restore(ctx, snap.Path, dbPath, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Items != 2 || r.Previous == "" {
+		t.Fatalf("restored = %+v", r)
+	}
+
+	restored := openStore(t, dbPath)
+	if n, _ := restored.Count(ctx); n != 2 {
+		t.Fatalf("restored count = %d", n)
+	}
+	if it, err := restored.FindByFingerprint(ctx, core.Fingerprint("keep me")); err != nil || !it.Pinned {
+		t.Fatalf("pin lost: %+v, %v", it, err)
+	}
+	if n, err := sqlite.Verify(ctx, r.Previous); err != nil || n != 3 {
+		t.Fatalf("previous database = %d items, %v", n, err)
+	}
+}
+
+func TestRestore_RejectsDamagedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "live.db")
+	st := openStore(t, dbPath, "precious")
+	m := New(st, Config{Dir: filepath.Join(dir, "backups")})
+	snap, err := m.Create(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	// damage the snapshot's first page
+	f, err := os.OpenFile(snap.Path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt([]byte(strings.Repeat("x", 200)), 0)
+	f.Close()
+
+	if _, err := Restore(context.Background(), snap.Path, dbPath, nil); !errors.Is(err, sqlite.ErrCorrupt) {
+		t.Fatalf("err = %v, want ErrCorrupt", err)
+	}
+	if n, err := sqlite.Verify(context.Background(), dbPath); err != nil || n != 1 {
+		t.Fatalf("live database touched: %d, %v", n, err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, ".otterclip-restore-*")); len(matches) != 0 {
+		t.Fatalf("leftovers: %v", matches)
+	}
+}
+
+func TestRestore_RefusesOpenDatabase(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "live.db")
+	st := openStore(t, dbPath, "before")
+	ctx := context.Background()
+	snap, err := New(st, Config{Dir: filepath.Join(dir, "backups")}).Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(t, st, "after")
+
+	// the store is still open, as it is while OtterClip runs
+	if _, err := Restore(ctx, snap.Path, dbPath, nil); !errors.Is(err, sqlite.ErrInUse) {
+		t.Fatalf("err = %v, want ErrInUse", err)
+	}
+	if n, err := st.Count(ctx); err != nil || n != 2 {
+		t.Fatalf("live database touched: %d, %v", n, err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "live.db.before-restore-*")); len(matches) != 0 {
+		t.Fatalf("leftovers: %v", matches)
+	}
+
+	st.Close()
+	if r, err := Restore(ctx, snap.Path, dbPath, nil); err != nil || r.Items != 1 {
+		t.Fatalf("restore after closing = %+v, %v", r, err)
+	}
+}
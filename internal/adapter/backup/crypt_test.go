@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func init() {
+	kdfIterations = 1000
+}
+
+func encrypt(t *testing.T, plain, pass []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Encrypt(&buf, bytes.NewReader(plain), pass); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	pass := []byte("correct horse")
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3 * chunkSize} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		enc := encrypt(t, plain, pass)
+		if !IsEncrypted(enc) {
+			t.Fatalf("%d: missing magic", size)
+		}
+		if bytes.Contains(enc, plain[:min(size, 64)]) && size >= 16 {
+			t.Fatalf("%d: plaintext visible", size)
+		}
+		var got bytes.Buffer
+		if err := Decrypt(&got, bytes.NewReader(enc), pass); err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		if !bytes.Equal(got.Bytes(), plain) {
+			t.Fatalf("%d: round trip differs", size)
+		}
+	}
+}
+
+func TestDecrypt_Rejects(t *testing.T) {
+	pass := []byte("pw")
+	plain := bytes.Repeat([]byte("otter"), chunkSize/2) // 2.5 chunks
+	enc := encrypt(t, plain, pass)
+	full := chunkSize + tagSize
+
+	tamper := func(f func(b []byte) []byte) []byte {
+		return f(bytes.Clone(enc))
+	}
+	cases := map[string][]byte{
+		"wrong passphrase": nil,
+		// a cut at a chunk boundary loses the final-chunk flag
+		"truncated":      enc[:headerSize+2*full],
+		"short chunk":    enc[:len(enc)-1],
+		"flipped bit":    tamper(func(b []byte) []byte { b[headerSize+10] ^= 1; return b }),
+		"salt changed":   tamper(func(b []byte) []byte { b[len(magic)] ^= 1; return b }),
+		"chunks swapped": tamper(func(b []byte) []byte { copy(b[headerSize:], enc[headerSize+full:headerSize+2*full]); return b }),
+		"extended":       append(bytes.Clone(enc), enc[headerSize:headerSize+full]...),
+	}
+	for name, data := range cases {
+		key := pass
+		if data == nil {
+			data, key = enc, []byte("nope")
+		}
+		err := Decrypt(&bytes.Buffer{}, bytes.NewReader(data), key)
+		if !errors.Is(err, ErrPassphrase) {
+			t.Errorf("%s: err = %v, want ErrPassphrase", name, err)
+		}
+	}
+
+	huge := tamper(func(b []byte) []byte {
+		binary.BigEndian.PutUint32(b[len(magic)+saltSize:], 1<<31)
+		return b
+	})
+	if err := Decrypt(&bytes.Buffer{}, bytes.NewReader(huge), pass); err == nil {
+		t.Error("accepted an absurd key derivation cost")
+	}
+	if err := Decrypt(&bytes.Buffer{}, bytes.NewReader(plain), pass); err == nil {
+		t.Error("decrypted a plain file")
+	}
+	if err := Encrypt(&bytes.Buffer{}, bytes.NewReader(plain), nil); err == nil {
+		t.Error("encrypted without a passphrase")
+	}
+}
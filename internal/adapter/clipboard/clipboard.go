@@ -1,6 +1,11 @@
 package clipboard
 
-import "context"
+import (
+	"context"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
 
 // Watch emits a signal when clipboard *may* have changed.
 // Implementations can poll or subscribe to OS events.
@@ -8,3 +13,38 @@ type Watcher interface {
 	Watch(ctx context.Context) (<-chan struct{}, error)
 	ReadText() (string, error)
 }
+
+// Writer puts text on the system clipboard.
+type Writer interface {
+	WriteText(text string) error
+}
+
+// System is the platform clipboard: it can be watched, read and written.
+// NewSystem returns the implementation for the current OS.
+type System interface {
+	Watcher
+	Writer
+}
+
+// SourceReader is implemented by watchers that can tell which application
+// currently owns the clipboard (usually the focused window).
+type SourceReader interface {
+	ReadSource() (core.Source, error)
+}
+
+// TargetReader is implemented by watchers that can list the formats
+// (MIME types / pasteboard types) offered by the current clipboard owner.
+type TargetReader interface {
+	ReadTargets() ([]string, error)
+}
+
+func splitTargets(out []byte) []string {
+	var targets []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			targets = append(targets, line)
+		}
+	}
+	return targets
+}
@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
 )
 
 type DarwinWatcher struct {
@@ -23,6 +25,8 @@ func NewDarwinWatcher(interval time.Duration) *DarwinWatcher {
 	return &DarwinWatcher{Interval: interval}
 }
 
+func NewSystem(interval time.Duration) System { return NewDarwinWatcher(interval) }
+
 func (w *DarwinWatcher) Watch(ctx context.Context) (<-chan struct{}, error) {
 	ch := make(chan struct{}, 1)
 
@@ -71,3 +75,34 @@ func (w *DarwinWatcher) ReadText() (string, error) {
 	// keep raw as-is; normalization happens in core
 	return strings.TrimRight(out.String(), "\n"), nil
 }
+
+func (w *DarwinWatcher) ReadSource() (core.Source, error) {
+	// frontmost app is the best guess for who just wrote the pasteboard
+	cmd := exec.Command("osascript", "-e",
+		`tell application "System Events" to get {bundle identifier, name} of first process whose frontmost is true`)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return core.Source{}, err
+	}
+	bundle, name, _ := strings.Cut(strings.TrimSpace(out.String()), ", ")
+	return core.Source{AppID: bundle, Process: name}, nil
+}
+
+func (w *DarwinWatcher) ReadTargets() ([]string, error) {
+	// NSPasteboard types, one per line (JXA bridge)
+	cmd := exec.Command("osascript", "-l", "JavaScript", "-e",
+		`ObjC.import("AppKit"); $.NSPasteboard.generalPasteboard.types.js.map(t => t.js).join("\n")`)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+	return splitTargets(out.Bytes()), nil
+}
+
+func (w *DarwinWatcher) WriteText(text string) error {
+	cmd := exec.Command("pbcopy")
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
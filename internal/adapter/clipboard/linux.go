@@ -0,0 +1,202 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// LinuxWatcher polls the clipboard through wl-paste (Wayland) or xclip (X11).
+type LinuxWatcher struct {
+	Interval time.Duration
+
+	// HintPath, if set, is read by ReadSource instead of querying the
+	// window system (see HintFileSource).
+	HintPath string
+
+	wayland bool
+	last    string
+
+	// run executes an external helper and returns its stdout; replaced in
+	// tests to fake the selection owner.
+	run func(name string, args ...string) ([]byte, error)
+}
+
+func NewLinuxWatcher(interval time.Duration) *LinuxWatcher {
+	if interval <= 0 {
+		interval = 350 * time.Millisecond
+	}
+	return &LinuxWatcher{
+		Interval: interval,
+		wayland:  os.Getenv("WAYLAND_DISPLAY") != "",
+		run:      runCommand,
+	}
+}
+
+func NewSystem(interval time.Duration) System { return NewLinuxWatcher(interval) }
+
+func runCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+func (w *LinuxWatcher) Watch(ctx context.Context) (<-chan struct{}, error) {
+	ch := make(chan struct{}, 1)
+
+	// prime initial state
+	if txt, err := w.ReadText(); err == nil {
+		w.last = txt
+	}
+
+	t := time.NewTicker(w.Interval)
+
+	go func() {
+		defer t.Stop()
+		defer close(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				txt, err := w.ReadText()
+				if err != nil {
+					continue
+				}
+				if txt != "" && txt != w.last {
+					w.last = txt
+					select {
+					case ch <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (w *LinuxWatcher) ReadText() (string, error) {
+	var out []byte
+	var err error
+	if w.wayland {
+		out, err = w.run("wl-paste", "--no-newline", "--type", "text")
+	} else {
+		out, err = w.run("xclip", "-selection", "clipboard", "-o")
+	}
+	if err != nil {
+		return "", err
+	}
+	// keep raw as-is; normalization happens in core
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+func (w *LinuxWatcher) WriteText(text string) error {
+	var cmd *exec.Cmd
+	if w.wayland {
+		cmd = exec.Command("wl-copy")
+	} else {
+		cmd = exec.Command("xclip", "-selection", "clipboard", "-i")
+	}
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// ReadTargets lists the formats offered by the selection owner, which is
+// where password managers put their "don't record this" hints.
+func (w *LinuxWatcher) ReadTargets() ([]string, error) {
+	var out []byte
+	var err error
+	if w.wayland {
+		out, err = w.run("wl-paste", "--list-types")
+	} else {
+		out, err = w.run("xclip", "-selection", "clipboard", "-t", "TARGETS", "-o")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return splitTargets(out), nil
+}
+
+func (w *LinuxWatcher) ReadSource() (core.Source, error) {
+	if w.HintPath != "" {
+		return HintFileSource{Path: w.HintPath}.ReadSource()
+	}
+	if w.wayland {
+		// Wayland has no generic way to ask for the focused window;
+		// try compositors that expose one, then fall back to XWayland.
+		if src, err := w.hyprlandSource(); err == nil {
+			return src, nil
+		}
+	}
+	return w.x11Source()
+}
+
+var (
+	reActiveWindow = regexp.MustCompile(`window id # (0x[0-9a-fA-F]+)`)
+	reWMClass      = regexp.MustCompile(`WM_CLASS\(STRING\) = "([^"]*)", "([^"]*)"`)
+	reWMPid        = regexp.MustCompile(`_NET_WM_PID\(CARDINAL\) = (\d+)`)
+)
+
+// x11Source resolves _NET_ACTIVE_WINDOW to its WM_CLASS and process name.
+func (w *LinuxWatcher) x11Source() (core.Source, error) {
+	out, err := w.run("xprop", "-root", "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return core.Source{}, err
+	}
+	m := reActiveWindow.FindSubmatch(out)
+	if m == nil {
+		return core.Source{}, errors.New("no active window")
+	}
+
+	out, err = w.run("xprop", "-id", string(m[1]), "WM_CLASS", "_NET_WM_PID")
+	if err != nil {
+		return core.Source{}, err
+	}
+
+	var src core.Source
+	if m := reWMClass.FindSubmatch(out); m != nil {
+		src.WindowClass = string(m[2])
+	}
+	if m := reWMPid.FindSubmatch(out); m != nil {
+		src.Process = processName(string(m[1]))
+	}
+	return src, nil
+}
+
+func (w *LinuxWatcher) hyprlandSource() (core.Source, error) {
+	out, err := w.run("hyprctl", "activewindow", "-j")
+	if err != nil {
+		return core.Source{}, err
+	}
+	var win struct {
+		Class string `json:"class"`
+		PID   int    `json:"pid"`
+	}
+	if err := json.Unmarshal(out, &win); err != nil {
+		return core.Source{}, err
+	}
+	src := core.Source{AppID: win.Class, WindowClass: win.Class}
+	if win.PID > 0 {
+		src.Process = processName(strconv.Itoa(win.PID))
+	}
+	return src, nil
+}
+
+func processName(pid string) string {
+	b, err := os.ReadFile("/proc/" + pid + "/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
@@ -0,0 +1,67 @@
+//go:build linux
+
+package clipboard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// fakeOwner plays the X11 selection owner behind xclip.
+type fakeOwner struct {
+	text    string
+	targets []string
+}
+
+func (o fakeOwner) run(name string, args ...string) ([]byte, error) {
+	if name != "xclip" {
+		return nil, errors.New("unexpected command " + name)
+	}
+	if strings.Contains(strings.Join(args, " "), "-t TARGETS") {
+		return []byte(strings.Join(o.targets, "\n") + "\n"), nil
+	}
+	return []byte(o.text), nil
+}
+
+func TestLinuxWatcher_ReadTargetsConcealed(t *testing.T) {
+	owner := fakeOwner{
+		text:    "hunter2",
+		targets: []string{"TARGETS", "TIMESTAMP", "UTF8_STRING", "text/plain", "x-kde-passwordManagerHint"},
+	}
+	w := &LinuxWatcher{run: owner.run}
+
+	txt, err := w.ReadText()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txt != "hunter2" {
+		t.Fatalf("unexpected text %q", txt)
+	}
+
+	targets, err := w.ReadTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != len(owner.targets) {
+		t.Fatalf("expected %d targets, got %v", len(owner.targets), targets)
+	}
+	if !core.IsConcealed(targets) {
+		t.Fatalf("expected targets to be flagged as concealed")
+	}
+}
+
+func TestLinuxWatcher_ReadTargetsPlain(t *testing.T) {
+	owner := fakeOwner{text: "hello", targets: []string{"TARGETS", "UTF8_STRING"}}
+	w := &LinuxWatcher{run: owner.run}
+
+	targets, err := w.ReadTargets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if core.IsConcealed(targets) {
+		t.Fatalf("did not expect plain selection to be concealed")
+	}
+}
@@ -0,0 +1,48 @@
+package clipboard
+
+import (
+	"bufio"
+	"os"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// HintFileSource reads the clipboard source from a small key=value file:
+//
+//	app=org.keepassxc.KeePassXC
+//	class=keepassxc
+//	process=keepassxc
+//
+// It is used by tests and by setups where no window system query works
+// (a compositor hook can write the file on focus change).
+type HintFileSource struct {
+	Path string
+}
+
+func (h HintFileSource) ReadSource() (core.Source, error) {
+	f, err := os.Open(h.Path)
+	if err != nil {
+		return core.Source{}, err
+	}
+	defer f.Close()
+
+	var src core.Source
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "app", "app_id":
+			src.AppID = val
+		case "class", "window_class":
+			src.WindowClass = val
+		case "process":
+			src.Process = val
+		}
+	}
+	return src, sc.Err()
+}
@@ -0,0 +1,25 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestHintFileSource(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "source")
+	if err := os.WriteFile(p, []byte("app=org.keepassxc.KeePassXC\nclass = keepassxc\n# comment\nprocess=keepassxc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := HintFileSource{Path: p}.ReadSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := core.Source{AppID: "org.keepassxc.KeePassXC", WindowClass: "keepassxc", Process: "keepassxc"}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
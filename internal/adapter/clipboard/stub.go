@@ -1,10 +1,11 @@
-//go:build !darwin
+//go:build !darwin && !linux
 
 package clipboard
 
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var ErrUnsupported = errors.New("clipboard watcher not implemented for this OS yet")
@@ -13,6 +14,11 @@ type UnsupportedWatcher struct{}
 
 func NewUnsupportedWatcher() *UnsupportedWatcher { return &UnsupportedWatcher{} }
 
+func NewSystem(interval time.Duration) System {
+	_ = interval
+	return NewUnsupportedWatcher()
+}
+
 func (w *UnsupportedWatcher) Watch(ctx context.Context) (<-chan struct{}, error) {
 	_ = ctx
 	return nil, ErrUnsupported
@@ -21,3 +27,8 @@ func (w *UnsupportedWatcher) Watch(ctx context.Context) (<-chan struct{}, error)
 func (w *UnsupportedWatcher) ReadText() (string, error) {
 	return "", ErrUnsupported
 }
+
+func (w *UnsupportedWatcher) WriteText(text string) error {
+	_ = text
+	return ErrUnsupported
+}
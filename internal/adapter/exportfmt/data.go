@@ -0,0 +1,123 @@
+package exportfmt
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// jsonWriter writes the same document json.Encoder with a two-space
+// indent writes for a slice, an item at a time.
+type jsonWriter struct {
+	w io.Writer
+	n int
+}
+
+func newJSON(w io.Writer, _ Options) Writer { return &jsonWriter{w: w} }
+
+func (j *jsonWriter) Write(it core.Item) error {
+	b, err := json.MarshalIndent(FromItem(it), "  ", "  ")
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if j.n == 0 {
+		prefix = "[\n  "
+	}
+	j.n++
+	if _, err := io.WriteString(j.w, prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type ndjsonWriter struct{ enc *json.Encoder }
+
+func newNDJSON(w io.Writer, _ Options) Writer { return ndjsonWriter{json.NewEncoder(w)} }
+
+func (n ndjsonWriter) Write(it core.Item) error { return n.enc.Encode(FromItem(it)) }
+func (n ndjsonWriter) Close() error             { return nil }
+
+var csvHeader = []string{
+	"id", "type", "content", "fingerprint", "created_at", "last_seen_at", "pinned",
+	"title", "note", "source_app", "source_class", "source_process", "tags",
+}
+
+type csvWriter struct {
+	cw     *csv.Writer
+	header bool
+}
+
+func newCSV(w io.Writer, _ Options) Writer { return &csvWriter{cw: csv.NewWriter(w)} }
+
+func (c *csvWriter) writeHeader() {
+	if !c.header {
+		c.header = true
+		c.cw.Write(csvHeader)
+	}
+}
+
+func (c *csvWriter) Write(it core.Item) error {
+	c.writeHeader()
+	e := FromItem(it)
+	c.cw.Write([]string{
+		e.ID, e.Type, e.Content, e.Fingerprint, e.CreatedAt, e.LastSeenAt, strconv.FormatBool(e.Pinned),
+		e.Title, e.Note, e.Source.AppID, e.Source.WindowClass, e.Source.Process, strings.Join(e.Tags, ","),
+	})
+	// csv.Writer buffers a few KB and reports write errors late
+	return c.cw.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.writeHeader()
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+type textWriter struct {
+	w   io.Writer
+	sep string
+	n   int
+}
+
+func newText(w io.Writer, opts Options) Writer {
+	sep := opts.Separator
+	if sep == "" {
+		sep = DefaultSeparator
+	}
+	return &textWriter{w: w, sep: sep}
+}
+
+func (t *textWriter) Write(it core.Item) error {
+	if t.n > 0 {
+		if _, err := io.WriteString(t.w, t.sep); err != nil {
+			return err
+		}
+	}
+	t.n++
+	_, err := io.WriteString(t.w, it.Content)
+	return err
+}
+
+// Close ends the last line when items are separated by lines.
+func (t *textWriter) Close() error {
+	if t.n == 0 || !strings.HasSuffix(t.sep, "\n") {
+		return nil
+	}
+	_, err := io.WriteString(t.w, "\n")
+	return err
+}
@@ -0,0 +1,158 @@
+package exportfmt
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+const headingLen = 60
+
+// heading names an item in documents: its title, or the start of its
+// first line.
+func heading(it core.Item) string {
+	s := it.Title
+	if s == "" {
+		s, _, _ = strings.Cut(strings.TrimSpace(it.Content), "\n")
+	}
+	if r := []rune(s); len(r) > headingLen {
+		s = string(r[:headingLen-1]) + "…"
+	}
+	return s
+}
+
+// details lists an item's type, pin, dates, source and tags.
+func details(it core.Item) []string {
+	d := []string{string(it.Type)}
+	if it.Pinned {
+		d = append(d, "pinned")
+	}
+	d = append(d, "last seen "+it.LastSeenAt.Format("2006-01-02 15:04"))
+	if !it.Source.IsZero() {
+		d = append(d, "from "+it.Source.String())
+	}
+	if len(it.Tags) > 0 {
+		d = append(d, "tags: "+strings.Join(it.Tags, ", "))
+	}
+	return d
+}
+
+type markdownWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func newMarkdown(w io.Writer, _ Options) Writer { return &markdownWriter{w: w} }
+
+func (m *markdownWriter) start() error {
+	if m.started {
+		return nil
+	}
+	m.started = true
+	_, err := io.WriteString(m.w, "# OtterClip export\n")
+	return err
+}
+
+func (m *markdownWriter) Write(it core.Item) error {
+	if err := m.start(); err != nil {
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n## %s\n\n", heading(it))
+	fmt.Fprintf(&b, "_%s_\n\n", strings.Join(details(it), " · "))
+	if it.Note != "" {
+		for line := range strings.Lines(it.Note) {
+			fmt.Fprintf(&b, "> %s", line)
+		}
+		b.WriteString("\n\n")
+	}
+	fence := codeFence(it.Content)
+	fmt.Fprintf(&b, "%s%s\n%s\n%s\n", fence, it.Meta[core.MetaLanguage], it.Content, fence)
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownWriter) Close() error { return m.start() }
+
+// codeFence returns a backtick fence longer than any run of backticks in s.
+func codeFence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+var htmlTemplates = template.Must(template.New("head").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>OtterClip export</title>
+<style>
+body { font: 15px/1.5 system-ui, sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+article { border-bottom: 1px solid #ddd; padding: 1rem 0; }
+h2 { font-size: 1.05rem; margin: 0; }
+.details { color: #666; font-size: .85rem; }
+blockquote { margin: .5rem 0; padding-left: .75rem; border-left: 3px solid #ccc; color: #555; white-space: pre-wrap; }
+pre { background: #f6f6f6; padding: .75rem; overflow-x: auto; white-space: pre-wrap; word-break: break-word; }
+</style>
+</head>
+<body>
+<h1>OtterClip export</h1>
+`))
+
+func init() {
+	template.Must(htmlTemplates.New("item").Parse(`<article>
+<h2>{{.Heading}}</h2>
+<p class="details">{{.Details}}</p>
+{{- if .Note}}
+<blockquote>{{.Note}}</blockquote>
+{{- end}}
+<pre{{if .Language}} data-language="{{.Language}}"{{end}}>{{.Content}}</pre>
+</article>
+`))
+	template.Must(htmlTemplates.New("foot").Parse("</body>\n</html>\n"))
+}
+
+type htmlWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func newHTML(w io.Writer, _ Options) Writer { return &htmlWriter{w: w} }
+
+func (h *htmlWriter) start() error {
+	if h.started {
+		return nil
+	}
+	h.started = true
+	return htmlTemplates.ExecuteTemplate(h.w, "head", nil)
+}
+
+func (h *htmlWriter) Write(it core.Item) error {
+	if err := h.start(); err != nil {
+		return err
+	}
+	return htmlTemplates.ExecuteTemplate(h.w, "item", map[string]string{
+		"Heading":  heading(it),
+		"Details":  strings.Join(details(it), " · "),
+		"Note":     it.Note,
+		"Language": it.Meta[core.MetaLanguage],
+		"Content":  it.Content,
+	})
+}
+
+func (h *htmlWriter) Close() error {
+	if err := h.start(); err != nil {
+		return err
+	}
+	return htmlTemplates.ExecuteTemplate(h.w, "foot", nil)
+}
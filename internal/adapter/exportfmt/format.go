@@ -0,0 +1,121 @@
+// Package exportfmt writes clipboard history in the formats
+// `otterclipctl export` offers. Writers take one item at a time, so an
+// export streams from the store in constant memory.
+package exportfmt
+
+import (
+	"io"
+	"slices"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// Item is an item of the JSON exports, the form the otterclip import
+// format reads back.
+type Item struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Content     string `json:"content"`
+	Fingerprint string `json:"fingerprint"`
+	CreatedAt   string `json:"created_at"`
+	LastSeenAt  string `json:"last_seen_at"`
+	Pinned      bool   `json:"pinned"`
+	Title       string `json:"title,omitempty"`
+	Note        string `json:"note,omitempty"`
+
+	Source    core.Source       `json:"source,omitzero"`
+	ExpiresAt string            `json:"expires_at,omitempty"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+}
+
+func FromItem(it core.Item) Item {
+	return Item{
+		ID:          it.ID,
+		Type:        string(it.Type),
+		Content:     it.Content,
+		Fingerprint: it.Fingerprint,
+		CreatedAt:   formatTime(it.CreatedAt),
+		LastSeenAt:  formatTime(it.LastSeenAt),
+		Pinned:      it.Pinned,
+		Title:       it.Title,
+		Note:        it.Note,
+		Source:      it.Source,
+		ExpiresAt:   formatTime(it.ExpiresAt),
+		Meta:        it.Meta,
+		Tags:        it.Tags,
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Writer writes items one at a time.
+type Writer interface {
+	Write(it core.Item) error
+	// Close ends the document; it doesn't close the underlying writer.
+	Close() error
+}
+
+type Options struct {
+	// Separator goes between items in the text format.
+	Separator string
+}
+
+// DefaultSeparator separates items in the text format.
+const DefaultSeparator = "\n---\n"
+
+type Format struct {
+	Name        string
+	Ext         string // file extension for default output names
+	Description string
+	New         func(w io.Writer, opts Options) Writer
+}
+
+var registry = []Format{
+	{"json", "json", "one indented JSON array", newJSON},
+	{"ndjson", "ndjson", "one JSON item per line", newNDJSON},
+	{"csv", "csv", "CSV with a header row; tags are comma separated", newCSV},
+	{"markdown", "md", "Markdown, one section per item", newMarkdown},
+	{"html", "html", "a standalone HTML page", newHTML},
+	{"text", "txt", "plain content separated by --separator", newText},
+}
+
+// Register adds a format, replacing a built-in one of the same name.
+func Register(f Format) {
+	for i := range registry {
+		if registry[i].Name == f.Name {
+			registry[i] = f
+			return
+		}
+	}
+	registry = append(registry, f)
+}
+
+// All returns the formats in display order.
+func All() []Format {
+	return slices.Clone(registry)
+}
+
+func Lookup(name string) (Format, bool) {
+	for _, f := range registry {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Names returns the format names in display order.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Name
+	}
+	return names
+}
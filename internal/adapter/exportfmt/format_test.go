@@ -0,0 +1,164 @@
+package exportfmt
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+var at = time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+
+var items = []core.Item{
+	{
+		ID: "id-1", Content: "plain, \"quoted\"\nsecond line", Type: core.ContentTypeText,
+		Fingerprint: "fp1", CreatedAt: at, LastSeenAt: at, Pinned: true,
+		Title: "First", Note: "remember", Source: core.Source{AppID: "firefox"}, Tags: []string{"a", "b"},
+	},
+	{
+		ID: "id-2", Content: "x := \"```\" + `<b>`", Type: core.ContentTypeCode,
+		Fingerprint: "fp2", CreatedAt: at, LastSeenAt: at,
+		Meta: map[string]string{core.MetaLanguage: "go"},
+	},
+}
+
+func export(t *testing.T, name string, opts Options, items ...core.Item) string {
+	t.Helper()
+	f, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("no format %q", name)
+	}
+	var buf bytes.Buffer
+	w := f.New(&buf, opts)
+	for _, it := range items {
+		if err := w.Write(it); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestJSON_MatchesEncoder(t *testing.T) {
+	for _, n := range []int{0, 1, 2} {
+		want := make([]Item, 0, n)
+		for _, it := range items[:n] {
+			want = append(want, FromItem(it))
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(want); err != nil {
+			t.Fatal(err)
+		}
+		if got := export(t, "json", Options{}, items[:n]...); got != buf.String() {
+			t.Fatalf("%d items:\n got %s\nwant %s", n, got, buf.String())
+		}
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	out := export(t, "ndjson", Options{}, items...)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %q", len(lines), out)
+	}
+	var got Item
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != "id-2" || got.Meta[core.MetaLanguage] != "go" || got.ExpiresAt != "" {
+		t.Fatalf("item = %+v", got)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	recs, err := csv.NewReader(strings.NewReader(export(t, "csv", Options{}, items...))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 || !slices.Equal(recs[0], csvHeader) {
+		t.Fatalf("records = %q", recs)
+	}
+	r := recs[1]
+	if r[2] != items[0].Content || r[6] != "true" || r[9] != "firefox" || r[12] != "a,b" {
+		t.Fatalf("row = %q", r)
+	}
+
+	empty := export(t, "csv", Options{})
+	if empty != strings.Join(csvHeader, ",")+"\n" {
+		t.Fatalf("empty export = %q", empty)
+	}
+}
+
+func TestText(t *testing.T) {
+	if got := export(t, "text", Options{}, items...); got != items[0].Content+DefaultSeparator+items[1].Content+"\n" {
+		t.Fatalf("got %q", got)
+	}
+	if got := export(t, "text", Options{Separator: "\x00"}, items...); got != items[0].Content+"\x00"+items[1].Content {
+		t.Fatalf("NUL separated: %q", got)
+	}
+	if got := export(t, "text", Options{}); got != "" {
+		t.Fatalf("empty export = %q", got)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	out := export(t, "markdown", Options{}, items...)
+	for _, want := range []string{
+		"# OtterClip export\n",
+		"\n## First\n",
+		"_text · pinned · last seen 2024-03-04 05:06 · from firefox · tags: a, b_",
+		"> remember\n",
+		"````go\nx := \"```\" + `<b>`\n````\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestHTML_Escapes(t *testing.T) {
+	out := export(t, "html", Options{}, items...)
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<h2>First</h2>",
+		`<pre data-language="go">x := &#34;` + "```" + `&#34; &#43; ` + "`&lt;b&gt;`</pre>",
+		"</html>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<b>") {
+		t.Fatal("content not escaped")
+	}
+	if empty := export(t, "html", Options{}); !strings.HasSuffix(empty, "</html>\n") {
+		t.Fatalf("empty export = %q", empty)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteErrors(t *testing.T) {
+	for _, f := range All() {
+		w := f.New(failWriter{}, Options{})
+		err := w.Write(items[0])
+		if err == nil {
+			err = w.Close()
+		}
+		if err == nil {
+			t.Errorf("%s: write error not reported", f.Name)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package hotkey
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Modifier is a set of modifier keys.
+type Modifier uint8
+
+const (
+	ModCtrl Modifier = 1 << iota
+	ModShift
+	ModAlt
+	ModSuper // Windows / Command key
+)
+
+var modNames = []struct {
+	mod  Modifier
+	name string
+}{
+	{ModCtrl, "Ctrl"},
+	{ModShift, "Shift"},
+	{ModAlt, "Alt"},
+	{ModSuper, "Super"},
+}
+
+var modAliases = map[string]Modifier{
+	"ctrl": ModCtrl, "control": ModCtrl, "ctl": ModCtrl,
+	"shift": ModShift,
+	"alt":   ModAlt, "option": ModAlt, "opt": ModAlt, "mod1": ModAlt,
+	"super": ModSuper, "meta": ModSuper, "win": ModSuper, "cmd": ModSuper,
+	"command": ModSuper, "mod4": ModSuper,
+}
+
+// Named keys, keyed by lower-case alias, mapping to the canonical name.
+var keyAliases = map[string]string{
+	"space": "Space", "enter": "Enter", "return": "Enter", "tab": "Tab",
+	"esc": "Escape", "escape": "Escape", "backspace": "Backspace",
+	"delete": "Delete", "del": "Delete", "insert": "Insert", "ins": "Insert",
+	"home": "Home", "end": "End", "pageup": "PageUp", "pgup": "PageUp",
+	"pagedown": "PageDown", "pgdn": "PageDown",
+	"up": "Up", "down": "Down", "left": "Left", "right": "Right",
+	"comma": ",", "period": ".", "slash": "/", "semicolon": ";",
+	"minus": "-", "equal": "=", "grave": "`", "backquote": "`",
+	"bracketleft": "[", "bracketright": "]", "apostrophe": "'", "backslash": "\\",
+}
+
+const punctuation = ",./;-=`[]'\\"
+
+// Accelerator is a key combination such as Ctrl+Shift+V. Key holds the
+// canonical key name: an upper-case letter, a digit, F1-F24, one of the
+// named keys (Space, Enter, Up, ...) or a punctuation character.
+type Accelerator struct {
+	Mods Modifier
+	Key  string
+}
+
+// ParseAccelerator parses strings like "Ctrl+Shift+V", "super+space" or
+// "Alt+F2". Modifier and key names are case-insensitive and accept the
+// usual aliases (Control, Cmd, Win, Option, Esc, PgUp, ...). At least one
+// modifier is required unless the key is a function key.
+func ParseAccelerator(s string) (Accelerator, error) {
+	var a Accelerator
+	parts := strings.Split(strings.TrimSpace(s), "+")
+	if len(parts) == 0 || strings.TrimSpace(parts[len(parts)-1]) == "" {
+		return a, fmt.Errorf("hotkey %q: missing key", s)
+	}
+
+	for _, p := range parts[:len(parts)-1] {
+		m, ok := modAliases[strings.ToLower(strings.TrimSpace(p))]
+		if !ok {
+			return a, fmt.Errorf("hotkey %q: unknown modifier %q", s, p)
+		}
+		if a.Mods&m != 0 {
+			return a, fmt.Errorf("hotkey %q: repeated modifier %q", s, p)
+		}
+		a.Mods |= m
+	}
+
+	key, ok := canonicalKey(strings.TrimSpace(parts[len(parts)-1]))
+	if !ok {
+		return a, fmt.Errorf("hotkey %q: unknown key %q", s, parts[len(parts)-1])
+	}
+	a.Key = key
+	if a.Mods == 0 && !isFunctionKey(key) {
+		return a, fmt.Errorf("hotkey %q: needs a modifier", s)
+	}
+	return a, nil
+}
+
+// MustParse is like ParseAccelerator but panics on error; for defaults.
+func MustParse(s string) Accelerator {
+	a, err := ParseAccelerator(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func canonicalKey(k string) (string, bool) {
+	if len(k) == 1 {
+		c := k[0]
+		switch {
+		case c >= 'a' && c <= 'z':
+			return string(c - 'a' + 'A'), true
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return k, true
+		case strings.IndexByte(punctuation, c) >= 0:
+			return k, true
+		}
+		return "", false
+	}
+	lk := strings.ToLower(k)
+	if name, ok := keyAliases[lk]; ok {
+		return name, true
+	}
+	if n, ok := functionKey(lk); ok {
+		return "F" + strconv.Itoa(n), true
+	}
+	return "", false
+}
+
+// functionKey parses "f1" to "f24".
+func functionKey(lk string) (int, bool) {
+	if len(lk) < 2 || lk[0] != 'f' {
+		return 0, false
+	}
+	n, err := strconv.Atoi(lk[1:])
+	if err != nil || n < 1 || n > 24 {
+		return 0, false
+	}
+	return n, true
+}
+
+func isFunctionKey(key string) bool {
+	_, ok := functionKey(strings.ToLower(key))
+	return ok
+}
+
+// String returns the canonical form, e.g. "Ctrl+Shift+V".
+func (a Accelerator) String() string {
+	var b strings.Builder
+	for _, m := range modNames {
+		if a.Mods&m.mod != 0 {
+			b.WriteString(m.name)
+			b.WriteByte('+')
+		}
+	}
+	b.WriteString(a.Key)
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package hotkey
+
+import "testing"
+
+func TestParseAccelerator(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"Ctrl+Shift+V", "Ctrl+Shift+V"},
+		{"shift+ctrl+v", "Ctrl+Shift+V"},
+		{"Control + Alt + Delete", "Ctrl+Alt+Delete"},
+		{"cmd+space", "Super+Space"},
+		{"Win+Option+pgup", "Alt+Super+PageUp"},
+		{"F12", "F12"},
+		{"super+f2", "Super+F2"},
+		{"Ctrl+-", "Ctrl+-"},
+		{"ctrl+Comma", "Ctrl+,"},
+		{"Ctrl+3", "Ctrl+3"},
+	}
+	for _, c := range cases {
+		a, err := ParseAccelerator(c.in)
+		if err != nil {
+			t.Errorf("ParseAccelerator(%q): %v", c.in, err)
+			continue
+		}
+		if got := a.String(); got != c.want {
+			t.Errorf("ParseAccelerator(%q) = %s, want %s", c.in, got, c.want)
+		}
+		if again, err := ParseAccelerator(a.String()); err != nil || again != a {
+			t.Errorf("%s does not round-trip: %+v, %v", a, again, err)
+		}
+	}
+}
+
+func TestParseAccelerator_Errors(t *testing.T) {
+	for _, in := range []string{"", "Ctrl+", "V", "Hyper+V", "Ctrl+Ctrl+V", "Ctrl+F25", "Ctrl+Banana", "Ctrl+é"} {
+		if a, err := ParseAccelerator(in); err == nil {
+			t.Errorf("ParseAccelerator(%q) = %s, want error", in, a)
+		}
+	}
+}
+
+func TestKeysym(t *testing.T) {
+	cases := map[string]uint32{
+		"V": 'v', "7": '7', "Space": 0x20, "F1": 0xffbe, "F12": 0xffc9,
+		"Enter": 0xff0d, "/": '/', "Down": 0xff54,
+	}
+	for key, want := range cases {
+		if got, ok := Keysym(key); !ok || got != want {
+			t.Errorf("Keysym(%s) = %#x, %v; want %#x", key, got, ok, want)
+		}
+	}
+	if _, ok := Keysym("F0"); ok {
+		t.Error("expected F0 to have no keysym")
+	}
+}
@@ -0,0 +1,139 @@
+// Package hotkey registers global shortcuts. Backends grab keys from the
+// window system (X11) or receive actions from outside (the command socket
+// used on Wayland, where compositors own global shortcuts); Manager binds
+// named actions to handlers across all of them.
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrUnsupported is returned by Native when the platform or session has no
+// key-grabbing backend.
+var ErrUnsupported = errors.New("hotkey: global shortcuts not supported here")
+
+// Backend delivers presses of registered shortcuts as action names.
+type Backend interface {
+	// Register binds action to accel. Backends that can't grab keys
+	// (the command socket) accept any accelerator.
+	Register(action string, accel Accelerator) error
+	Unregister(action string) error
+	// Events reports triggered actions. It is closed by Close.
+	Events() <-chan string
+	Close() error
+}
+
+// Manager dispatches actions from several backends to handlers.
+type Manager struct {
+	backends []Backend
+
+	mu       sync.Mutex
+	handlers map[string]func()
+	bindings map[string]Accelerator
+}
+
+func NewManager(backends ...Backend) *Manager {
+	return &Manager{
+		backends: backends,
+		handlers: make(map[string]func()),
+		bindings: make(map[string]Accelerator),
+	}
+}
+
+// Bind registers accel for action on every backend and calls fn when it
+// fires. Rebinding an action replaces its accelerator and handler. If any
+// backend refuses (the keys are taken by another application), the
+// previous binding is restored everywhere and the error returned.
+func (m *Manager) Bind(action string, accel Accelerator, fn func()) error {
+	m.mu.Lock()
+	prev, hadPrev := m.bindings[action]
+	m.mu.Unlock()
+
+	var errs []error
+	for _, b := range m.backends {
+		b.Unregister(action)
+		if err := b.Register(action, accel); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		for _, b := range m.backends {
+			b.Unregister(action)
+			if hadPrev {
+				b.Register(action, prev)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	m.mu.Lock()
+	m.handlers[action] = fn
+	m.bindings[action] = accel
+	m.mu.Unlock()
+	return nil
+}
+
+// Unbind removes an action from every backend.
+func (m *Manager) Unbind(action string) {
+	for _, b := range m.backends {
+		b.Unregister(action)
+	}
+	m.mu.Lock()
+	delete(m.handlers, action)
+	delete(m.bindings, action)
+	m.mu.Unlock()
+}
+
+// Run calls handlers as actions fire until ctx is done or every backend
+// has closed its events.
+func (m *Manager) Run(ctx context.Context) {
+	merged := make(chan string)
+	var wg sync.WaitGroup
+	for _, b := range m.backends {
+		wg.Add(1)
+		go func(events <-chan string) {
+			defer wg.Done()
+			for action := range events {
+				select {
+				case merged <- action:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(b.Events())
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case action, ok := <-merged:
+			if !ok {
+				return
+			}
+			m.mu.Lock()
+			fn := m.handlers[action]
+			m.mu.Unlock()
+			if fn != nil {
+				fn()
+			}
+		}
+	}
+}
+
+// Close closes every backend.
+func (m *Manager) Close() error {
+	var errs []error
+	for _, b := range m.backends {
+		if err := b.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,122 @@
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeBackend struct {
+	bound  map[string]Accelerator
+	events chan string
+	fail   bool
+	refuse string
+	closed bool
+}
+
+func newFake() *fakeBackend {
+	return &fakeBackend{bound: make(map[string]Accelerator), events: make(chan string)}
+}
+
+func (f *fakeBackend) Register(action string, accel Accelerator) error {
+	if f.fail || accel.String() == f.refuse {
+		return errors.New("taken")
+	}
+	f.bound[action] = accel
+	return nil
+}
+
+func (f *fakeBackend) Unregister(action string) error {
+	delete(f.bound, action)
+	return nil
+}
+
+func (f *fakeBackend) Events() <-chan string { return f.events }
+
+func (f *fakeBackend) Close() error {
+	if !f.closed {
+		f.closed = true
+		close(f.events)
+	}
+	return nil
+}
+
+func TestManager_DispatchesFromAllBackends(t *testing.T) {
+	x, sock := newFake(), newFake()
+	m := NewManager(x, sock)
+
+	fired := make(chan string, 4)
+	if err := m.Bind("toggle", MustParse("Ctrl+Shift+V"), func() { fired <- "toggle" }); err != nil {
+		t.Fatal(err)
+	}
+	if x.bound["toggle"].String() != "Ctrl+Shift+V" || sock.bound["toggle"].Key != "V" {
+		t.Fatalf("expected both backends bound, got %+v %+v", x.bound, sock.bound)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		m.Run(ctx)
+		close(done)
+	}()
+
+	x.events <- "toggle"
+	sock.events <- "toggle"
+	sock.events <- "unbound-action"
+	for i := 0; i < 2; i++ {
+		select {
+		case <-fired:
+		case <-time.After(time.Second):
+			t.Fatalf("handler fired %d times, want 2", i)
+		}
+	}
+
+	m.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after Close")
+	}
+}
+
+func TestManager_Bind(t *testing.T) {
+	x, sock := newFake(), newFake()
+	m := NewManager(x, sock)
+
+	if err := m.Bind("toggle", MustParse("Super+V"), func() {}); err != nil {
+		t.Fatal(err)
+	}
+	// rebinding replaces the accelerator
+	if err := m.Bind("toggle", MustParse("Alt+F2"), func() {}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sock.bound["toggle"].String(); got != "Alt+F2" {
+		t.Fatalf("expected rebind, got %s", got)
+	}
+
+	// keys taken on one backend fail the bind and keep the old accelerator
+	x.refuse = "Ctrl+Shift+X"
+	if err := m.Bind("toggle", MustParse("Ctrl+Shift+X"), func() {}); err == nil {
+		t.Fatal("expected the rebind to fail")
+	}
+	for _, b := range []*fakeBackend{x, sock} {
+		if got := b.bound["toggle"].String(); got != "Alt+F2" {
+			t.Fatalf("expected Alt+F2 restored, got %q", got)
+		}
+	}
+
+	x.fail = true
+	if err := m.Bind("other", MustParse("Ctrl+O"), func() {}); err == nil {
+		t.Fatal("expected an error when a backend refuses")
+	}
+	if _, ok := sock.bound["other"]; ok {
+		t.Fatal("expected a failed first bind to leave nothing registered")
+	}
+
+	m.Unbind("toggle")
+	if _, ok := sock.bound["toggle"]; ok {
+		t.Fatal("expected unbind to reach backends")
+	}
+}
@@ -0,0 +1,30 @@
+package hotkey
+
+// X11 keysyms for canonical key names (see X11/keysymdef.h). Letters map
+// to their lower-case keysym, which is what the unshifted key produces.
+var namedKeysyms = map[string]uint32{
+	"Space": 0x0020, "Enter": 0xff0d, "Tab": 0xff09, "Escape": 0xff1b,
+	"Backspace": 0xff08, "Delete": 0xffff, "Insert": 0xff63,
+	"Home": 0xff50, "End": 0xff57, "PageUp": 0xff55, "PageDown": 0xff56,
+	"Left": 0xff51, "Up": 0xff52, "Right": 0xff53, "Down": 0xff54,
+}
+
+// Keysym returns the X11 keysym of a canonical key name.
+func Keysym(key string) (uint32, bool) {
+	if ks, ok := namedKeysyms[key]; ok {
+		return ks, true
+	}
+	if len(key) > 1 && key[0] == 'F' {
+		if n, ok := functionKey("f" + key[1:]); ok {
+			return 0xffbe + uint32(n-1), true // XK_F1..XK_F24 are contiguous
+		}
+	}
+	if len(key) == 1 {
+		c := key[0]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		return uint32(c), true // Latin-1 keysyms equal their code points
+	}
+	return 0, false
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+package hotkey
+
+import "os"
+
+// Native returns the key-grabbing backend for this session: X11 when
+// running under an X server. Wayland compositors don't let clients grab
+// keys, so there (and when X11 is unreachable) it returns ErrUnsupported
+// and shortcuts go through the command socket instead.
+func Native() (Backend, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" || os.Getenv("DISPLAY") == "" {
+		return nil, ErrUnsupported
+	}
+	return NewX11()
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package hotkey
+
+// Native returns ErrUnsupported: only X11 grabs are implemented, so other
+// platforms rely on the command socket.
+func Native() (Backend, error) { return nil, ErrUnsupported }
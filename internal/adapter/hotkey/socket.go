@@ -0,0 +1,157 @@
+package hotkey
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotRunning is returned by Send when nothing listens on the socket.
+var ErrNotRunning = errors.New("otterclip is not running")
+
+// SocketPath is where the running app listens for actions:
+// $XDG_RUNTIME_DIR/otterclip.sock, or a per-user file in the temp dir.
+func SocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "otterclip.sock")
+	}
+	return filepath.Join(os.TempDir(), "otterclip-"+strconv.Itoa(os.Getuid())+".sock")
+}
+
+// SocketBackend triggers actions sent by other processes, so a shortcut
+// bound in the compositor (`otterclip toggle`) reaches the app. It accepts
+// any accelerator: the key combination lives in the compositor's config.
+type SocketBackend struct {
+	ln     net.Listener
+	events chan string
+
+	mu      sync.Mutex
+	actions map[string]bool
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// ListenSocket listens on path, replacing a stale socket left by a crashed
+// instance. It fails if another instance is still listening.
+func ListenSocket(path string) (*SocketBackend, error) {
+	if err := Send(context.Background(), path, ""); err == nil {
+		return nil, fmt.Errorf("hotkey: %s is in use by another instance", path)
+	}
+	os.Remove(path)
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	s := &SocketBackend{ln: ln, events: make(chan string, 8), actions: make(map[string]bool)}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+func (s *SocketBackend) Register(action string, _ Accelerator) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions[action] = true
+	return nil
+}
+
+func (s *SocketBackend) Unregister(action string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.actions, action)
+	return nil
+}
+
+func (s *SocketBackend) Events() <-chan string { return s.events }
+
+func (s *SocketBackend) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	s.wg.Wait()
+	close(s.events)
+	return err
+}
+
+func (s *SocketBackend) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		s.handle(conn)
+	}
+}
+
+// handle answers one request: a single line naming the action. An empty
+// line is a liveness probe.
+func (s *SocketBackend) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	action := strings.TrimSpace(line)
+	if action == "" {
+		fmt.Fprintln(conn, "ok")
+		return
+	}
+
+	s.mu.Lock()
+	known := s.actions[action]
+	s.mu.Unlock()
+	if !known {
+		fmt.Fprintf(conn, "unknown action %q\n", action)
+		return
+	}
+	select {
+	case s.events <- action:
+		fmt.Fprintln(conn, "ok")
+	default:
+		fmt.Fprintln(conn, "busy")
+	}
+}
+
+// Send asks the app listening on path to run action.
+func Send(ctx context.Context, path, action string) error {
+	var d net.Dialer
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return ErrNotRunning
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if _, err := fmt.Fprintln(conn, action); err != nil {
+		return err
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if reply = strings.TrimSpace(reply); reply != "ok" {
+		return errors.New(reply)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package hotkey
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSocketBackend(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "otterclip.sock")
+
+	if err := Send(ctx, path, "toggle"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning before listening, got %v", err)
+	}
+
+	s, err := ListenSocket(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, err := ListenSocket(path); err == nil {
+		t.Fatal("expected a second instance to be refused")
+	}
+
+	if err := Send(ctx, path, "toggle"); err == nil {
+		t.Fatal("expected an unregistered action to be rejected")
+	}
+	s.Register("toggle", Accelerator{})
+	if err := Send(ctx, path, "toggle"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-s.Events():
+		if got != "toggle" {
+			t.Fatalf("got action %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event delivered")
+	}
+
+	s.Close()
+	if _, ok := <-s.Events(); ok {
+		t.Fatal("expected events closed")
+	}
+	if err := Send(ctx, path, "toggle"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("expected ErrNotRunning after close, got %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+//go:build linux
+
+package hotkey
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// Lock modifiers that must not stop a shortcut from firing: the grab is
+// repeated for every combination of them.
+var lockMasks = []uint16{0, xproto.ModMaskLock, xproto.ModMask2, xproto.ModMaskLock | xproto.ModMask2}
+
+const relevantMods = xproto.ModMaskShift | xproto.ModMaskControl | xproto.ModMask1 | xproto.ModMask4
+
+type grab struct {
+	code xproto.Keycode
+	mods uint16
+}
+
+// X11Backend grabs keys on the root window with XGrabKey.
+type X11Backend struct {
+	conn   *xgb.Conn
+	root   xproto.Window
+	events chan string
+
+	// keysym -> keycode, from the server's keyboard mapping
+	codes map[xproto.Keysym]xproto.Keycode
+
+	mu      sync.Mutex
+	grabs   map[string]grab // by action
+	closed  bool
+	stopped chan struct{}
+}
+
+// NewX11 connects to $DISPLAY.
+func NewX11() (*X11Backend, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("hotkey: connect to X11: %w", err)
+	}
+	setup := xproto.Setup(conn)
+	x := &X11Backend{
+		conn:    conn,
+		root:    setup.DefaultScreen(conn).Root,
+		events:  make(chan string, 8),
+		grabs:   make(map[string]grab),
+		stopped: make(chan struct{}),
+	}
+
+	codes, err := KeyboardMap(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	x.codes = codes
+
+	go x.loop()
+	return x, nil
+}
+
+// KeyboardMap maps keysyms to the first keycode producing them.
+func KeyboardMap(conn *xgb.Conn) (map[xproto.Keysym]xproto.Keycode, error) {
+	setup := xproto.Setup(conn)
+	count := byte(setup.MaxKeycode - setup.MinKeycode + 1)
+	km, err := xproto.GetKeyboardMapping(conn, setup.MinKeycode, count).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("hotkey: keyboard mapping: %w", err)
+	}
+	codes := make(map[xproto.Keysym]xproto.Keycode)
+	per := int(km.KeysymsPerKeycode)
+	for i := 0; i < int(count); i++ {
+		for j := 0; j < per; j++ {
+			ks := km.Keysyms[i*per+j]
+			if _, seen := codes[ks]; ks != 0 && !seen {
+				codes[ks] = setup.MinKeycode + xproto.Keycode(i)
+			}
+		}
+	}
+	return codes, nil
+}
+
+func x11Mods(m Modifier) uint16 {
+	var out uint16
+	if m&ModCtrl != 0 {
+		out |= xproto.ModMaskControl
+	}
+	if m&ModShift != 0 {
+		out |= xproto.ModMaskShift
+	}
+	if m&ModAlt != 0 {
+		out |= xproto.ModMask1
+	}
+	if m&ModSuper != 0 {
+		out |= xproto.ModMask4
+	}
+	return out
+}
+
+func (x *X11Backend) Register(action string, accel Accelerator) error {
+	ks, ok := Keysym(accel.Key)
+	if !ok {
+		return fmt.Errorf("hotkey: no X11 keysym for %s", accel.Key)
+	}
+	code, ok := x.codes[xproto.Keysym(ks)]
+	if !ok {
+		return fmt.Errorf("hotkey: %s is not on this keyboard", accel.Key)
+	}
+	g := grab{code: code, mods: x11Mods(accel.Mods)}
+
+	for _, lock := range lockMasks {
+		err := xproto.GrabKeyChecked(x.conn, true, x.root, g.mods|lock, g.code,
+			xproto.GrabModeAsync, xproto.GrabModeAsync).Check()
+		if err != nil {
+			x.ungrab(g)
+			return fmt.Errorf("hotkey: %s is taken by another application", accel)
+		}
+	}
+
+	x.mu.Lock()
+	x.grabs[action] = g
+	x.mu.Unlock()
+	return nil
+}
+
+func (x *X11Backend) Unregister(action string) error {
+	x.mu.Lock()
+	g, ok := x.grabs[action]
+	delete(x.grabs, action)
+	x.mu.Unlock()
+	if ok {
+		x.ungrab(g)
+	}
+	return nil
+}
+
+func (x *X11Backend) ungrab(g grab) {
+	for _, lock := range lockMasks {
+		xproto.UngrabKeyChecked(x.conn, g.code, x.root, g.mods|lock).Check()
+	}
+}
+
+func (x *X11Backend) Events() <-chan string { return x.events }
+
+func (x *X11Backend) Close() error {
+	x.mu.Lock()
+	if x.closed {
+		x.mu.Unlock()
+		return nil
+	}
+	x.closed = true
+	x.mu.Unlock()
+
+	x.conn.Close()
+	<-x.stopped
+	close(x.events)
+	return nil
+}
+
+func (x *X11Backend) loop() {
+	defer close(x.stopped)
+	for {
+		ev, err := x.conn.WaitForEvent()
+		if ev == nil && err == nil {
+			return // connection closed
+		}
+		press, ok := ev.(xproto.KeyPressEvent)
+		if !ok {
+			continue
+		}
+		g := grab{code: press.Detail, mods: press.State & relevantMods}
+
+		x.mu.Lock()
+		var action string
+		for a, have := range x.grabs {
+			if have == g {
+				action = a
+				break
+			}
+		}
+		x.mu.Unlock()
+		if action == "" {
+			continue
+		}
+		select {
+		case x.events <- action:
+		default: // a burst of presses; drop the extras
+		}
+	}
+}
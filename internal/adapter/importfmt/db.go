@@ -0,0 +1,137 @@
+package importfmt
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+
+	_ "modernc.org/sqlite"
+)
+
+// openCopy copies r to a temporary file and opens it read-only, so a
+// database can come from stdin and the original is never touched. The
+// returned func closes the database and removes the copy.
+func openCopy(r io.Reader) (*sql.DB, func(), error) {
+	f, err := os.CreateTemp("", "otterclip-import-*.db")
+	if err != nil {
+		return nil, nil, err
+	}
+	path := f.Name()
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		os.Remove(path)
+		return nil, nil, err
+	}
+	return db, func() { db.Close(); os.Remove(path) }, nil
+}
+
+// coreDataEpoch is where Core Data timestamps count from.
+var coreDataEpoch = time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func coreDataTime(sec float64) time.Time {
+	if sec == 0 {
+		return time.Time{}
+	}
+	return coreDataEpoch.Add(time.Duration(sec * float64(time.Second)))
+}
+
+// readMaccy reads Maccy's Core Data store. Only the plain-text
+// representation of each item is imported.
+func readMaccy(r io.Reader, yield func(Record) error) error {
+	db, done, err := openCopy(r)
+	if err != nil {
+		return fmt.Errorf("maccy: %w", err)
+	}
+	defer done()
+
+	rows, err := db.Query(`
+		SELECT COALESCE(i.ZFIRSTCOPIEDAT, 0), COALESCE(i.ZLASTCOPIEDAT, 0),
+		       COALESCE(i.ZPIN, ''), COALESCE(i.ZAPPLICATION, ''), c.ZVALUE
+		FROM ZHISTORYITEM i
+		LEFT JOIN ZHISTORYITEMCONTENT c
+		       ON c.ZITEM = i.Z_PK AND c.ZTYPE = 'public.utf8-plain-text'
+		ORDER BY i.ZLASTCOPIEDAT DESC`)
+	if err != nil {
+		return fmt.Errorf("maccy: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			first, last float64
+			pin, app    string
+			value       []byte
+		)
+		if err := rows.Scan(&first, &last, &pin, &app, &value); err != nil {
+			return fmt.Errorf("maccy: %w", err)
+		}
+		rec := Record{
+			Content:    string(value),
+			CreatedAt:  coreDataTime(first),
+			LastSeenAt: coreDataTime(last),
+			Pinned:     pin != "",
+			Source:     core.Source{AppID: app},
+		}
+		if value == nil {
+			rec = Record{Skip: "no text (image or file)"}
+		}
+		if err := yield(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// readDitto reads Ditto's database. Groups are folders, not clips;
+// "never auto delete" clips are imported as pinned.
+func readDitto(r io.Reader, yield func(Record) error) error {
+	db, done, err := openCopy(r)
+	if err != nil {
+		return fmt.Errorf("ditto: %w", err)
+	}
+	defer done()
+
+	rows, err := db.Query(`
+		SELECT COALESCE(lDate, 0), COALESCE(mText, ''), COALESCE(lDontAutoDelete, 0)
+		FROM Main
+		WHERE COALESCE(bIsGroup, 0) = 0
+		ORDER BY lDate DESC`)
+	if err != nil {
+		return fmt.Errorf("ditto: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			date, keep int64
+			text       string
+		)
+		if err := rows.Scan(&date, &text, &keep); err != nil {
+			return fmt.Errorf("ditto: %w", err)
+		}
+		rec := Record{Content: text, Pinned: keep != 0}
+		if date != 0 {
+			rec.CreatedAt = time.Unix(date, 0).UTC()
+			rec.LastSeenAt = rec.CreatedAt
+		}
+		if text == "" {
+			rec = Record{Skip: "no text (image or file)"}
+		}
+		if err := yield(rec); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
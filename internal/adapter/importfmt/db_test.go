@@ -0,0 +1,86 @@
+package importfmt
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fixture creates a database with stmts and returns its bytes.
+func fixture(t *testing.T, stmts ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range stmts {
+		if _, err := db.Exec(s); err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestReadMaccy(t *testing.T) {
+	input := fixture(t,
+		`CREATE TABLE ZHISTORYITEM (Z_PK INTEGER PRIMARY KEY, ZFIRSTCOPIEDAT TIMESTAMP, ZLASTCOPIEDAT TIMESTAMP,
+			ZPIN VARCHAR, ZTITLE VARCHAR, ZAPPLICATION VARCHAR)`,
+		`CREATE TABLE ZHISTORYITEMCONTENT (Z_PK INTEGER PRIMARY KEY, ZITEM INTEGER, ZTYPE VARCHAR, ZVALUE BLOB)`,
+		`INSERT INTO ZHISTORYITEM VALUES (1, 100, 200, NULL, 'old', 'com.apple.Safari')`,
+		`INSERT INTO ZHISTORYITEM VALUES (2, 300, 400, 'b', 'new', 'com.apple.Terminal')`,
+		`INSERT INTO ZHISTORYITEM VALUES (3, 500, 600, NULL, '', NULL)`,
+		`INSERT INTO ZHISTORYITEMCONTENT VALUES (1, 1, 'public.utf8-plain-text', CAST('old' AS BLOB))`,
+		`INSERT INTO ZHISTORYITEMCONTENT VALUES (2, 1, 'public.html', CAST('<b>old</b>' AS BLOB))`,
+		`INSERT INTO ZHISTORYITEMCONTENT VALUES (3, 2, 'public.utf8-plain-text', CAST('new' AS BLOB))`,
+		`INSERT INTO ZHISTORYITEMCONTENT VALUES (4, 3, 'public.png', x'89504e47')`,
+	)
+	recs := readAll(t, "maccy", input)
+	got := contents(recs)
+	want := []string{"skip: no text (image or file)", "new", "old"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	r := recs[1]
+	if !r.Pinned || r.Source.AppID != "com.apple.Terminal" {
+		t.Fatalf("record = %+v", r)
+	}
+	if want := time.Date(2001, 1, 1, 0, 6, 40, 0, time.UTC); !r.LastSeenAt.Equal(want) {
+		t.Fatalf("LastSeenAt = %v, want %v", r.LastSeenAt, want)
+	}
+	if recs[2].Pinned {
+		t.Fatal("unpinned item imported as pinned")
+	}
+}
+
+func TestReadDitto(t *testing.T) {
+	input := fixture(t,
+		`CREATE TABLE Main (lID INTEGER PRIMARY KEY, lDate INTEGER, mText TEXT, lDontAutoDelete INTEGER, bIsGroup INTEGER)`,
+		`INSERT INTO Main VALUES (1, 1700000000, 'first', 0, 0)`,
+		`INSERT INTO Main VALUES (2, 1700000100, 'Folder', 0, 1)`,
+		`INSERT INTO Main VALUES (3, 1700000200, 'kept', 1700000200, 0)`,
+		`INSERT INTO Main VALUES (4, 1700000300, '', 0, 0)`,
+	)
+	recs := readAll(t, "ditto", input)
+	got := contents(recs)
+	want := []string{"skip: no text (image or file)", "kept", "first"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if !recs[1].Pinned || recs[2].Pinned {
+		t.Fatalf("pins = %v, %v", recs[1].Pinned, recs[2].Pinned)
+	}
+	if !recs[2].CreatedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("CreatedAt = %v", recs[2].CreatedAt)
+	}
+}
@@ -0,0 +1,89 @@
+// Package importfmt reads the history of other clipboard managers. Each
+// Format turns one manager's export or database into Records; merging
+// them into the store is up to the importer use case.
+package importfmt
+
+import (
+	"io"
+	"slices"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// Record is one clip read from another clipboard manager. Zero times mean
+// the source doesn't keep them.
+type Record struct {
+	Content string
+	// ID, Type and Meta are set when the source is OtterClip, and its
+	// content is then kept as stored; otherwise the importer normalizes
+	// the content, assigns an ID and detects the type.
+	ID         string
+	Type       string
+	Meta       map[string]string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	Pinned     bool
+	Title      string
+	Note       string
+	Source     core.Source
+	ExpiresAt  time.Time
+	Tags       []string
+	// Skip is why the entry can't be imported (an image, a password),
+	// reported rather than silently dropped.
+	Skip string
+}
+
+// Format reads one clipboard manager's history. Formats without
+// timestamps yield records newest first, so the importer can keep their
+// order.
+type Format struct {
+	Name        string
+	Description string
+	// Read calls yield for each record in r, stopping at the first error.
+	Read func(r io.Reader, yield func(Record) error) error
+}
+
+var registry = []Format{
+	{"otterclip", "OtterClip JSON export (array, collection or one item per line)", readOtterClip},
+	{"copyq", "CopyQ items as JSON lines: copyq eval \"$(otterclipctl import copyq-script)\"", readCopyQ},
+	{"cliphist", "cliphist items separated by NUL: cliphist list | while IFS= read -r l; do echo \"$l\" | cliphist decode; printf '\\0'; done", readCliphist},
+	{"clipman", "Clipman's clipman.json (~/.local/share/clipman.json)", readClipman},
+	{"gpaste", "GPaste history.xml (~/.local/share/gpaste/history.xml)", readGPaste},
+	{"maccy", "Maccy's Storage.sqlite database", readMaccy},
+	{"ditto", "Ditto's Ditto.db database", readDitto},
+}
+
+// Register adds a format, replacing a built-in one of the same name.
+func Register(f Format) {
+	for i := range registry {
+		if registry[i].Name == f.Name {
+			registry[i] = f
+			return
+		}
+	}
+	registry = append(registry, f)
+}
+
+// All returns the formats in display order.
+func All() []Format {
+	return slices.Clone(registry)
+}
+
+func Lookup(name string) (Format, bool) {
+	for _, f := range registry {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Format{}, false
+}
+
+// Names returns the format names in display order.
+func Names() []string {
+	names := make([]string, len(registry))
+	for i, f := range registry {
+		names[i] = f.Name
+	}
+	return names
+}
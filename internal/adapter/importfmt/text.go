@@ -0,0 +1,273 @@
+package importfmt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// otterclipItem is an item of `otterclipctl export` (exportfmt.Item).
+type otterclipItem struct {
+	ID         string            `json:"id"`
+	Type       string            `json:"type"`
+	Content    string            `json:"content"`
+	CreatedAt  time.Time         `json:"created_at"`
+	LastSeenAt time.Time         `json:"last_seen_at"`
+	Pinned     bool              `json:"pinned"`
+	Title      string            `json:"title"`
+	Note       string            `json:"note"`
+	Source     core.Source       `json:"source"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Meta       map[string]string `json:"meta"`
+	Tags       []string          `json:"tags"`
+}
+
+func (it otterclipItem) record() Record {
+	return Record{
+		ID:         it.ID,
+		Content:    it.Content,
+		Type:       it.Type,
+		CreatedAt:  it.CreatedAt,
+		LastSeenAt: it.LastSeenAt,
+		Pinned:     it.Pinned,
+		Title:      it.Title,
+		Note:       it.Note,
+		Source:     it.Source,
+		ExpiresAt:  it.ExpiresAt,
+		Meta:       it.Meta,
+		Tags:       it.Tags,
+	}
+}
+
+// readOtterClip accepts a JSON array of items, a collection export
+// ({"name", "items"}) or one item per line.
+func readOtterClip(r io.Reader, yield func(Record) error) error {
+	br := bufio.NewReader(r)
+	first, err := peekNonSpace(br)
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(br)
+
+	if first == '[' {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			var it otterclipItem
+			if err := dec.Decode(&it); err != nil {
+				return err
+			}
+			if err := yield(it.record()); err != nil {
+				return err
+			}
+		}
+		_, err := dec.Token()
+		return err
+	}
+
+	for {
+		var v struct {
+			otterclipItem
+			Items []otterclipItem `json:"items"`
+		}
+		if err := dec.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		items := v.Items
+		if items == nil {
+			items = []otterclipItem{v.otterclipItem}
+		}
+		for _, it := range items {
+			if err := yield(it.record()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b)) {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+// CopyQScript prints CopyQ's items, newest first, in the form the copyq
+// format reads:
+//
+//	copyq eval "$CopyQScript" > copyq.jsonl
+const CopyQScript = `for (var i = 0; i < size(); ++i) {
+  var d = getItem(i);
+  print(JSON.stringify({
+    text: d["text/plain"] === undefined ? null : str(d["text/plain"]),
+    notes: str(d["application/x-copyq-item-notes"] || ""),
+    tags: str(d["application/x-copyq-tags"] || ""),
+    pinned: d["application/x-copyq-item-pinned"] !== undefined
+  }) + "\n");
+}`
+
+type copyqItem struct {
+	Text   *string `json:"text"`
+	Notes  string  `json:"notes"`
+	Tags   string  `json:"tags"`
+	Pinned bool    `json:"pinned"`
+}
+
+func readCopyQ(r io.Reader, yield func(Record) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var it copyqItem
+		if err := dec.Decode(&it); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		rec := Record{Note: it.Notes, Pinned: it.Pinned}
+		if it.Text == nil {
+			rec.Skip = "no text (image or other format)"
+		} else {
+			rec.Content = *it.Text
+		}
+		// CopyQ keeps tags one per line (or comma separated when typed)
+		for _, tag := range strings.FieldsFunc(it.Tags, func(r rune) bool { return r == '\n' || r == ',' }) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				rec.Tags = append(rec.Tags, tag)
+			}
+		}
+		if err := yield(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// maxClip bounds a single NUL-separated clip.
+const maxClip = 64 << 20
+
+// readCliphist reads decoded cliphist items separated by NUL bytes, in
+// `cliphist list` order (newest first).
+func readCliphist(r io.Reader, yield func(Record) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), maxClip)
+	sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, 0); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+	for sc.Scan() {
+		rec := Record{Content: sc.Text()}
+		if !utf8.ValidString(rec.Content) {
+			rec = Record{Skip: "binary data (image?)"}
+		}
+		if err := yield(rec); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// readClipman reads Clipman's history: a JSON array of strings, oldest
+// first.
+func readClipman(r io.Reader, yield func(Record) error) error {
+	var history []string
+	if err := json.NewDecoder(r).Decode(&history); err != nil {
+		return fmt.Errorf("clipman: %w", err)
+	}
+	for _, s := range slices.Backward(history) {
+		if err := yield(Record{Content: s}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type gpasteItem struct {
+	Kind  string `xml:"kind,attr"`
+	Date  string `xml:"date,attr"`
+	Value string `xml:"value"`
+	// GPaste 2 kept the text directly in the item
+	Text string `xml:",chardata"`
+}
+
+// readGPaste reads GPaste's history.xml, newest first.
+func readGPaste(r io.Reader, yield func(Record) error) error {
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("gpaste: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "item" {
+			continue
+		}
+		var it gpasteItem
+		if err := dec.DecodeElement(&it, &start); err != nil {
+			return fmt.Errorf("gpaste: %w", err)
+		}
+
+		rec := Record{Content: it.Value}
+		if rec.Content == "" {
+			rec.Content = strings.TrimSpace(it.Text)
+		}
+		rec.LastSeenAt = parseGPasteDate(it.Date)
+		rec.CreatedAt = rec.LastSeenAt
+		switch it.Kind {
+		case "", "Text", "Uris":
+		case "Password":
+			rec = Record{Skip: "password"}
+		case "Image":
+			rec = Record{Skip: "image"}
+		default:
+			rec = Record{Skip: "unsupported kind " + it.Kind}
+		}
+		if err := yield(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// parseGPasteDate accepts Unix seconds or microseconds (GLib's
+// g_get_real_time) and RFC 3339.
+func parseGPasteDate(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e14 {
+			return time.UnixMicro(n).UTC()
+		}
+		return time.Unix(n, 0).UTC()
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	return time.Time{}
+}
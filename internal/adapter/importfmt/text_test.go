@@ -0,0 +1,133 @@
+package importfmt
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, name, input string) []Record {
+	t.Helper()
+	f, ok := Lookup(name)
+	if !ok {
+		t.Fatalf("no format %q", name)
+	}
+	var out []Record
+	err := f.Read(strings.NewReader(input), func(r Record) error {
+		out = append(out, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func contents(recs []Record) []string {
+	out := make([]string, len(recs))
+	for i, r := range recs {
+		out[i] = r.Content
+		if r.Skip != "" {
+			out[i] = "skip: " + r.Skip
+		}
+	}
+	return out
+}
+
+func TestReadOtterClip_Shapes(t *testing.T) {
+	array := `[
+	  {"id":"1","type":"url","content":"https://a","created_at":"2024-01-02T03:04:05Z","last_seen_at":"2024-02-02T03:04:05Z",
+	   "pinned":true,"title":"A","note":"n","source":{"app_id":"firefox"},"tags":["web"]},
+	  {"id":"2","type":"text","content":"b","created_at":"2024-01-01T00:00:00Z","last_seen_at":"2024-01-01T00:00:00Z"}
+	]`
+	recs := readAll(t, "otterclip", array)
+	if got := contents(recs); !slices.Equal(got, []string{"https://a", "b"}) {
+		t.Fatalf("contents = %q", got)
+	}
+	r := recs[0]
+	want := time.Date(2024, 2, 2, 3, 4, 5, 0, time.UTC)
+	if r.Type != "url" || !r.Pinned || r.Title != "A" || r.Note != "n" ||
+		r.Source.AppID != "firefox" || !slices.Equal(r.Tags, []string{"web"}) || !r.LastSeenAt.Equal(want) {
+		t.Fatalf("record = %+v", r)
+	}
+
+	collection := `{"name":"work","items":[{"content":"x"},{"content":"y"}]}`
+	if got := contents(readAll(t, "otterclip", collection)); !slices.Equal(got, []string{"x", "y"}) {
+		t.Fatalf("collection = %q", got)
+	}
+
+	lines := "{\"content\":\"one\"}\n{\"content\":\"two\"}\n"
+	if got := contents(readAll(t, "otterclip", lines)); !slices.Equal(got, []string{"one", "two"}) {
+		t.Fatalf("lines = %q", got)
+	}
+
+	if got := readAll(t, "otterclip", "  \n"); len(got) != 0 {
+		t.Fatalf("empty input = %+v", got)
+	}
+}
+
+func TestReadCopyQ(t *testing.T) {
+	input := `{"text":"hello","notes":"greeting","tags":"a\nb, c","pinned":true}
+{"text":null,"notes":"","tags":"","pinned":false}
+`
+	recs := readAll(t, "copyq", input)
+	if len(recs) != 2 {
+		t.Fatalf("got %d records", len(recs))
+	}
+	if r := recs[0]; r.Content != "hello" || r.Note != "greeting" || !r.Pinned || !slices.Equal(r.Tags, []string{"a", "b", "c"}) {
+		t.Fatalf("record = %+v", r)
+	}
+	if recs[1].Skip == "" {
+		t.Fatal("item without text should be skipped")
+	}
+}
+
+func TestReadCliphist(t *testing.T) {
+	input := "newest\x00multi\nline\x00\xff\xfe\x00oldest"
+	got := contents(readAll(t, "cliphist", input))
+	want := []string{"newest", "multi\nline", "skip: binary data (image?)", "oldest"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadClipman_NewestFirst(t *testing.T) {
+	got := contents(readAll(t, "clipman", `["old","mid","new"]`))
+	if !slices.Equal(got, []string{"new", "mid", "old"}) {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestReadGPaste(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<history version="2.0">
+  <item kind="Text" date="1700000000"><value><![CDATA[hello <world>]]></value></item>
+  <item kind="Password" name="bank"><value><![CDATA[hunter2]]></value></item>
+  <item kind="Uris"><value><![CDATA[file:///tmp/a]]></value></item>
+  <item kind="Image"><value><![CDATA[/tmp/img.png]]></value></item>
+  <item>legacy</item>
+</history>`
+	recs := readAll(t, "gpaste", input)
+	got := contents(recs)
+	want := []string{"hello <world>", "skip: password", "file:///tmp/a", "skip: image", "legacy"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if !recs[0].LastSeenAt.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("date = %v", recs[0].LastSeenAt)
+	}
+	if strings.Contains(recs[1].Content, "hunter2") {
+		t.Fatal("password content leaked into a skipped record")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	want := []string{"otterclip", "copyq", "cliphist", "clipman", "gpaste", "maccy", "ditto"}
+	if got := Names(); !slices.Equal(got, want) {
+		t.Fatalf("Names() = %q", got)
+	}
+	if _, ok := Lookup("nope"); ok {
+		t.Fatal("Lookup found an unknown format")
+	}
+}
@@ -0,0 +1,191 @@
+package paste
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+)
+
+// Tool is a keystroke helper the Command backend runs.
+type Tool string
+
+const (
+	// Wtype uses the virtual-keyboard protocol (wlroots compositors).
+	Wtype Tool = "wtype"
+	// Ydotool writes to /dev/uinput through ydotoold and works on any
+	// compositor, but needs the daemon running.
+	Ydotool Tool = "ydotool"
+)
+
+// Command pastes on Wayland by running wtype or ydotool. Clients can't
+// focus other windows there, so Remember does nothing: hiding the
+// launcher lets the compositor return focus to the previous window, and
+// Paste waits Delay for that before typing.
+type Command struct {
+	Tool  Tool
+	Delay time.Duration
+
+	run func(ctx context.Context, name string, args ...string) error
+}
+
+// NewCommand picks the first helper found on $PATH, preferring wtype.
+func NewCommand() (*Command, error) {
+	for _, t := range []Tool{Wtype, Ydotool} {
+		if _, err := exec.LookPath(string(t)); err == nil {
+			return &Command{Tool: t, Delay: 150 * time.Millisecond}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: install wtype or ydotool", ErrUnsupported)
+}
+
+func (c *Command) Remember() error { return nil }
+
+func (c *Command) Paste(ctx context.Context, chord hotkey.Accelerator) error {
+	args, err := c.Args(chord)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(c.Delay):
+	}
+	run := c.run
+	if run == nil {
+		run = runCommand
+	}
+	return run(ctx, string(c.Tool), args...)
+}
+
+// Args returns the helper's arguments for pressing chord.
+func (c *Command) Args(chord hotkey.Accelerator) ([]string, error) {
+	switch c.Tool {
+	case Wtype:
+		return wtypeArgs(chord)
+	case Ydotool:
+		return ydotoolArgs(chord)
+	}
+	return nil, fmt.Errorf("paste: unknown tool %q", c.Tool)
+}
+
+func runCommand(ctx context.Context, name string, args ...string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("paste: %s: %v: %s", name, err, msg)
+		}
+		return fmt.Errorf("paste: %s: %w", name, err)
+	}
+	return nil
+}
+
+// modifiers in press order, with their wtype names and Linux input codes
+var modKeys = []struct {
+	mod   hotkey.Modifier
+	wtype string
+	code  int
+}{
+	{hotkey.ModCtrl, "ctrl", 29},   // KEY_LEFTCTRL
+	{hotkey.ModShift, "shift", 42}, // KEY_LEFTSHIFT
+	{hotkey.ModAlt, "alt", 56},     // KEY_LEFTALT
+	{hotkey.ModSuper, "logo", 125}, // KEY_LEFTMETA
+}
+
+// wtype -M ctrl -M shift -k v -m shift -m ctrl
+func wtypeArgs(chord hotkey.Accelerator) ([]string, error) {
+	name, ok := xkbName(chord.Key)
+	if !ok {
+		return nil, fmt.Errorf("paste: wtype can't type %s", chord.Key)
+	}
+	var args, release []string
+	for _, m := range modKeys {
+		if chord.Mods&m.mod != 0 {
+			args = append(args, "-M", m.wtype)
+			release = append([]string{"-m", m.wtype}, release...)
+		}
+	}
+	args = append(args, "-k", name)
+	return append(args, release...), nil
+}
+
+// ydotool key 29:1 47:1 47:0 29:0
+func ydotoolArgs(chord hotkey.Accelerator) ([]string, error) {
+	code, ok := inputCode(chord.Key)
+	if !ok {
+		return nil, fmt.Errorf("paste: ydotool can't type %s", chord.Key)
+	}
+	press := func(code int, down bool) string {
+		if down {
+			return strconv.Itoa(code) + ":1"
+		}
+		return strconv.Itoa(code) + ":0"
+	}
+	args := []string{"key"}
+	var release []string
+	for _, m := range modKeys {
+		if chord.Mods&m.mod != 0 {
+			args = append(args, press(m.code, true))
+			release = append([]string{press(m.code, false)}, release...)
+		}
+	}
+	args = append(args, press(code, true), press(code, false))
+	return append(args, release...), nil
+}
+
+// xkb keysym names for keys whose canonical name differs
+var xkbNames = map[string]string{
+	"Space": "space", "Enter": "Return", "Backspace": "BackSpace",
+	"PageUp": "Prior", "PageDown": "Next",
+	",": "comma", ".": "period", "/": "slash", ";": "semicolon",
+	"-": "minus", "=": "equal", "`": "grave", "[": "bracketleft",
+	"]": "bracketright", "'": "apostrophe", "\\": "backslash",
+}
+
+func xkbName(key string) (string, bool) {
+	if name, ok := xkbNames[key]; ok {
+		return name, true
+	}
+	if len(key) == 1 {
+		return strings.ToLower(key), true
+	}
+	if _, ok := hotkey.Keysym(key); ok {
+		return key, true // Tab, Escape, Insert, Home, F1, ...
+	}
+	return "", false
+}
+
+// Linux input event codes (linux/input-event-codes.h) for canonical keys.
+var inputCodes = map[string]int{
+	"Escape": 1, "Backspace": 14, "Tab": 15, "Enter": 28, "Space": 57,
+	"Home": 102, "Up": 103, "PageUp": 104, "Left": 105, "Right": 106,
+	"End": 107, "Down": 108, "PageDown": 109, "Insert": 110, "Delete": 111,
+	"1": 2, "2": 3, "3": 4, "4": 5, "5": 6, "6": 7, "7": 8, "8": 9, "9": 10, "0": 11,
+	"-": 12, "=": 13, "[": 26, "]": 27, ";": 39, "'": 40, "`": 41, "\\": 43,
+	",": 51, ".": 52, "/": 53,
+	"Q": 16, "W": 17, "E": 18, "R": 19, "T": 20, "Y": 21, "U": 22, "I": 23, "O": 24, "P": 25,
+	"A": 30, "S": 31, "D": 32, "F": 33, "G": 34, "H": 35, "J": 36, "K": 37, "L": 38,
+	"Z": 44, "X": 45, "C": 46, "V": 47, "B": 48, "N": 49, "M": 50,
+	"F11": 87, "F12": 88,
+}
+
+func inputCode(key string) (int, bool) {
+	if code, ok := inputCodes[key]; ok {
+		return code, true
+	}
+	if len(key) > 1 && key[0] == 'F' {
+		n, err := strconv.Atoi(key[1:])
+		switch {
+		case err != nil:
+		case n >= 1 && n <= 10:
+			return 58 + n, true // KEY_F1 is 59
+		case n >= 13 && n <= 24:
+			return 170 + n, true // KEY_F13 is 183
+		}
+	}
+	return 0, false
+}
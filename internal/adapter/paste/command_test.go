@@ -0,0 +1,65 @@
+package paste
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+)
+
+func TestCommand_Args(t *testing.T) {
+	cases := []struct {
+		tool  Tool
+		chord string
+		want  string
+	}{
+		{Wtype, "Ctrl+V", "-M ctrl -k v -m ctrl"},
+		{Wtype, "Ctrl+Shift+V", "-M ctrl -M shift -k v -m shift -m ctrl"},
+		{Wtype, "Shift+Insert", "-M shift -k Insert -m shift"},
+		{Wtype, "Super+Enter", "-M logo -k Return -m logo"},
+		{Ydotool, "Ctrl+V", "key 29:1 47:1 47:0 29:0"},
+		{Ydotool, "Ctrl+Shift+V", "key 29:1 42:1 47:1 47:0 42:0 29:0"},
+		{Ydotool, "Shift+Insert", "key 42:1 110:1 110:0 42:0"},
+		{Ydotool, "F13", "key 183:1 183:0"},
+	}
+	for _, c := range cases {
+		args, err := (&Command{Tool: c.tool}).Args(hotkey.MustParse(c.chord))
+		if err != nil {
+			t.Fatalf("%s %s: %v", c.tool, c.chord, err)
+		}
+		if got := strings.Join(args, " "); got != c.want {
+			t.Errorf("%s %s: got %q, want %q", c.tool, c.chord, got, c.want)
+		}
+	}
+
+	if _, err := (&Command{Tool: "xdotool"}).Args(DefaultChord); err == nil {
+		t.Error("expected unknown tool to fail")
+	}
+}
+
+func TestCommand_Paste(t *testing.T) {
+	var got []string
+	c := &Command{Tool: Wtype, run: func(_ context.Context, name string, args ...string) error {
+		got = append([]string{name}, args...)
+		return nil
+	}}
+	if err := c.Remember(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Paste(context.Background(), DefaultChord); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"wtype", "-M", "ctrl", "-k", "v", "-m", "ctrl"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ran %q, want %q", got, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.Delay = time.Hour
+	if err := c.Paste(ctx, DefaultChord); err != context.Canceled {
+		t.Fatalf("expected a cancelled paste to stop waiting, got %v", err)
+	}
+}
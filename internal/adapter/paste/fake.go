@@ -0,0 +1,48 @@
+package paste
+
+import (
+	"context"
+	"sync"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+)
+
+// Fake records what would have been pasted; for tests.
+type Fake struct {
+	mu         sync.Mutex
+	remembered int
+	chords     []hotkey.Accelerator
+	// Err, when set, is returned by Paste.
+	Err error
+}
+
+func (f *Fake) Remember() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.remembered++
+	return nil
+}
+
+func (f *Fake) Paste(_ context.Context, chord hotkey.Accelerator) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.Err != nil {
+		return f.Err
+	}
+	f.chords = append(f.chords, chord)
+	return nil
+}
+
+// Remembered reports how many times Remember was called.
+func (f *Fake) Remembered() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.remembered
+}
+
+// Chords returns the chords pasted so far.
+func (f *Fake) Chords() []hotkey.Accelerator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]hotkey.Accelerator(nil), f.chords...)
+}
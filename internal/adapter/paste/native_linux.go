@@ -0,0 +1,17 @@
+//go:build linux
+
+package paste
+
+import "os"
+
+// Native returns the paster for this session: wtype or ydotool under
+// Wayland, XTest under X11.
+func Native() (Paster, error) {
+	switch {
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		return NewCommand()
+	case os.Getenv("DISPLAY") != "":
+		return NewX11()
+	}
+	return nil, ErrUnsupported
+}
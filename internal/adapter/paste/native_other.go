@@ -0,0 +1,7 @@
+//go:build !linux
+
+package paste
+
+// Native returns ErrUnsupported: only X11 and Wayland are implemented, so
+// elsewhere choosing an item just copies it.
+func Native() (Paster, error) { return nil, ErrUnsupported }
@@ -0,0 +1,94 @@
+// Package paste types a chosen clip into another application: it returns
+// focus to the window that was active before the launcher appeared and
+// synthesises the paste keystroke there. Backends drive X11 through the
+// XTest extension or, on Wayland, the wtype / ydotool helpers.
+package paste
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// ErrUnsupported is returned by Native when no backend can inject keys in
+// this session.
+var ErrUnsupported = errors.New("paste: synthesising keystrokes not supported here")
+
+// Paster sends the paste keystroke to the previously focused window.
+type Paster interface {
+	// Remember notes the focused window so Paste can go back to it. Call
+	// it before the launcher takes focus.
+	Remember() error
+	// Paste focuses the remembered window, where the backend can, and
+	// presses chord in it.
+	Paste(ctx context.Context, chord hotkey.Accelerator) error
+}
+
+// DefaultChord pastes in most applications.
+var DefaultChord = hotkey.MustParse("Ctrl+V")
+
+// ChordRule overrides the paste chord for applications matching App (see
+// core.Source.Matches).
+type ChordRule struct {
+	App   string
+	Chord hotkey.Accelerator
+}
+
+// ChordRules pick the paste chord for a target application: the first
+// matching rule wins, then the built-in terminal rules, then DefaultChord.
+type ChordRules []ChordRule
+
+// Terminals take Ctrl+V as a literal control character, so they get the
+// chord they bind to paste.
+var terminalRules = ChordRules{
+	{"kitty", hotkey.MustParse("Ctrl+Shift+V")},
+	{"alacritty", hotkey.MustParse("Ctrl+Shift+V")},
+	{"foot", hotkey.MustParse("Ctrl+Shift+V")},
+	{"wezterm", hotkey.MustParse("Ctrl+Shift+V")},
+	{"ghostty", hotkey.MustParse("Ctrl+Shift+V")},
+	{"konsole", hotkey.MustParse("Ctrl+Shift+V")},
+	{"tilix", hotkey.MustParse("Ctrl+Shift+V")},
+	{"terminator", hotkey.MustParse("Ctrl+Shift+V")},
+	{"terminal", hotkey.MustParse("Ctrl+Shift+V")}, // gnome-, xfce4-, mate-terminal
+	{"xterm", hotkey.MustParse("Shift+Insert")},
+	{"urxvt", hotkey.MustParse("Shift+Insert")},
+}
+
+// ParseChordRules parses "app=chord" pairs separated by commas or
+// newlines, e.g. "kitty=Ctrl+Shift+V, emacs=Ctrl+Y".
+func ParseChordRules(s string) (ChordRules, error) {
+	var rules ChordRules
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		app, chord, ok := strings.Cut(f, "=")
+		app = strings.TrimSpace(app)
+		if !ok || app == "" {
+			return nil, fmt.Errorf("paste rule %q: want app=chord", f)
+		}
+		accel, err := hotkey.ParseAccelerator(chord)
+		if err != nil {
+			return nil, fmt.Errorf("paste rule %q: %w", f, err)
+		}
+		rules = append(rules, ChordRule{App: app, Chord: accel})
+	}
+	return rules, nil
+}
+
+// For returns the paste chord for src.
+func (r ChordRules) For(src core.Source) hotkey.Accelerator {
+	for _, rules := range []ChordRules{r, terminalRules} {
+		for _, rule := range rules {
+			if src.Matches(rule.App) {
+				return rule.Chord
+			}
+		}
+	}
+	return DefaultChord
+}
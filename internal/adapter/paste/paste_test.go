@@ -0,0 +1,45 @@
+package paste
+
+import (
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestParseChordRules(t *testing.T) {
+	rules, err := ParseChordRules("emacs=Ctrl+Y,\n kitty = ctrl+alt+v ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].App != "emacs" || rules[1].Chord.String() != "Ctrl+Alt+V" {
+		t.Fatalf("unexpected rules %+v", rules)
+	}
+
+	for _, bad := range []string{"kitty", "=Ctrl+V", "kitty=V", "kitty=Ctrl+Nope"} {
+		if _, err := ParseChordRules(bad); err == nil {
+			t.Errorf("expected %q rejected", bad)
+		}
+	}
+}
+
+func TestChordRules_For(t *testing.T) {
+	rules, _ := ParseChordRules("kitty=Ctrl+Alt+V, emacs=Ctrl+Y")
+	cases := []struct {
+		src  core.Source
+		want string
+	}{
+		{core.Source{WindowClass: "firefox"}, "Ctrl+V"},
+		{core.Source{}, "Ctrl+V"},
+		{core.Source{AppID: "Emacs"}, "Ctrl+Y"},
+		// user rules come before the built-in terminal ones
+		{core.Source{AppID: "kitty"}, "Ctrl+Alt+V"},
+		{core.Source{AppID: "org.wezfurlong.wezterm"}, "Ctrl+Shift+V"},
+		{core.Source{WindowClass: "gnome-terminal-server"}, "Ctrl+Shift+V"},
+		{core.Source{Process: "xterm"}, "Shift+Insert"},
+	}
+	for _, c := range cases {
+		if got := rules.For(c.src).String(); got != c.want {
+			t.Errorf("For(%+v) = %s, want %s", c.src, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+//go:build linux
+
+package paste
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+	"github.com/jezek/xgb/xtest"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+)
+
+// Left-hand modifier keysyms, pressed around the chord's key.
+var modKeysyms = []struct {
+	mod    hotkey.Modifier
+	keysym xproto.Keysym
+}{
+	{hotkey.ModCtrl, 0xffe3},  // Control_L
+	{hotkey.ModShift, 0xffe1}, // Shift_L
+	{hotkey.ModAlt, 0xffe9},   // Alt_L
+	{hotkey.ModSuper, 0xffeb}, // Super_L
+}
+
+// X11 pastes through the XTest extension. Remember reads
+// _NET_ACTIVE_WINDOW; Paste asks the window manager to activate that
+// window again and fakes the key presses.
+type X11 struct {
+	conn  *xgb.Conn
+	root  xproto.Window
+	codes map[xproto.Keysym]xproto.Keycode
+	atom  xproto.Atom // _NET_ACTIVE_WINDOW
+
+	// Delay lets the window manager finish switching focus before keys
+	// are sent.
+	Delay time.Duration
+
+	mu     sync.Mutex
+	target xproto.Window
+}
+
+// NewX11 connects to $DISPLAY and checks for the XTest extension.
+func NewX11() (*X11, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("paste: connect to X11: %w", err)
+	}
+	if err := xtest.Init(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("%w: no XTest extension: %v", ErrUnsupported, err)
+	}
+	codes, err := hotkey.KeyboardMap(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	const name = "_NET_ACTIVE_WINDOW"
+	atom, err := xproto.InternAtom(conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("paste: %s: %w", name, err)
+	}
+	return &X11{
+		conn:  conn,
+		root:  xproto.Setup(conn).DefaultScreen(conn).Root,
+		codes: codes,
+		atom:  atom.Atom,
+		Delay: 80 * time.Millisecond,
+	}, nil
+}
+
+func (x *X11) Remember() error {
+	prop, err := xproto.GetProperty(x.conn, false, x.root, x.atom, xproto.AtomWindow, 0, 1).Reply()
+	if err != nil {
+		return fmt.Errorf("paste: read active window: %w", err)
+	}
+	var w xproto.Window
+	if len(prop.Value) >= 4 {
+		w = xproto.Window(xgb.Get32(prop.Value))
+	}
+	x.mu.Lock()
+	x.target = w
+	x.mu.Unlock()
+	return nil
+}
+
+func (x *X11) Paste(ctx context.Context, chord hotkey.Accelerator) error {
+	ks, ok := hotkey.Keysym(chord.Key)
+	if !ok {
+		return fmt.Errorf("paste: no X11 keysym for %s", chord.Key)
+	}
+	key, ok := x.codes[xproto.Keysym(ks)]
+	if !ok {
+		return fmt.Errorf("paste: %s is not on this keyboard", chord.Key)
+	}
+	var mods []xproto.Keycode
+	for _, m := range modKeysyms {
+		if chord.Mods&m.mod == 0 {
+			continue
+		}
+		code, ok := x.codes[m.keysym]
+		if !ok {
+			return fmt.Errorf("paste: no keycode for modifier in %s", chord)
+		}
+		mods = append(mods, code)
+	}
+
+	x.mu.Lock()
+	target := x.target
+	x.mu.Unlock()
+	if target != 0 {
+		if err := x.activate(target); err != nil {
+			return err
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(x.Delay):
+	}
+
+	var errs []error
+	for _, code := range mods {
+		errs = append(errs, x.fake(xproto.KeyPress, code))
+	}
+	errs = append(errs, x.fake(xproto.KeyPress, key), x.fake(xproto.KeyRelease, key))
+	for i := len(mods) - 1; i >= 0; i-- {
+		errs = append(errs, x.fake(xproto.KeyRelease, mods[i]))
+	}
+	return errors.Join(errs...)
+}
+
+// activate sends the EWMH request to focus w; source indication 2 says it
+// comes from a pager-like tool, which window managers honour over their
+// focus-stealing prevention.
+func (x *X11) activate(w xproto.Window) error {
+	ev := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: w,
+		Type:   x.atom,
+		Data:   xproto.ClientMessageDataUnionData32New([]uint32{2, xproto.TimeCurrentTime, 0, 0, 0}),
+	}
+	mask := uint32(xproto.EventMaskSubstructureRedirect | xproto.EventMaskSubstructureNotify)
+	if err := xproto.SendEventChecked(x.conn, false, x.root, mask, string(ev.Bytes())).Check(); err != nil {
+		return fmt.Errorf("paste: activate window: %w", err)
+	}
+	return nil
+}
+
+func (x *X11) fake(typ byte, code xproto.Keycode) error {
+	return xtest.FakeInputChecked(x.conn, typ, byte(code), xproto.TimeCurrentTime, x.root, 0, 0, 0).Check()
+}
+
+// Close disconnects from the X server.
+func (x *X11) Close() error {
+	x.conn.Close()
+	return nil
+}
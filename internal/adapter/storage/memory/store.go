@@ -3,6 +3,10 @@ package memory
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,7 +14,8 @@ import (
 	"github.com/its-jojoo/otterclip/internal/core"
 )
 
-var ErrNotFound = errors.New("not found")
+// ErrNotFound is storage.ErrNotFound, kept for callers of this package.
+var ErrNotFound = storage.ErrNotFound
 
 type Store struct {
 	mu   sync.RWMutex
@@ -20,6 +25,18 @@ type Store struct {
 
 	fpToID map[string]string
 	list   []string
+
+	collections map[string]*collection
+	snippets    map[string]core.Snippet
+	settings    map[string]string
+	queue       []string // fingerprints, oldest first
+	revisions   map[string][]core.Revision
+	counters    map[string]map[int64]int // name -> unix hour -> count
+}
+
+type collection struct {
+	createdAt time.Time
+	ids       []string
 }
 
 func New() *Store {
@@ -27,11 +44,20 @@ func New() *Store {
 		now:    time.Now,
 		byID:   make(map[string]core.Item),
 		fpToID: make(map[string]string),
+
+		collections: make(map[string]*collection),
+		snippets:    make(map[string]core.Snippet),
+		settings:    make(map[string]string),
+		revisions:   make(map[string][]core.Revision),
+		counters:    make(map[string]map[int64]int),
 	}
 }
 
 func (s *Store) Now() time.Time { return s.now() }
 
+// SetClock replaces the store's time source (tests).
+func (s *Store) SetClock(now func() time.Time) { s.now = now }
+
 func (s *Store) Put(ctx context.Context, item core.Item, mode storage.PutMode) error {
 	_ = ctx
 
@@ -51,6 +77,19 @@ func (s *Store) Put(ctx context.Context, item core.Item, mode storage.PutMode) e
 			existing.Type = item.Type
 			existing.LastSeenAt = item.LastSeenAt
 			existing.Fingerprint = item.Fingerprint
+			existing.Source = item.Source
+			if !existing.ExpiresAt.IsZero() {
+				existing.ExpiresAt = item.ExpiresAt
+			}
+			existing.Meta = item.Meta
+			existing.Tags = mergeTags(existing.Tags, item.Tags)
+			if item.Title != "" {
+				existing.Title = item.Title
+			}
+			if item.Note != "" {
+				existing.Note = item.Note
+			}
+			existing.DeletedAt = time.Time{} // copying again restores from trash
 			// keep existing.CreatedAt and existing.Pinned
 			s.byID[existingID] = existing
 
@@ -62,10 +101,30 @@ func (s *Store) Put(ctx context.Context, item core.Item, mode storage.PutMode) e
 	// Merge by ID (used by callers that explicitly want to update an existing row)
 	if mode == storage.PutMerge {
 		if existing, ok := s.byID[item.ID]; ok {
+			if other, ok := s.fpToID[item.Fingerprint]; ok && other != item.ID {
+				return storage.ErrDuplicate
+			}
+			if existing.Content != item.Content {
+				revs := s.revisions[item.ID]
+				s.revisions[item.ID] = append(revs, core.Revision{
+					ItemID:     item.ID,
+					Rev:        len(revs) + 1,
+					Content:    existing.Content,
+					ReplacedAt: s.now(),
+				})
+			}
+			if existing.Fingerprint != item.Fingerprint {
+				delete(s.fpToID, existing.Fingerprint)
+				s.fpToID[item.Fingerprint] = item.ID
+			}
+
 			existing.LastSeenAt = item.LastSeenAt
 			existing.Content = item.Content
 			existing.Type = item.Type
 			existing.Fingerprint = item.Fingerprint
+			existing.Meta = item.Meta
+			existing.Title = item.Title
+			existing.Note = item.Note
 			// keep existing.CreatedAt and existing.Pinned
 			s.byID[item.ID] = existing
 			s.moveToFront(item.ID)
@@ -74,6 +133,7 @@ func (s *Store) Put(ctx context.Context, item core.Item, mode storage.PutMode) e
 	}
 
 	// Insert new
+	item.Tags = mergeTags(nil, item.Tags)
 	s.byID[item.ID] = item
 	if item.Fingerprint != "" {
 		s.fpToID[item.Fingerprint] = item.ID
@@ -93,13 +153,66 @@ func (s *Store) ListRecent(ctx context.Context, limit int) ([]core.Item, error)
 	}
 
 	out := make([]core.Item, 0, limit)
-	for i := 0; i < limit; i++ {
-		id := s.list[i]
-		out = append(out, s.byID[id])
+	for _, id := range s.list {
+		if len(out) == limit {
+			break
+		}
+		if it := s.byID[id]; it.DeletedAt.IsZero() {
+			out = append(out, it)
+		}
 	}
 	return out, nil
 }
 
+// WalkRecent walks a snapshot of the history, so fn may use the store.
+func (s *Store) WalkRecent(ctx context.Context, limit int, fn func(core.Item) error) error {
+	items, err := s.ListRecent(ctx, limit)
+	if err != nil {
+		return err
+	}
+	for _, it := range items {
+		if err := fn(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) FindByIDPrefix(ctx context.Context, prefix string, limit int) ([]core.Item, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if prefix == "" {
+		return nil, nil
+	}
+	var out []core.Item
+	for id, it := range s.byID {
+		if strings.HasPrefix(id, prefix) {
+			out = append(out, it)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Store) FindByFingerprint(ctx context.Context, fp string) (core.Item, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.fpToID[fp]
+	if !ok {
+		return core.Item{}, ErrNotFound
+	}
+	return s.byID[id], nil
+}
+
 func (s *Store) SetPinned(ctx context.Context, id string, pinned bool) error {
 	_ = ctx
 
@@ -115,12 +228,39 @@ func (s *Store) SetPinned(ctx context.Context, id string, pinned bool) error {
 	return nil
 }
 
+func (s *Store) SetTitle(ctx context.Context, id string, title string) error {
+	return s.update(ctx, id, func(it *core.Item) { it.Title = title })
+}
+
+func (s *Store) SetNote(ctx context.Context, id string, note string) error {
+	return s.update(ctx, id, func(it *core.Item) { it.Note = note })
+}
+
+func (s *Store) update(ctx context.Context, id string, fn func(it *core.Item)) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	fn(&it)
+	s.byID[id] = it
+	return nil
+}
+
 func (s *Store) Delete(ctx context.Context, id string) error {
 	_ = ctx
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	return s.delete(id)
+}
+
+func (s *Store) delete(id string) error {
 	it, ok := s.byID[id]
 	if !ok {
 		return ErrNotFound
@@ -140,6 +280,10 @@ func (s *Store) Delete(ctx context.Context, id string) error {
 			break
 		}
 	}
+	for _, c := range s.collections {
+		c.ids = removeID(c.ids, id)
+	}
+	delete(s.revisions, id)
 	return nil
 }
 
@@ -147,7 +291,431 @@ func (s *Store) Count(ctx context.Context) (int, error) {
 	_ = ctx
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.byID), nil
+	n := 0
+	for _, it := range s.byID {
+		if it.DeletedAt.IsZero() {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Store) Trash(ctx context.Context, id string) error {
+	now := s.now()
+	return s.update(ctx, id, func(it *core.Item) { it.DeletedAt = now })
+}
+
+func (s *Store) Restore(ctx context.Context, id string) error {
+	return s.update(ctx, id, func(it *core.Item) { it.DeletedAt = time.Time{} })
+}
+
+func (s *Store) ListTrash(ctx context.Context, limit int) ([]core.Item, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var out []core.Item
+	for _, it := range s.byID {
+		if !it.DeletedAt.IsZero() {
+			out = append(out, it)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].DeletedAt.After(out[j].DeletedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (s *Store) PurgeTrash(ctx context.Context, cutoff time.Time) (int, error) {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, it := range s.byID {
+		if it.DeletedAt.IsZero() || (!cutoff.IsZero() && !it.DeletedAt.Before(cutoff)) {
+			continue
+		}
+		if err := s.delete(id); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// live returns an item that exists and is not in the trash.
+func (s *Store) live(id string) (core.Item, bool) {
+	it, ok := s.byID[id]
+	return it, ok && it.DeletedAt.IsZero()
+}
+
+func (s *Store) AddTags(ctx context.Context, itemID string, tags ...string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.byID[itemID]
+	if !ok {
+		return ErrNotFound
+	}
+	it.Tags = mergeTags(it.Tags, tags)
+	s.byID[itemID] = it
+	return nil
+}
+
+func (s *Store) RemoveTags(ctx context.Context, itemID string, tags ...string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	it, ok := s.byID[itemID]
+	if !ok {
+		return ErrNotFound
+	}
+	drop := make(map[string]bool, len(tags))
+	for _, t := range core.NormalizeTags(tags) {
+		drop[t] = true
+	}
+	kept := make([]string, 0, len(it.Tags))
+	for _, t := range it.Tags {
+		if !drop[t] {
+			kept = append(kept, t)
+		}
+	}
+	it.Tags = kept
+	s.byID[itemID] = it
+	return nil
+}
+
+func (s *Store) ListTags(ctx context.Context) ([]core.TagCount, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, it := range s.byID {
+		if !it.DeletedAt.IsZero() {
+			continue
+		}
+		for _, t := range it.Tags {
+			counts[t]++
+		}
+	}
+	out := make([]core.TagCount, 0, len(counts))
+	for name, n := range counts {
+		out = append(out, core.TagCount{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func (s *Store) AddToCollection(ctx context.Context, name string, itemIDs ...string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name == "" {
+		return errors.New("collection name required")
+	}
+	c, ok := s.collections[name]
+	if !ok {
+		c = &collection{createdAt: s.now()}
+		s.collections[name] = c
+	}
+	for _, id := range itemIDs {
+		if _, ok := s.byID[id]; !ok {
+			return ErrNotFound
+		}
+		if !slices.Contains(c.ids, id) {
+			c.ids = append(c.ids, id)
+		}
+	}
+	return nil
+}
+
+func (s *Store) RemoveFromCollection(ctx context.Context, name string, itemIDs ...string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.collections[name]
+	if !ok {
+		return nil
+	}
+	for _, id := range itemIDs {
+		c.ids = removeID(c.ids, id)
+	}
+	return nil
+}
+
+func (s *Store) ListCollections(ctx context.Context) ([]core.Collection, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]core.Collection, 0, len(s.collections))
+	for name, c := range s.collections {
+		n := 0
+		for _, id := range c.ids {
+			if _, ok := s.live(id); ok {
+				n++
+			}
+		}
+		out = append(out, core.Collection{Name: name, Count: n, CreatedAt: c.createdAt})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *Store) CollectionItems(ctx context.Context, name string) ([]core.Item, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.collections[name]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := make([]core.Item, 0, len(c.ids))
+	for _, id := range c.ids {
+		if it, ok := s.live(id); ok {
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) DeleteCollection(ctx context.Context, name string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.collections[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.collections, name)
+	return nil
+}
+
+func (s *Store) PutSnippet(ctx context.Context, sn core.Snippet) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sn.Name == "" {
+		return errors.New("snippet name required")
+	}
+	now := s.now()
+	if old, ok := s.snippets[sn.Name]; ok {
+		sn.CreatedAt = old.CreatedAt
+	} else if sn.CreatedAt.IsZero() {
+		sn.CreatedAt = now
+	}
+	if sn.UpdatedAt.IsZero() {
+		sn.UpdatedAt = now
+	}
+	s.snippets[sn.Name] = sn
+	return nil
+}
+
+func (s *Store) GetSnippet(ctx context.Context, name string) (core.Snippet, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sn, ok := s.snippets[name]
+	if !ok {
+		return core.Snippet{}, ErrNotFound
+	}
+	return sn, nil
+}
+
+func (s *Store) ListSnippets(ctx context.Context) ([]core.Snippet, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]core.Snippet, 0, len(s.snippets))
+	for _, sn := range s.snippets {
+		out = append(out, sn)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (s *Store) DeleteSnippet(ctx context.Context, name string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.snippets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(s.snippets, name)
+	return nil
+}
+
+func (s *Store) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.settings[key]
+	return v, ok, nil
+}
+
+func (s *Store) SetSetting(ctx context.Context, key, value string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings[key] = value
+	return nil
+}
+
+func (s *Store) QueuePush(ctx context.Context, fingerprint string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fingerprint == "" {
+		return errors.New("fingerprint required")
+	}
+	s.queue = append(s.queue, fingerprint)
+	return nil
+}
+
+func (s *Store) QueueList(ctx context.Context) ([]core.Item, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]core.Item, 0, len(s.queue))
+	for _, fp := range s.queue {
+		if it, ok := s.live(s.fpToID[fp]); ok {
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) QueuePop(ctx context.Context, newest bool) (core.Item, error) {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// entries whose item was deleted can never be served; trashed items
+	// stay queued in case they are restored
+	kept := s.queue[:0]
+	for _, fp := range s.queue {
+		if _, ok := s.fpToID[fp]; ok {
+			kept = append(kept, fp)
+		}
+	}
+	s.queue = kept
+
+	for i := range s.queue {
+		if newest {
+			i = len(s.queue) - 1 - i
+		}
+		if it, ok := s.live(s.fpToID[s.queue[i]]); ok {
+			s.queue = slices.Delete(s.queue, i, i+1)
+			return it, nil
+		}
+	}
+	return core.Item{}, storage.ErrQueueEmpty
+}
+
+func (s *Store) QueueClear(ctx context.Context) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue = nil
+	return nil
+}
+
+func (s *Store) ListRevisions(ctx context.Context, itemID string) ([]core.Revision, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.revisions[itemID]), nil
+}
+
+func (s *Store) AddCount(ctx context.Context, name string, at time.Time, delta int) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counters[name] == nil {
+		s.counters[name] = make(map[int64]int)
+	}
+	s.counters[name][at.Unix()/3600] += delta
+	return nil
+}
+
+func (s *Store) Counts(ctx context.Context, name string, since time.Time) ([]storage.HourCount, error) {
+	_ = ctx
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	from := since.Unix() / 3600
+	var out []storage.HourCount
+	for _, h := range slices.Sorted(maps.Keys(s.counters[name])) {
+		if h >= from {
+			out = append(out, storage.HourCount{Hour: time.Unix(h*3600, 0), N: s.counters[name][h]})
+		}
+	}
+	return out, nil
+}
+
+func mergeTags(have, add []string) []string {
+	merged := core.NormalizeTags(append(slices.Clone(have), add...))
+	sort.Strings(merged)
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+func removeID(ids []string, id string) []string {
+	for i := range ids {
+		if ids[i] == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
 }
 
 func (s *Store) moveToFront(id string) {
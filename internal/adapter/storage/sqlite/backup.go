@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	driver "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrCorrupt is returned by Verify for databases that fail SQLite's
+// integrity check or aren't OtterClip histories.
+var ErrCorrupt = errors.New("database failed verification")
+
+// ErrInUse is returned by CheckUnused when the database is open elsewhere.
+var ErrInUse = errors.New("database is in use")
+
+// CheckUnused returns ErrInUse when another connection, in this process or
+// another, is using the database at path. Stores use WAL mode, which
+// SQLite only lets the sole connection leave, so the check switches the
+// file to a rollback journal (Open switches it back) and then makes sure
+// no transaction is under way. Other errors, such as a damaged file, are
+// returned as they are.
+func CheckUnused(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var mode string
+	err = conn.QueryRowContext(ctx, `PRAGMA journal_mode=DELETE`).Scan(&mode)
+	if err == nil {
+		_, err = conn.ExecContext(ctx, `BEGIN EXCLUSIVE`)
+		if err == nil {
+			_, err = conn.ExecContext(ctx, `ROLLBACK`)
+		}
+	}
+	var se *driver.Error
+	if errors.As(err, &se) && (se.Code()&0xff == sqlite3.SQLITE_BUSY || se.Code()&0xff == sqlite3.SQLITE_LOCKED) {
+		return ErrInUse
+	}
+	return err
+}
+
+// Backup writes a consistent copy of the database to path with VACUUM
+// INTO, which is safe while other connections (the running app) read and
+// write. The copy is compacted and has no WAL; path must not exist.
+func (s *Store) Backup(ctx context.Context, path string) error {
+	_, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path)
+	return err
+}
+
+// Verify opens the database at path read-only, runs SQLite's integrity
+// check and returns how many history items it holds.
+func Verify(ctx context.Context, path string) (int, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	problems, err := integrityCheck(ctx, db)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if len(problems) > 0 {
+		return 0, fmt.Errorf("%w: %s", ErrCorrupt, strings.Join(problems, "; "))
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM items`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	return n, nil
+}
+
+// maxProblems bounds what integrityCheck reports; a badly damaged file
+// can produce thousands of lines.
+const maxProblems = 10
+
+// integrityCheck returns the problems PRAGMA integrity_check finds, none
+// for a healthy database.
+func integrityCheck(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check(`+fmt.Sprint(maxProblems)+`)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var msg string
+		if err := rows.Scan(&msg); err != nil {
+			return nil, err
+		}
+		if msg != "ok" {
+			problems = append(problems, msg)
+		}
+	}
+	return problems, rows.Err()
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_BackupVerify(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(filepath.Join(dir, "live.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, c := range []string{"one", "two"} {
+		it := core.Item{ID: c, Content: c, Type: core.ContentTypeText, Fingerprint: core.Fingerprint(c), CreatedAt: now, LastSeenAt: now}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.SetPinned(ctx, "one", true); err != nil {
+		t.Fatal(err)
+	}
+
+	// the store stays open and usable, as it is while the app runs
+	backup := filepath.Join(dir, "backup.db")
+	if err := st.Backup(ctx, backup); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Backup(ctx, backup); err == nil {
+		t.Fatal("backup over an existing file should fail")
+	}
+
+	n, err := Verify(ctx, backup)
+	if err != nil || n != 2 {
+		t.Fatalf("Verify = %d, %v", n, err)
+	}
+	copied, err := Open(backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer copied.Close()
+	if it, err := copied.FindByFingerprint(ctx, core.Fingerprint("one")); err != nil || !it.Pinned {
+		t.Fatalf("backup lost the pin: %+v, %v", it, err)
+	}
+}
+
+func TestVerify_RejectsDamagedFiles(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	junk := filepath.Join(dir, "junk.db")
+	if err := os.WriteFile(junk, []byte("definitely not a database, but long enough to look like one......"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Verify(ctx, junk); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("junk: err = %v", err)
+	}
+
+	// a valid SQLite file that isn't a history
+	other := filepath.Join(dir, "other.db")
+	st, err := Open(filepath.Join(dir, "src.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.db.Exec(`DROP TABLE items`); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Backup(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+	if _, err := Verify(ctx, other); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("no items table: err = %v", err)
+	}
+
+	if _, err := Verify(ctx, filepath.Join(dir, "missing.db")); err == nil {
+		t.Fatal("missing file verified")
+	}
+}
+
+func TestCheckUnused(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "live.db")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := st.Count(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CheckUnused(ctx, path); !errors.Is(err, ErrInUse) {
+		t.Fatalf("open store: %v, want ErrInUse", err)
+	}
+	st.Close()
+	if err := CheckUnused(ctx, path); err != nil {
+		t.Fatalf("closed store: %v", err)
+	}
+
+	// the database opens in WAL mode again afterwards
+	st, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	var mode string
+	if err := st.db.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&mode); err != nil || mode != "wal" {
+		t.Fatalf("journal mode = %q, %v", mode, err)
+	}
+}
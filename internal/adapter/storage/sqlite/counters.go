@@ -0,0 +1,55 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+)
+
+func (s *Store) AddCount(ctx context.Context, name string, at time.Time, delta int) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO counters(name, hour, value) VALUES(?, ?, ?)
+ON CONFLICT(name, hour) DO UPDATE SET value=value+excluded.value
+`, name, unixHour(at), delta)
+	return err
+}
+
+func (s *Store) Counts(ctx context.Context, name string, since time.Time) ([]storage.HourCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT hour, value FROM counters WHERE name=? AND hour >= ? ORDER BY hour
+`, name, unixHour(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []storage.HourCount
+	for rows.Next() {
+		var hour int64
+		var hc storage.HourCount
+		if err := rows.Scan(&hour, &hc.N); err != nil {
+			return nil, err
+		}
+		hc.Hour = time.Unix(hour, 0)
+		out = append(out, hc)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) AddSighting(ctx context.Context, fp string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE items SET seen_count=seen_count+1 WHERE fingerprint=?`, fp)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// unixHour is the Unix time of the start of the hour containing t.
+func unixHour(t time.Time) int64 {
+	u := t.Unix()
+	return u - u%3600
+}
@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+)
+
+func TestSQLiteStore_Counters(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	h := time.Unix(1_700_000_000-1_700_000_000%3600, 0)
+	for _, c := range []struct {
+		name string
+		at   time.Time
+	}{
+		{"stored", h.Add(5 * time.Minute)},
+		{"stored", h.Add(59 * time.Minute)},
+		{"stored", h.Add(3 * time.Hour)},
+		{"deduped", h},
+	} {
+		if err := st.AddCount(ctx, c.name, c.at, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := st.Counts(ctx, "stored", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []storage.HourCount{{Hour: h, N: 2}, {Hour: h.Add(3 * time.Hour), N: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Counts = %+v, want %+v", got, want)
+	}
+
+	// since falls inside the last bucket, which is still included
+	got, err = st.Counts(ctx, "stored", h.Add(3*time.Hour+time.Minute))
+	if err != nil || !reflect.DeepEqual(got, want[1:]) {
+		t.Fatalf("Counts since = %+v, %v", got, err)
+	}
+	if got, _ := st.Counts(ctx, "missing", time.Time{}); len(got) != 0 {
+		t.Fatalf("unknown counter = %+v", got)
+	}
+}
@@ -0,0 +1,373 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// Maintenance for `otterclipctl doctor`. Every step that writes takes a
+// dryRun flag: the work is done in a transaction that is rolled back, so a
+// dry run reports exactly what a real one would change.
+
+// IntegrityCheck returns the problems SQLite's integrity check finds in
+// the database, none when it is healthy.
+func (s *Store) IntegrityCheck(ctx context.Context) ([]string, error) {
+	return integrityCheck(ctx, s.db)
+}
+
+// Orphans counts rows that point at something that no longer exists.
+type Orphans struct {
+	ItemTags        int // tag links to missing items or tags
+	CollectionItems int // collection entries for missing items or collections
+	Revisions       int // revisions of missing items
+	QueueEntries    int // paste queue entries no item matches
+	Tags            int // tags no item uses
+}
+
+func (o Orphans) Total() int {
+	return o.ItemTags + o.CollectionItems + o.Revisions + o.QueueEntries + o.Tags
+}
+
+// RemoveOrphans deletes orphaned rows and returns how many there were.
+// Item deletes don't rely on ON DELETE CASCADE (see deleteItem), so these
+// are left behind by older versions and by interrupted writes.
+func (s *Store) RemoveOrphans(ctx context.Context, dryRun bool) (Orphans, error) {
+	var o Orphans
+	err := s.inTxUnless(ctx, dryRun, func(tx *sql.Tx) error {
+		// tags last: removing dangling links can leave them unused
+		for _, q := range []struct {
+			n     *int
+			query string
+		}{
+			{&o.ItemTags, `DELETE FROM item_tags WHERE item_id NOT IN (SELECT id FROM items) OR tag_id NOT IN (SELECT id FROM tags)`},
+			{&o.CollectionItems, `DELETE FROM collection_items WHERE item_id NOT IN (SELECT id FROM items) OR collection_id NOT IN (SELECT id FROM collections)`},
+			{&o.Revisions, `DELETE FROM item_revisions WHERE item_id NOT IN (SELECT id FROM items)`},
+			{&o.QueueEntries, `DELETE FROM paste_queue WHERE fingerprint NOT IN (SELECT fingerprint FROM items)`},
+			{&o.Tags, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM item_tags)`},
+		} {
+			res, err := tx.ExecContext(ctx, q.query)
+			if err != nil {
+				return err
+			}
+			n, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			*q.n = int(n)
+		}
+		return nil
+	})
+	return o, err
+}
+
+// ItemSize is the size of an item's content in bytes.
+type ItemSize struct {
+	ID    string `json:"id"`
+	Bytes int    `json:"bytes"`
+}
+
+// Oversized returns the items whose content is longer than maxBytes,
+// largest first. Capture truncates to core.MaxContentLen and edits refuse
+// longer content, but imports and saved transforms keep what they are
+// given.
+func (s *Store) Oversized(ctx context.Context, maxBytes int) ([]ItemSize, error) {
+	return s.itemSizes(ctx, `size > ?`, -1, maxBytes)
+}
+
+// itemSizes returns up to limit (all when negative) items matching where,
+// which may refer to the content size as size, largest first.
+func (s *Store) itemSizes(ctx context.Context, where string, limit int, args ...any) ([]ItemSize, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT id, length(CAST(content AS BLOB)) AS size
+FROM items
+WHERE `+where+`
+ORDER BY size DESC, id
+LIMIT ?
+`, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ItemSize
+	for rows.Next() {
+		var is ItemSize
+		if err := rows.Scan(&is.ID, &is.Bytes); err != nil {
+			return nil, err
+		}
+		out = append(out, is)
+	}
+	return out, rows.Err()
+}
+
+// Merge records items folded into another because they now share its
+// fingerprint.
+type Merge struct {
+	Into string
+	From []string
+}
+
+type RefingerprintReport struct {
+	// Fingerprints and Types count surviving items whose fingerprint or
+	// type changed.
+	Fingerprints int
+	Types        int
+	Merges       []Merge
+}
+
+// Refingerprint recomputes every item's fingerprint and type with the
+// current core rules, so items stored under older normalization dedupe
+// against new captures again.
+//
+// Items that end up sharing a fingerprint are merged into the most
+// recently seen one, which keeps the earliest CreatedAt, any pin, title
+// or note, and the tags, collections, revisions and sightings of all of
+// them. Paste queue entries follow their item to its new fingerprint.
+func (s *Store) Refingerprint(ctx context.Context, dryRun bool) (RefingerprintReport, error) {
+	var r RefingerprintReport
+	err := s.inTxUnless(ctx, dryRun, func(tx *sql.Tx) error {
+		items, err := allItems(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		groups := make(map[string][]core.Item)
+		for _, it := range items {
+			fp := core.Fingerprint(core.Normalize(it.Content))
+			if fp == "" {
+				continue // blank content; nothing to key it on
+			}
+			groups[fp] = append(groups[fp], it)
+		}
+
+		rekeyed := make(map[string]string) // old fingerprint -> new
+		var changed []core.Item
+		for _, fp := range slices.Sorted(maps.Keys(groups)) {
+			group := groups[fp]
+			slices.SortStableFunc(group, func(a, b core.Item) int {
+				return b.LastSeenAt.Compare(a.LastSeenAt)
+			})
+			keep, orig := group[0], group[0]
+
+			if len(group) > 1 {
+				m := Merge{Into: keep.ID}
+				for _, dup := range group[1:] {
+					if err := mergeInto(ctx, tx, &keep, dup); err != nil {
+						return err
+					}
+					rekeyed[dup.Fingerprint] = fp
+					m.From = append(m.From, dup.ID)
+				}
+				r.Merges = append(r.Merges, m)
+			}
+
+			keep.Fingerprint = fp
+			if keep.Fingerprint != orig.Fingerprint {
+				rekeyed[orig.Fingerprint] = fp
+				r.Fingerprints++
+			}
+			redetect(&keep)
+			if keep.Type != orig.Type {
+				r.Types++
+			}
+			if !sameItem(keep, orig) {
+				changed = append(changed, keep)
+			}
+		}
+
+		// Park changed fingerprints on unique placeholders first: the
+		// new fingerprint of one item may still be the old one of
+		// another.
+		for _, it := range changed {
+			if _, err := tx.ExecContext(ctx, `UPDATE items SET fingerprint='~'||id WHERE id=?`, it.ID); err != nil {
+				return err
+			}
+		}
+		for _, it := range changed {
+			meta, err := encodeMeta(it.Meta)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+UPDATE items
+SET fingerprint=?, type=?, meta=?, created_at=?, last_seen_at=?, pinned=?, title=?, note=?, deleted_at=?, expires_at=?
+WHERE id=?
+`, it.Fingerprint, string(it.Type), meta, it.CreatedAt.UnixMilli(), it.LastSeenAt.UnixMilli(),
+				boolToInt(it.Pinned), it.Title, it.Note, timeToMilli(it.DeletedAt), timeToMilli(it.ExpiresAt), it.ID); err != nil {
+				return err
+			}
+		}
+		return requeue(ctx, tx, rekeyed)
+	})
+	return r, err
+}
+
+func allItems(ctx context.Context, tx *sql.Tx) ([]core.Item, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+itemColumns+` FROM items ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// mergeInto folds dup into keep and deletes it. keep's fields are only
+// updated in memory; its links and sighting count are moved in tx.
+func mergeInto(ctx context.Context, tx *sql.Tx, keep *core.Item, dup core.Item) error {
+	if dup.CreatedAt.Before(keep.CreatedAt) {
+		keep.CreatedAt = dup.CreatedAt
+	}
+	if dup.LastSeenAt.After(keep.LastSeenAt) {
+		keep.LastSeenAt = dup.LastSeenAt
+	}
+	keep.Pinned = keep.Pinned || dup.Pinned
+	if keep.Title == "" {
+		keep.Title = dup.Title
+	}
+	if keep.Note == "" {
+		keep.Note = dup.Note
+	}
+	// trashed only if every copy was
+	if dup.DeletedAt.IsZero() {
+		keep.DeletedAt = dup.DeletedAt
+	}
+	// permanent if any copy was, as when a capture repeats it
+	if dup.ExpiresAt.IsZero() || !keep.ExpiresAt.IsZero() && dup.ExpiresAt.After(keep.ExpiresAt) {
+		keep.ExpiresAt = dup.ExpiresAt
+	}
+
+	for _, q := range []string{
+		`UPDATE items SET seen_count=seen_count+(SELECT seen_count FROM items WHERE id=?2) WHERE id=?1`,
+		`INSERT OR IGNORE INTO item_tags(item_id, tag_id) SELECT ?, tag_id FROM item_tags WHERE item_id=?`,
+		`INSERT OR IGNORE INTO collection_items(collection_id, item_id, added_at) SELECT collection_id, ?, added_at FROM collection_items WHERE item_id=?`,
+		// appended after keep's own revisions, in their original order
+		`INSERT INTO item_revisions(item_id, rev, content, replaced_at)
+SELECT ?1, (SELECT COALESCE(MAX(rev), 0) FROM item_revisions WHERE item_id=?1) + ROW_NUMBER() OVER (ORDER BY rev), content, replaced_at
+FROM item_revisions WHERE item_id=?2`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, keep.ID, dup.ID); err != nil {
+			return err
+		}
+	}
+	return deleteItem(ctx, tx, dup.ID)
+}
+
+// redetect sets it.Type to what detection says now. Captured content is
+// stored normalized, which loses the line structure some detectors need
+// (the original type was detected on the raw clip, or set by an app
+// rule), so a specific type is never downgraded to plain text. Items
+// that keep their type get a missing language filled in.
+func redetect(it *core.Item) {
+	typ := core.DetectType(it.Content)
+	if typ == core.ContentTypeText && it.Type != core.ContentTypeText {
+		if _, ok := core.ParseContentType(string(it.Type)); ok {
+			typ = it.Type
+		}
+	}
+	if typ == it.Type && it.Meta[core.MetaLanguage] != "" {
+		return
+	}
+	lang := core.ItemLanguage(typ, it.Content)
+	if typ == it.Type && lang == "" {
+		return
+	}
+	it.Meta = maps.Clone(it.Meta)
+	delete(it.Meta, core.MetaLanguage)
+	if lang != "" {
+		if it.Meta == nil {
+			it.Meta = make(map[string]string)
+		}
+		it.Meta[core.MetaLanguage] = string(lang)
+	}
+	it.Type = typ
+}
+
+func sameItem(a, b core.Item) bool {
+	return a.Fingerprint == b.Fingerprint && a.Type == b.Type && maps.Equal(a.Meta, b.Meta) &&
+		a.CreatedAt.Equal(b.CreatedAt) && a.LastSeenAt.Equal(b.LastSeenAt) && a.Pinned == b.Pinned &&
+		a.Title == b.Title && a.Note == b.Note && a.DeletedAt.Equal(b.DeletedAt) && a.ExpiresAt.Equal(b.ExpiresAt)
+}
+
+// requeue points paste queue entries at their items' new fingerprints.
+// Entries are updated one by one because the mapping can chain (a's new
+// fingerprint is b's old one).
+func requeue(ctx context.Context, tx *sql.Tx, rekeyed map[string]string) error {
+	if len(rekeyed) == 0 {
+		return nil
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT seq, fingerprint FROM paste_queue`)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		seq int64
+		fp  string
+	}
+	var moves []entry
+	for rows.Next() {
+		var e entry
+		if err := rows.Scan(&e.seq, &e.fp); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if fp, ok := rekeyed[e.fp]; ok && fp != e.fp {
+			moves = append(moves, entry{e.seq, fp})
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for _, e := range moves {
+		if _, err := tx.ExecContext(ctx, `UPDATE paste_queue SET fingerprint=? WHERE seq=?`, e.fp, e.seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size returns the size of the database in bytes, free pages included.
+func (s *Store) Size(ctx context.Context) (int64, error) {
+	var pages, pageSize int64
+	if err := s.db.QueryRowContext(ctx, `PRAGMA page_count`).Scan(&pages); err != nil {
+		return 0, err
+	}
+	if err := s.db.QueryRowContext(ctx, `PRAGMA page_size`).Scan(&pageSize); err != nil {
+		return 0, err
+	}
+	return pages * pageSize, nil
+}
+
+// Vacuum rebuilds the database file, returning the space deleted rows
+// left behind to the filesystem.
+func (s *Store) Vacuum(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `VACUUM`)
+	return err
+}
+
+// inTxUnless runs fn in a transaction like inTx, but rolls it back
+// instead of committing when dryRun is set.
+func (s *Store) inTxUnless(ctx context.Context, dryRun bool, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil || dryRun {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
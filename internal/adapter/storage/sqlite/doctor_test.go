@@ -0,0 +1,263 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_Refingerprint(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	t0 := time.UnixMilli(1_700_000_000_000)
+	put := func(id, content string, typ core.ContentType, fp string, created, seen time.Duration) {
+		t.Helper()
+		it := core.Item{ID: id, Content: content, Type: typ, Fingerprint: fp,
+			CreatedAt: t0.Add(created), LastSeenAt: t0.Add(seen)}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// stale fingerprints from older normalization rules
+	put("old", "hello   world", core.ContentTypeText, "stale-old", time.Hour, 2*time.Hour)
+	put("new", "hello world", core.ContentTypeText, core.Fingerprint("hello world"), 0, 3*time.Hour)
+	put("url", "https://example.com", core.ContentTypeText, "stale-url", 0, 0)
+	// detected on the raw clip; the normalized content no longer looks like YAML
+	put("yaml", "name: otter kind: animal", core.ContentTypeYAML, core.Fingerprint("name: otter kind: animal"), 0, time.Minute)
+	// a new fingerprint that is another item's old one
+	put("swap", "https://example.org", core.ContentTypeURL, "stale-swap", 0, 0)
+	put("swap2", "https://example.net", core.ContentTypeURL, core.Fingerprint("https://example.org"), 0, 0)
+
+	if err := st.SetPinned(ctx, "old", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetTitle(ctx, "old", "greeting"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AddTags(ctx, "old", "demo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AddToCollection(ctx, "starters", "old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Put(ctx, core.Item{ID: "old", Content: "hello   world ", Type: core.ContentTypeText,
+		Fingerprint: "stale-old", LastSeenAt: t0.Add(2 * time.Hour), Title: "greeting"}, storage.PutMerge); err != nil {
+		t.Fatal(err)
+	}
+	for _, fp := range []string{"stale-url", "stale-old"} {
+		if err := st.QueuePush(ctx, fp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dry, err := st.Refingerprint(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.FindByFingerprint(ctx, "stale-url"); err != nil {
+		t.Fatalf("dry run changed the database: %v", err)
+	}
+
+	r, err := st.Refingerprint(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dry, r) {
+		t.Fatalf("dry run reported %+v, real run %+v", dry, r)
+	}
+	want := RefingerprintReport{
+		Fingerprints: 3, // url, swap, swap2
+		Types:        1, // url
+		Merges:       []Merge{{Into: "new", From: []string{"old"}}},
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Fatalf("report = %+v, want %+v", r, want)
+	}
+
+	merged, err := st.FindByFingerprint(ctx, core.Fingerprint("hello world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID != "new" || !merged.Pinned || merged.Title != "greeting" || !merged.CreatedAt.Equal(t0) ||
+		!merged.LastSeenAt.Equal(t0.Add(3*time.Hour)) || !reflect.DeepEqual(merged.Tags, []string{"demo"}) {
+		t.Fatalf("merged = %+v", merged)
+	}
+	var seen int
+	if err := st.db.QueryRowContext(ctx, `SELECT seen_count FROM items WHERE id='new'`).Scan(&seen); err != nil || seen != 2 {
+		t.Fatalf("seen_count = %d, %v", seen, err)
+	}
+	if revs, _ := st.ListRevisions(ctx, "new"); len(revs) != 1 || revs[0].Content != "hello   world" {
+		t.Fatalf("revisions = %+v", revs)
+	}
+	if items, _ := st.CollectionItems(ctx, "starters"); len(items) != 1 || items[0].ID != "new" {
+		t.Fatalf("collection = %+v", items)
+	}
+
+	url, err := st.FindByFingerprint(ctx, core.Fingerprint("https://example.com"))
+	if err != nil || url.ID != "url" || url.Type != core.ContentTypeURL {
+		t.Fatalf("url = %+v, %v", url, err)
+	}
+	if it, err := st.FindByFingerprint(ctx, core.Fingerprint("https://example.net")); err != nil || it.ID != "swap2" {
+		t.Fatalf("swap2 = %+v, %v", it, err)
+	}
+	if it, _ := st.FindByFingerprint(ctx, core.Fingerprint("name: otter kind: animal")); it.Type != core.ContentTypeYAML {
+		t.Fatalf("yaml downgraded to %q", it.Type)
+	}
+
+	queued, err := st.QueueList(ctx)
+	if err != nil || len(queued) != 2 || queued[0].ID != "url" || queued[1].ID != "new" {
+		t.Fatalf("queue = %+v, %v", queued, err)
+	}
+
+	// a second run finds nothing to do
+	if again, err := st.Refingerprint(ctx, false); err != nil || !reflect.DeepEqual(again, RefingerprintReport{}) {
+		t.Fatalf("second run = %+v, %v", again, err)
+	}
+}
+
+func TestSQLiteStore_RefingerprintKeepsPermanent(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	t0 := time.UnixMilli(1_700_000_000_000)
+	put := func(id, content, fp string, seen, expires time.Duration) {
+		t.Helper()
+		it := core.Item{ID: id, Content: content, Type: core.ContentTypeText, Fingerprint: fp,
+			CreatedAt: t0, LastSeenAt: t0.Add(seen)}
+		if expires != 0 {
+			it.ExpiresAt = t0.Add(expires)
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// kept permanently, then copied again concealed
+	put("kept", "my   recovery code", "stale-kept", 0, 0)
+	put("concealed", "my recovery code", core.Fingerprint("my recovery code"), time.Hour, 2*time.Hour)
+	// concealed both times: the later expiry wins
+	put("first", "one   time", "stale-first", 2*time.Hour, 5*time.Hour)
+	put("second", "one time", core.Fingerprint("one time"), 3*time.Hour, 4*time.Hour)
+
+	if _, err := st.Refingerprint(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if it, err := st.FindByFingerprint(ctx, core.Fingerprint("my recovery code")); err != nil || !it.ExpiresAt.IsZero() {
+		t.Fatalf("merged permanent item = %+v, %v", it, err)
+	}
+	if it, err := st.FindByFingerprint(ctx, core.Fingerprint("one time")); err != nil || !it.ExpiresAt.Equal(t0.Add(5*time.Hour)) {
+		t.Fatalf("merged concealed item = %+v, %v", it, err)
+	}
+}
+
+func TestSQLiteStore_RemoveOrphans(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.db")
+	st, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	it := core.Item{ID: "a", Content: "a", Type: core.ContentTypeText, Fingerprint: core.Fingerprint("a"), CreatedAt: now, LastSeenAt: now}
+	if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AddTags(ctx, "a", "kept"); err != nil {
+		t.Fatal(err)
+	}
+
+	// foreign keys are off on a plain connection, as they were for
+	// older versions
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	if _, err := raw.Exec(`
+INSERT INTO tags(name) VALUES ('unused');
+INSERT INTO item_tags(item_id, tag_id) VALUES ('gone', 1);
+INSERT INTO collections(name, created_at) VALUES ('c', 0);
+INSERT INTO collection_items(collection_id, item_id, added_at) VALUES (1, 'gone', 0), (99, 'a', 0);
+INSERT INTO item_revisions(item_id, rev, content, replaced_at) VALUES ('gone', 1, 'x', 0);
+INSERT INTO paste_queue(fingerprint, added_at) VALUES ('nobody', 0);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Orphans{ItemTags: 1, CollectionItems: 2, Revisions: 1, QueueEntries: 1, Tags: 1}
+	for _, dryRun := range []bool{true, false} {
+		o, err := st.RemoveOrphans(ctx, dryRun)
+		if err != nil || o != want {
+			t.Fatalf("dry run %v: %+v, %v", dryRun, o, err)
+		}
+	}
+	if o, err := st.RemoveOrphans(ctx, false); err != nil || o.Total() != 0 {
+		t.Fatalf("after removal: %+v, %v", o, err)
+	}
+	if tags, _ := st.ListTags(ctx); len(tags) != 1 || tags[0].Name != "kept" {
+		t.Fatalf("tags = %+v", tags)
+	}
+}
+
+func TestSQLiteStore_OversizedVacuum(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	for id, content := range map[string]string{"big": strings.Repeat("é", core.MaxContentLen), "small": "small"} {
+		it := core.Item{ID: id, Content: content, Type: core.ContentTypeText, Fingerprint: core.Fingerprint(content), CreatedAt: now, LastSeenAt: now}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := st.Oversized(ctx, core.MaxContentLen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ItemSize{{ID: "big", Bytes: 2 * core.MaxContentLen}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Oversized = %+v, want %+v", got, want)
+	}
+
+	if err := st.Delete(ctx, "big"); err != nil {
+		t.Fatal(err)
+	}
+	before, err := st.Size(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Vacuum(ctx); err != nil {
+		t.Fatal(err)
+	}
+	after, err := st.Size(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after >= before {
+		t.Fatalf("vacuum: %d -> %d bytes", before, after)
+	}
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func (s *Store) GetSetting(ctx context.Context, key string) (string, bool, error) {
+	var v string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key=?`, key).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, true, nil
+}
+
+func (s *Store) SetSetting(ctx context.Context, key, value string) error {
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO settings(key, value) VALUES(?, ?)
+ON CONFLICT(key) DO UPDATE SET value=excluded.value
+`, key, value)
+	return err
+}
+
+func (s *Store) QueuePush(ctx context.Context, fingerprint string) error {
+	if fingerprint == "" {
+		return errors.New("fingerprint required")
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO paste_queue(fingerprint, added_at) VALUES(?, ?)`,
+		fingerprint, s.now().UnixMilli())
+	return err
+}
+
+func (s *Store) QueueList(ctx context.Context) ([]core.Item, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+itemColumns+`
+FROM paste_queue q
+JOIN items ON items.fingerprint = q.fingerprint
+WHERE items.deleted_at = 0
+ORDER BY q.seq
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) QueuePop(ctx context.Context, newest bool) (core.Item, error) {
+	order := "ASC"
+	if newest {
+		order = "DESC"
+	}
+
+	var it core.Item
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		// entries whose item was deleted can never be served
+		if _, err := tx.ExecContext(ctx, `
+DELETE FROM paste_queue WHERE fingerprint NOT IN (SELECT fingerprint FROM items)
+`); err != nil {
+			return err
+		}
+
+		// trashed items stay queued in case they are restored
+		var seq int64
+		err := tx.QueryRowContext(ctx, `
+SELECT q.seq FROM paste_queue q
+JOIN items ON items.fingerprint = q.fingerprint AND items.deleted_at = 0
+ORDER BY q.seq `+order+` LIMIT 1
+`).Scan(&seq)
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrQueueEmpty
+		}
+		if err != nil {
+			return err
+		}
+
+		it, err = scanItem(tx.QueryRowContext(ctx, `
+SELECT `+itemColumns+`
+FROM paste_queue q JOIN items ON items.fingerprint = q.fingerprint
+WHERE q.seq=?
+`, seq))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM paste_queue WHERE seq=?`, seq)
+		return err
+	})
+	return it, err
+}
+
+func (s *Store) QueueClear(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM paste_queue`)
+	return err
+}
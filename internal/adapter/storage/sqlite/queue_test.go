@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_Settings(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	if _, ok, err := st.GetSetting(ctx, "queue.mode"); err != nil || ok {
+		t.Fatalf("expected unset setting, got ok=%v err=%v", ok, err)
+	}
+	for _, v := range []string{"fifo", "lifo"} {
+		if err := st.SetSetting(ctx, "queue.mode", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, ok, _ := st.GetSetting(ctx, "queue.mode"); !ok || v != "lifo" {
+		t.Fatalf("expected lifo, got %q (ok=%v)", v, ok)
+	}
+}
+
+func TestSQLiteStore_Queue(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	ids := map[string]string{}
+	for _, text := range []string{"one", "two", "three"} {
+		it := core.Item{
+			ID:          uuid.NewString(),
+			Content:     text,
+			Type:        core.ContentTypeText,
+			Fingerprint: core.Fingerprint(text),
+			CreatedAt:   now,
+			LastSeenAt:  now,
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.QueuePush(ctx, it.Fingerprint); err != nil {
+			t.Fatal(err)
+		}
+		ids[text] = it.ID
+	}
+
+	list, err := st.QueueList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Content != "one" {
+		t.Fatalf("unexpected queue order: %+v", list)
+	}
+
+	it, err := st.QueuePop(ctx, true)
+	if err != nil || it.Content != "three" {
+		t.Fatalf("expected newest pop to return three, got %q (%v)", it.Content, err)
+	}
+
+	// a deleted item is skipped rather than served
+	if err := st.Delete(ctx, ids["one"]); err != nil {
+		t.Fatal(err)
+	}
+	it, err = st.QueuePop(ctx, false)
+	if err != nil || it.Content != "two" {
+		t.Fatalf("expected oldest pop to return two, got %q (%v)", it.Content, err)
+	}
+
+	if _, err := st.QueuePop(ctx, false); !errors.Is(err, storage.ErrQueueEmpty) {
+		t.Fatalf("expected ErrQueueEmpty, got %v", err)
+	}
+
+	if err := st.QueuePush(ctx, core.Fingerprint("two")); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.QueueClear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := st.QueueList(ctx); len(list) != 0 {
+		t.Fatalf("expected empty queue after clear, got %d", len(list))
+	}
+}
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func (s *Store) ListRevisions(ctx context.Context, itemID string) ([]core.Revision, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT rev, content, replaced_at FROM item_revisions WHERE item_id=? ORDER BY rev
+`, itemID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Revision
+	for rows.Next() {
+		r := core.Revision{ItemID: itemID}
+		var at int64
+		if err := rows.Scan(&r.Rev, &r.Content, &at); err != nil {
+			return nil, err
+		}
+		r.ReplacedAt = time.UnixMilli(at)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_MergeKeepsRevisions(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	put := func(content string) core.Item {
+		it := core.Item{
+			ID:          uuid.NewString(),
+			Content:     content,
+			Type:        core.ContentTypeText,
+			Fingerprint: core.Fingerprint(content),
+			CreatedAt:   now,
+			LastSeenAt:  now,
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+		return it
+	}
+	it := put("teh draft")
+	other := put("other")
+
+	for _, c := range []string{"the draft", "the final"} {
+		it.Content, it.Fingerprint = c, core.Fingerprint(c)
+		if err := st.Put(ctx, it, storage.PutMerge); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// merging without a content change (e.g. last_seen_at) adds nothing
+	it.LastSeenAt = now.Add(time.Minute)
+	if err := st.Put(ctx, it, storage.PutMerge); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := st.ListRevisions(ctx, it.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Rev != 1 || revs[0].Content != "teh draft" || revs[1].Content != "the draft" {
+		t.Fatalf("unexpected revisions: %+v", revs)
+	}
+	got, err := st.FindByFingerprint(ctx, core.Fingerprint("the final"))
+	if err != nil || got.ID != it.ID {
+		t.Fatalf("expected item under new fingerprint, got %+v (%v)", got, err)
+	}
+
+	dup := other
+	dup.Content, dup.Fingerprint = "the final", it.Fingerprint
+	if err := st.Put(ctx, dup, storage.PutMerge); !errors.Is(err, storage.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate, got %v", err)
+	}
+	missing := it
+	missing.ID = "nope"
+	if err := st.Put(ctx, missing, storage.PutMerge); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if err := st.Delete(ctx, it.ID); err != nil {
+		t.Fatal(err)
+	}
+	if revs, _ := st.ListRevisions(ctx, it.ID); len(revs) != 0 {
+		t.Fatalf("expected revisions removed with item, got %d", len(revs))
+	}
+}
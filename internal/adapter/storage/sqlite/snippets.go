@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+var ErrSnippetNotFound = errors.New("snippet not found")
+
+// PutSnippet creates or replaces a snippet, keeping the original CreatedAt.
+func (s *Store) PutSnippet(ctx context.Context, sn core.Snippet) error {
+	if sn.Name == "" {
+		return errors.New("snippet name required")
+	}
+	if sn.Body == "" {
+		return errors.New("empty snippet body")
+	}
+	now := s.now()
+	if sn.CreatedAt.IsZero() {
+		sn.CreatedAt = now
+	}
+	if sn.UpdatedAt.IsZero() {
+		sn.UpdatedAt = now
+	}
+	_, err := s.db.ExecContext(ctx, `
+INSERT INTO snippets(name, body, created_at, updated_at)
+VALUES(?, ?, ?, ?)
+ON CONFLICT(name) DO UPDATE SET
+  body=excluded.body,
+  updated_at=excluded.updated_at
+`, sn.Name, sn.Body, sn.CreatedAt.UnixMilli(), sn.UpdatedAt.UnixMilli())
+	return err
+}
+
+func (s *Store) GetSnippet(ctx context.Context, name string) (core.Snippet, error) {
+	sn, err := scanSnippet(s.db.QueryRowContext(ctx, `
+SELECT name, body, created_at, updated_at FROM snippets WHERE name=?
+`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Snippet{}, ErrSnippetNotFound
+	}
+	return sn, err
+}
+
+func (s *Store) ListSnippets(ctx context.Context) ([]core.Snippet, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT name, body, created_at, updated_at FROM snippets ORDER BY name
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Snippet
+	for rows.Next() {
+		sn, err := scanSnippet(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sn)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) DeleteSnippet(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM snippets WHERE name=?`, name)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSnippetNotFound
+	}
+	return nil
+}
+
+func scanSnippet(r rowScanner) (core.Snippet, error) {
+	var sn core.Snippet
+	var cAt, uAt int64
+	if err := r.Scan(&sn.Name, &sn.Body, &cAt, &uAt); err != nil {
+		return core.Snippet{}, err
+	}
+	sn.CreatedAt = time.UnixMilli(cAt)
+	sn.UpdatedAt = time.UnixMilli(uAt)
+	return sn, nil
+}
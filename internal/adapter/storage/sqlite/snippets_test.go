@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_Snippets(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	if err := st.PutSnippet(ctx, core.Snippet{Name: "logs", Body: "kubectl -n {{input:ns}} logs"}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := st.GetSnippet(ctx, "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := st.PutSnippet(ctx, core.Snippet{Name: "logs", Body: "kubectl -n {{input:ns}} logs -f"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.GetSnippet(ctx, "logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Body != "kubectl -n {{input:ns}} logs -f" {
+		t.Fatalf("expected body replaced, got %q", got.Body)
+	}
+	if !got.CreatedAt.Equal(first.CreatedAt) {
+		t.Fatalf("expected created_at preserved")
+	}
+
+	list, err := st.ListSnippets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Fatalf("expected 1 snippet, got %d", len(list))
+	}
+
+	// snippets are not history
+	if n, _ := st.Count(ctx); n != 0 {
+		t.Fatalf("expected no history items, got %d", n)
+	}
+
+	if err := st.DeleteSnippet(ctx, "logs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.GetSnippet(ctx, "logs"); !errors.Is(err, ErrSnippetNotFound) {
+		t.Fatalf("expected ErrSnippetNotFound, got %v", err)
+	}
+}
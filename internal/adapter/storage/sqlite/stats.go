@@ -0,0 +1,265 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// StatsOptions shapes what Stats reports.
+type StatsOptions struct {
+	// Since starts the window the counters, days and hours cover; the
+	// zero time covers everything. Totals always cover the whole history.
+	Since time.Time
+	// Top is how many items MostCopied and Largest list (default 10).
+	Top int
+	// Location is the time zone days and hours are counted in (default
+	// time.Local). Counters are kept per UTC hour, so zones with a
+	// half-hour offset are approximate.
+	Location *time.Location
+}
+
+// Stats summarises how the clipboard history is used.
+type Stats struct {
+	Since time.Time `json:"since,omitzero"`
+
+	Items        int         `json:"items"`
+	Pinned       int         `json:"pinned"`
+	Trashed      int         `json:"trashed"`
+	ContentBytes int64       `json:"content_bytes"`
+	DBBytes      int64       `json:"db_bytes"`
+	Types        []TypeCount `json:"types"`
+
+	// Counters holds the window's totals of the core.Count* counters.
+	// DedupeRate is the share of copies that repeated an item already in
+	// history.
+	Counters   map[string]int `json:"counters"`
+	DedupeRate float64        `json:"dedupe_rate"`
+
+	Days  []DayStats    `json:"days"`
+	Hours [24]HourStats `json:"hours"`
+
+	MostCopied []ItemCount `json:"most_copied"`
+	Largest    []ItemSize  `json:"largest"`
+}
+
+// TypeCount is how many live items have a content type.
+type TypeCount struct {
+	Type  core.ContentType `json:"type"`
+	Count int              `json:"count"`
+}
+
+// DayStats and HourStats count copies (clips stored or deduped, from the
+// counters) and items added (by CreatedAt, imports included) per calendar
+// day and per hour of the day.
+type DayStats struct {
+	Day    string `json:"day"` // 2006-01-02
+	Copies int    `json:"copies"`
+	Added  int    `json:"added"`
+}
+
+type HourStats struct {
+	Hour   int `json:"hour"`
+	Copies int `json:"copies"`
+	Added  int `json:"added"`
+}
+
+// ItemCount is an item with how many times it was captured.
+type ItemCount struct {
+	Item  core.Item `json:"item"`
+	Count int       `json:"count"`
+}
+
+const dayLayout = "2006-01-02"
+
+// Stats runs the aggregate queries behind `otterclipctl stats`.
+func (s *Store) Stats(ctx context.Context, opts StatsOptions) (Stats, error) {
+	if opts.Top <= 0 {
+		opts.Top = 10
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	st := Stats{Since: opts.Since, Counters: make(map[string]int)}
+	for i := range st.Hours {
+		st.Hours[i].Hour = i
+	}
+
+	if err := s.db.QueryRowContext(ctx, `
+SELECT
+  COALESCE(SUM(deleted_at=0), 0),
+  COALESCE(SUM(deleted_at=0 AND pinned=1), 0),
+  COALESCE(SUM(deleted_at>0), 0),
+  COALESCE(SUM(CASE WHEN deleted_at=0 THEN length(CAST(content AS BLOB)) END), 0)
+FROM items
+`).Scan(&st.Items, &st.Pinned, &st.Trashed, &st.ContentBytes); err != nil {
+		return Stats{}, err
+	}
+	var err error
+	if st.DBBytes, err = s.Size(ctx); err != nil {
+		return Stats{}, err
+	}
+	if st.Types, err = s.typeCounts(ctx); err != nil {
+		return Stats{}, err
+	}
+
+	days := make(map[string]*DayStats)
+	day := func(t time.Time) *DayStats {
+		key := t.In(loc).Format(dayLayout)
+		if days[key] == nil {
+			days[key] = &DayStats{Day: key}
+		}
+		return days[key]
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT name, hour, value FROM counters WHERE hour >= ?
+`, unixHour(opts.Since))
+	if err != nil {
+		return Stats{}, err
+	}
+	for rows.Next() {
+		var name string
+		var hour int64
+		var n int
+		if err := rows.Scan(&name, &hour, &n); err != nil {
+			_ = rows.Close()
+			return Stats{}, err
+		}
+		st.Counters[name] += n
+		if name == core.CountStored || name == core.CountDeduped {
+			t := time.Unix(hour, 0)
+			day(t).Copies += n
+			st.Hours[t.In(loc).Hour()].Copies += n
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return Stats{}, err
+	}
+
+	rows, err = s.db.QueryContext(ctx, `
+SELECT created_at / 3600000, COUNT(1) FROM items WHERE created_at >= ? GROUP BY 1
+`, opts.Since.UnixMilli())
+	if err != nil {
+		return Stats{}, err
+	}
+	for rows.Next() {
+		var hour int64
+		var n int
+		if err := rows.Scan(&hour, &n); err != nil {
+			_ = rows.Close()
+			return Stats{}, err
+		}
+		t := time.Unix(hour*3600, 0)
+		day(t).Added += n
+		st.Hours[t.In(loc).Hour()].Added += n
+	}
+	if err := rows.Close(); err != nil {
+		return Stats{}, err
+	}
+	st.Days = dayRange(days, opts.Since, s.now(), loc)
+
+	if copies := st.Counters[core.CountStored] + st.Counters[core.CountDeduped]; copies > 0 {
+		st.DedupeRate = float64(st.Counters[core.CountDeduped]) / float64(copies)
+	}
+	if st.MostCopied, err = s.mostCopied(ctx, opts.Top); err != nil {
+		return Stats{}, err
+	}
+	if st.Largest, err = s.itemSizes(ctx, `deleted_at=0`, opts.Top); err != nil {
+		return Stats{}, err
+	}
+	return st, nil
+}
+
+// dayRange lists every day from since (or the first day with data) to
+// now, so quiet days show up as zeros.
+func dayRange(days map[string]*DayStats, since, now time.Time, loc *time.Location) []DayStats {
+	first := now.In(loc).Format(dayLayout)
+	if !since.IsZero() {
+		first = since.In(loc).Format(dayLayout)
+	} else {
+		for k := range days {
+			first = min(first, k)
+		}
+	}
+	start, err := time.ParseInLocation(dayLayout, first, loc)
+	if err != nil {
+		return nil
+	}
+	last := now.In(loc).Format(dayLayout)
+	var out []DayStats
+	for d := start; ; d = d.AddDate(0, 0, 1) {
+		key := d.Format(dayLayout)
+		if key > last {
+			break
+		}
+		if ds, ok := days[key]; ok {
+			out = append(out, *ds)
+		} else {
+			out = append(out, DayStats{Day: key})
+		}
+	}
+	return out
+}
+
+func (s *Store) typeCounts(ctx context.Context) ([]TypeCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT type, COUNT(1) FROM items WHERE deleted_at=0 GROUP BY type ORDER BY COUNT(1) DESC, type
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []TypeCount
+	for rows.Next() {
+		var tc TypeCount
+		var typ string
+		if err := rows.Scan(&typ, &tc.Count); err != nil {
+			return nil, err
+		}
+		tc.Type = core.ContentType(typ)
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+// mostCopied returns the live items captured more than once, most
+// captured first.
+func (s *Store) mostCopied(ctx context.Context, limit int) ([]ItemCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+itemColumns+`, items.seen_count
+FROM items
+WHERE deleted_at=0 AND seen_count > 1
+ORDER BY seen_count DESC, last_seen_at DESC
+LIMIT ?
+`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []ItemCount
+	for rows.Next() {
+		var ic ItemCount
+		it, err := scanItem(withCount{rows, &ic.Count})
+		if err != nil {
+			return nil, err
+		}
+		ic.Item = it
+		out = append(out, ic)
+	}
+	return out, rows.Err()
+}
+
+// withCount scans one extra trailing column into n.
+type withCount struct {
+	r rowScanner
+	n *int
+}
+
+func (w withCount) Scan(dest ...any) error {
+	return w.r.Scan(append(dest, w.n)...)
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_Stats(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+	st.now = func() time.Time { return at(19, 12, 0) }
+
+	put := func(id, content string, typ core.ContentType, created time.Time) {
+		t.Helper()
+		it := core.Item{ID: id, Content: content, Type: typ, Fingerprint: core.Fingerprint(content), CreatedAt: created, LastSeenAt: created}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("a", "a", core.ContentTypeText, at(18, 9, 30))
+	for range 2 { // copied three times
+		if err := st.AddSighting(ctx, core.Fingerprint("a")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range 2 { // saved twice, but not by capture
+		put("b", "b", core.ContentTypeText, at(19, 9, 10))
+	}
+	put("url", "https://x", core.ContentTypeURL, at(19, 11, 0))
+	put("old", "old", core.ContentTypeText, at(10, 8, 0))
+	if err := st.SetPinned(ctx, "b", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Trash(ctx, "old"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name string
+		at   time.Time
+		n    int
+	}{
+		{core.CountStored, at(18, 9, 30), 1},
+		{core.CountDeduped, at(18, 9, 45), 2},
+		{core.CountStored, at(19, 9, 10), 1},
+		{core.CountIgnoredPrivacy, at(19, 10, 0), 1},
+		{core.CountStored, at(1, 9, 0), 5}, // before the window
+	} {
+		if err := st.AddCount(ctx, c.name, c.at, c.n); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := st.Stats(ctx, StatsOptions{Since: at(17, 0, 0), Top: 2, Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Items != 3 || got.Pinned != 1 || got.Trashed != 1 || got.ContentBytes != int64(len("abhttps://x")) || got.DBBytes <= 0 {
+		t.Fatalf("totals = %+v", got)
+	}
+	if want := []TypeCount{{core.ContentTypeText, 2}, {core.ContentTypeURL, 1}}; !reflect.DeepEqual(got.Types, want) {
+		t.Fatalf("types = %+v", got.Types)
+	}
+	wantCounters := map[string]int{core.CountStored: 2, core.CountDeduped: 2, core.CountIgnoredPrivacy: 1}
+	if !reflect.DeepEqual(got.Counters, wantCounters) || got.DedupeRate != 0.5 {
+		t.Fatalf("counters = %v, dedupe rate %v", got.Counters, got.DedupeRate)
+	}
+	wantDays := []DayStats{{"2026-10-17", 0, 0}, {"2026-10-18", 3, 1}, {"2026-10-19", 1, 2}}
+	if !reflect.DeepEqual(got.Days, wantDays) {
+		t.Fatalf("days = %+v", got.Days)
+	}
+	if got.Hours[9] != (HourStats{Hour: 9, Copies: 4, Added: 2}) || got.Hours[11] != (HourStats{Hour: 11, Added: 1}) || got.Hours[8] != (HourStats{Hour: 8}) {
+		t.Fatalf("hours = %+v", got.Hours)
+	}
+	if err := st.AddSighting(ctx, core.Fingerprint("nope")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("AddSighting of a missing item: %v", err)
+	}
+	if len(got.MostCopied) != 1 || got.MostCopied[0].Item.ID != "a" || got.MostCopied[0].Count != 3 {
+		t.Fatalf("most copied = %+v", got.MostCopied)
+	}
+	if want := []ItemSize{{"url", 9}, {"a", 1}}; !reflect.DeepEqual(got.Largest, want) {
+		t.Fatalf("largest = %+v", got.Largest)
+	}
+
+	// all time starts at the first day with data
+	all, err := st.Stats(ctx, StatsOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Days) != 19 || all.Days[0] != (DayStats{"2026-10-01", 5, 0}) || all.Counters[core.CountStored] != 7 {
+		t.Fatalf("all time: %d days from %+v, counters %v", len(all.Days), all.Days[0], all.Counters)
+	}
+}
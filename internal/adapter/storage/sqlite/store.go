@@ -3,7 +3,10 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -12,6 +15,9 @@ import (
 	"github.com/its-jojoo/otterclip/internal/core"
 )
 
+// ErrNotFound is storage.ErrNotFound, kept for callers of this package.
+var ErrNotFound = storage.ErrNotFound
+
 type Store struct {
 	db  *sql.DB
 	now func() time.Time
@@ -72,8 +78,119 @@ WHERE id IN (
 
 -- Enforce global dedupe
 CREATE UNIQUE INDEX IF NOT EXISTS uq_items_fingerprint ON items(fingerprint);
+
+CREATE TABLE IF NOT EXISTS tags (
+  id   INTEGER PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS item_tags (
+  item_id TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  tag_id  INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+  PRIMARY KEY (item_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_item_tags_tag ON item_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS collections (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  name       TEXT NOT NULL UNIQUE,
+  created_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS collection_items (
+  collection_id INTEGER NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+  item_id       TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  added_at      INTEGER NOT NULL,
+  PRIMARY KEY (collection_id, item_id)
+);
+CREATE INDEX IF NOT EXISTS idx_collection_items_item ON collection_items(item_id);
+
+CREATE TABLE IF NOT EXISTS snippets (
+  name       TEXT PRIMARY KEY,
+  body       TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS settings (
+  key   TEXT PRIMARY KEY,
+  value TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS item_revisions (
+  item_id     TEXT NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+  rev         INTEGER NOT NULL,
+  content     TEXT NOT NULL,
+  replaced_at INTEGER NOT NULL,
+  PRIMARY KEY (item_id, rev)
+);
+
+CREATE TABLE IF NOT EXISTS paste_queue (
+  seq         INTEGER PRIMARY KEY AUTOINCREMENT,
+  fingerprint TEXT NOT NULL,
+  added_at    INTEGER NOT NULL
+);
+
+-- hour is the Unix time of the start of the hour, in seconds
+CREATE TABLE IF NOT EXISTS counters (
+  name  TEXT NOT NULL,
+  hour  INTEGER NOT NULL,
+  value INTEGER NOT NULL,
+  PRIMARY KEY (name, hour)
+);
 `)
-	return err
+	if err != nil {
+		return err
+	}
+
+	// Columns added after the initial schema. CREATE TABLE IF NOT EXISTS
+	// won't touch existing databases, so add them one by one.
+	return s.addColumns("items", []column{
+		{"source_app", "TEXT NOT NULL DEFAULT ''"},
+		{"source_class", "TEXT NOT NULL DEFAULT ''"},
+		{"source_process", "TEXT NOT NULL DEFAULT ''"},
+		{"expires_at", "INTEGER NOT NULL DEFAULT 0"},
+		{"meta", "TEXT NOT NULL DEFAULT ''"},
+		{"title", "TEXT NOT NULL DEFAULT ''"},
+		{"note", "TEXT NOT NULL DEFAULT ''"},
+		{"deleted_at", "INTEGER NOT NULL DEFAULT 0"},
+		// how many times the content was captured
+		{"seen_count", "INTEGER NOT NULL DEFAULT 1"},
+	})
+}
+
+type column struct {
+	name string
+	decl string
+}
+
+func (s *Store) addColumns(table string, cols []column) error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		have[name] = true
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	for _, c := range cols {
+		if have[c.name] {
+			continue
+		}
+		if _, err := s.db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + c.name + ` ` + c.decl); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Store) Put(ctx context.Context, item core.Item, mode storage.PutMode) error {
@@ -87,43 +204,110 @@ func (s *Store) Put(ctx context.Context, item core.Item, mode storage.PutMode) e
 		return errors.New("fingerprint required")
 	}
 
+	meta, err := encodeMeta(item.Meta)
+	if err != nil {
+		return err
+	}
+
 	switch mode {
 	case storage.PutInsert:
 		// Upsert by fingerprint:
 		// - If new: insert.
-		// - If exists: update content/type/last_seen_at, preserve created_at and pinned.
-		_, err := s.db.ExecContext(ctx, `
-INSERT INTO items(id, content, type, fingerprint, created_at, last_seen_at, pinned)
-VALUES(?, ?, ?, ?, ?, ?, ?)
+		// - If exists: update content/type/last_seen_at, preserve created_at and pinned,
+		//   and keep title/note unless the new item carries its own.
+		// - If it was trashed, copying it again restores it.
+		// - A permanent item stays permanent: a concealed copy of content
+		//   already in history must not schedule the original for purging.
+		// Repeat sightings are counted by AddSighting, not here.
+		// RETURNING gives us the surviving row's id when the fingerprint
+		// already existed, so tags land on the right item.
+		var id string
+		err := s.db.QueryRowContext(ctx, `
+INSERT INTO items(id, content, type, fingerprint, created_at, last_seen_at, pinned,
+                  source_app, source_class, source_process, expires_at, meta, title, note)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(fingerprint) DO UPDATE SET
   content=excluded.content,
   type=excluded.type,
-  last_seen_at=excluded.last_seen_at
+  last_seen_at=excluded.last_seen_at,
+  source_app=excluded.source_app,
+  source_class=excluded.source_class,
+  source_process=excluded.source_process,
+  expires_at=CASE WHEN items.expires_at=0 THEN 0 ELSE excluded.expires_at END,
+  meta=excluded.meta,
+  title=COALESCE(NULLIF(excluded.title, ''), items.title),
+  note=COALESCE(NULLIF(excluded.note, ''), items.note),
+  deleted_at=0
+RETURNING id
 `, item.ID, item.Content, string(item.Type), item.Fingerprint,
-			item.CreatedAt.UnixMilli(), item.LastSeenAt.UnixMilli(), boolToInt(item.Pinned))
-		return err
+			item.CreatedAt.UnixMilli(), item.LastSeenAt.UnixMilli(), boolToInt(item.Pinned),
+			item.Source.AppID, item.Source.WindowClass, item.Source.Process, timeToMilli(item.ExpiresAt), meta,
+			item.Title, item.Note).Scan(&id)
+		if err != nil {
+			return err
+		}
+		if len(item.Tags) > 0 {
+			return s.AddTags(ctx, id, item.Tags...)
+		}
+		return nil
 
 	case storage.PutMerge:
-		_, err := s.db.ExecContext(ctx, `
-UPDATE items
-SET content=?, type=?, fingerprint=?, last_seen_at=?
-WHERE id=?
-`, item.Content, string(item.Type), item.Fingerprint, item.LastSeenAt.UnixMilli(), item.ID)
-		return err
+		return s.inTx(ctx, func(tx *sql.Tx) error {
+			return s.merge(ctx, tx, item, meta)
+		})
 
 	default:
 		return errors.New("unknown put mode")
 	}
 }
 
+// merge updates an item in place, keeping the replaced content as a
+// revision when it changes.
+func (s *Store) merge(ctx context.Context, tx *sql.Tx, item core.Item, meta string) error {
+	var prev string
+	err := tx.QueryRowContext(ctx, `SELECT content FROM items WHERE id=?`, item.ID).Scan(&prev)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	var other string
+	err = tx.QueryRowContext(ctx, `SELECT id FROM items WHERE fingerprint=? AND id<>?`, item.Fingerprint, item.ID).Scan(&other)
+	if err == nil {
+		return storage.ErrDuplicate
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if prev != item.Content {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO item_revisions(item_id, rev, content, replaced_at)
+SELECT ?, COALESCE(MAX(rev), 0) + 1, ?, ? FROM item_revisions WHERE item_id=?
+`, item.ID, prev, s.now().UnixMilli(), item.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+UPDATE items
+SET content=?, type=?, fingerprint=?, last_seen_at=?, meta=?, title=?, note=?
+WHERE id=?
+`, item.Content, string(item.Type), item.Fingerprint, item.LastSeenAt.UnixMilli(), meta, item.Title, item.Note, item.ID)
+	return err
+}
+
 func (s *Store) ListRecent(ctx context.Context, limit int) ([]core.Item, error) {
 	if limit <= 0 {
 		limit = 50
 	}
 
 	rows, err := s.db.QueryContext(ctx, `
-SELECT id, content, type, fingerprint, created_at, last_seen_at, pinned
+SELECT `+itemColumns+`
 FROM items
+WHERE deleted_at=0
 ORDER BY last_seen_at DESC
 LIMIT ?
 `, limit)
@@ -134,18 +318,118 @@ LIMIT ?
 
 	out := make([]core.Item, 0, limit)
 	for rows.Next() {
-		var it core.Item
-		var cAt, lsAt int64
-		var pinned int
-		var typ string
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
 
-		if err := rows.Scan(&it.ID, &it.Content, &typ, &it.Fingerprint, &cAt, &lsAt, &pinned); err != nil {
+func (s *Store) WalkRecent(ctx context.Context, limit int, fn func(core.Item) error) error {
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+itemColumns+`
+FROM items
+WHERE deleted_at=0
+ORDER BY last_seen_at DESC
+LIMIT ?
+`, limit)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(it); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// itemColumns is qualified so it can be used in joins; queries must select FROM items.
+const itemColumns = `items.id, items.content, items.type, items.fingerprint,
+       items.created_at, items.last_seen_at, items.pinned,
+       items.source_app, items.source_class, items.source_process,
+       items.expires_at, items.meta, items.title, items.note, items.deleted_at,
+       (SELECT COALESCE(group_concat(t.name, char(31)), '')
+        FROM item_tags it JOIN tags t ON t.id = it.tag_id
+        WHERE it.item_id = items.id) AS tags`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItem(r rowScanner) (core.Item, error) {
+	var it core.Item
+	var cAt, lsAt, expAt, delAt int64
+	var pinned int
+	var typ, meta, tags string
+
+	if err := r.Scan(&it.ID, &it.Content, &typ, &it.Fingerprint, &cAt, &lsAt, &pinned,
+		&it.Source.AppID, &it.Source.WindowClass, &it.Source.Process, &expAt, &meta, &it.Title, &it.Note, &delAt, &tags); err != nil {
+		return core.Item{}, err
+	}
+	if tags != "" {
+		it.Tags = strings.Split(tags, "\x1f")
+		sort.Strings(it.Tags)
+	}
+	if meta != "" {
+		if err := json.Unmarshal([]byte(meta), &it.Meta); err != nil {
+			return core.Item{}, err
+		}
+	}
+	it.Type = core.ContentType(typ)
+	it.CreatedAt = time.UnixMilli(cAt)
+	it.LastSeenAt = time.UnixMilli(lsAt)
+	it.Pinned = pinned == 1
+	it.ExpiresAt = milliToTime(expAt)
+	it.DeletedAt = milliToTime(delAt)
+	return it, nil
+}
+
+// FindByFingerprint returns the item with the given fingerprint.
+func (s *Store) FindByFingerprint(ctx context.Context, fp string) (core.Item, error) {
+	it, err := scanItem(s.db.QueryRowContext(ctx, `SELECT `+itemColumns+` FROM items WHERE fingerprint=?`, fp))
+	if errors.Is(err, sql.ErrNoRows) {
+		return core.Item{}, ErrNotFound
+	}
+	return it, err
+}
+
+func (s *Store) FindByIDPrefix(ctx context.Context, prefix string, limit int) ([]core.Item, error) {
+	if prefix == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+itemColumns+`
+FROM items
+WHERE substr(id, 1, ?) = ?
+ORDER BY id
+LIMIT ?
+`, len(prefix), prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
 			return nil, err
 		}
-		it.Type = core.ContentType(typ)
-		it.CreatedAt = time.UnixMilli(cAt)
-		it.LastSeenAt = time.UnixMilli(lsAt)
-		it.Pinned = pinned == 1
 		out = append(out, it)
 	}
 	return out, rows.Err()
@@ -156,17 +440,88 @@ func (s *Store) SetPinned(ctx context.Context, id string, pinned bool) error {
 	return err
 }
 
+func (s *Store) SetTitle(ctx context.Context, id string, title string) error {
+	return s.setText(ctx, "title", id, title)
+}
+
+func (s *Store) SetNote(ctx context.Context, id string, note string) error {
+	return s.setText(ctx, "note", id, note)
+}
+
+func (s *Store) setText(ctx context.Context, col, id, value string) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE items SET `+col+`=? WHERE id=?`, value, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 func (s *Store) Delete(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, `DELETE FROM items WHERE id=?`, id)
-	return err
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		return deleteItem(ctx, tx, id)
+	})
+}
+
+// deleteItem removes an item and everything attached to it. foreign_keys is
+// per connection, so don't rely on ON DELETE CASCADE.
+func deleteItem(ctx context.Context, tx *sql.Tx, id string) error {
+	for _, q := range []string{
+		`DELETE FROM item_tags WHERE item_id=?`,
+		`DELETE FROM collection_items WHERE item_id=?`,
+		`DELETE FROM item_revisions WHERE item_id=?`,
+		`DELETE FROM items WHERE id=?`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
 }
 
 func (s *Store) Count(ctx context.Context) (int, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM items`)
+	row := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM items WHERE deleted_at=0`)
 	var n int
 	return n, row.Scan(&n)
 }
 
+func encodeMeta(m map[string]string) (string, error) {
+	if len(m) == 0 {
+		return "", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+// timeToMilli stores the zero time as 0 so "unset" survives a round trip.
+func timeToMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+func milliToTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
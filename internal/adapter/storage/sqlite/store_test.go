@@ -2,7 +2,10 @@ package sqlite
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -113,3 +116,271 @@ func TestSQLiteStore_UpsertByFingerprint(t *testing.T) {
 		t.Fatalf("expected last_seen_at updated")
 	}
 }
+
+func TestSQLiteStore_UpsertKeepsPermanent(t *testing.T) {
+	dir := t.TempDir()
+	st, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	fp := core.Fingerprint("secret")
+	put := func(id string, expires time.Time) {
+		t.Helper()
+		it := core.Item{ID: id, Content: "secret", Type: core.ContentTypeText, Fingerprint: fp,
+			CreatedAt: now, LastSeenAt: now, ExpiresAt: expires}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	put("permanent", time.Time{})
+	put("concealed", now.Add(time.Minute))
+	it, err := st.FindByFingerprint(ctx, fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.ID != "permanent" || !it.ExpiresAt.IsZero() {
+		t.Fatalf("expected the permanent item to stay permanent, got %+v", it)
+	}
+
+	// an expiring item does pick up a newer expiry
+	fp = core.Fingerprint("otp")
+	for i, exp := range []time.Time{now.Add(time.Minute), now.Add(time.Hour)} {
+		it := core.Item{ID: "otp" + string(rune('0'+i)), Content: "otp", Type: core.ContentTypeText, Fingerprint: fp,
+			CreatedAt: now, LastSeenAt: now, ExpiresAt: exp}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if it, _ := st.FindByFingerprint(ctx, fp); it.ExpiresAt.UnixMilli() != now.Add(time.Hour).UnixMilli() {
+		t.Fatalf("expected expiry refreshed, got %v", it.ExpiresAt)
+	}
+}
+
+func TestSQLiteStore_SourceRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	db := filepath.Join(dir, "test.db")
+
+	st, err := Open(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	src := core.Source{AppID: "org.keepassxc.KeePassXC", WindowClass: "keepassxc", Process: "keepassxc"}
+
+	it := core.Item{
+		ID:          uuid.NewString(),
+		Content:     "hello world",
+		Type:        core.ContentTypeText,
+		Fingerprint: core.Fingerprint("hello world"),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		Source:      src,
+	}
+	if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := st.ListRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Source != src {
+		t.Fatalf("expected source %+v, got %+v", src, items)
+	}
+}
+
+func TestSQLiteStore_TitleAndNote(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	it := core.Item{
+		ID:          uuid.NewString(),
+		Content:     "kubectl -n prod logs -f deploy/api",
+		Type:        core.ContentTypeCommand,
+		Fingerprint: core.Fingerprint("kubectl -n prod logs -f deploy/api"),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+	}
+	if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetTitle(ctx, it.ID, "prod api logs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetNote(ctx, it.ID, "use when paging"); err != nil {
+		t.Fatal(err)
+	}
+
+	// copying the same content again must not wipe the annotations
+	again := it
+	again.ID = uuid.NewString()
+	again.LastSeenAt = now.Add(time.Minute)
+	if err := st.Put(ctx, again, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := st.FindByFingerprint(ctx, it.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "prod api logs" || got.Note != "use when paging" {
+		t.Fatalf("expected title and note kept, got %q / %q", got.Title, got.Note)
+	}
+
+	if err := st.SetTitle(ctx, "missing", "x"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSQLiteStore_MigratesOldSchema(t *testing.T) {
+	dir := t.TempDir()
+	db := filepath.Join(dir, "old.db")
+
+	raw, err := sql.Open("sqlite", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := raw.Exec(`
+CREATE TABLE items (
+  id           TEXT PRIMARY KEY,
+  content      TEXT NOT NULL,
+  type         TEXT NOT NULL,
+  fingerprint  TEXT NOT NULL,
+  created_at   INTEGER NOT NULL,
+  last_seen_at INTEGER NOT NULL,
+  pinned       INTEGER NOT NULL DEFAULT 0
+);
+INSERT INTO items VALUES ('a', 'old', 'text', 'fp-old', 1, 1, 1);
+`); err != nil {
+		t.Fatal(err)
+	}
+	_ = raw.Close()
+
+	st, err := Open(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	items, err := st.ListRecent(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Content != "old" || !items[0].Pinned {
+		t.Fatalf("expected old row to survive migration, got %+v", items)
+	}
+}
+
+func TestSQLiteStore_FindByIDPrefix(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, id := range []string{"abc12345-0000", "abc19999-0000", "def00000-0000"} {
+		it := core.Item{
+			ID:          id,
+			Content:     "content " + id,
+			Type:        core.ContentTypeText,
+			Fingerprint: core.Fingerprint(id),
+			CreatedAt:   now,
+			LastSeenAt:  now,
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := st.FindByIDPrefix(ctx, "abc1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected ambiguous prefix to match 2, got %d", len(got))
+	}
+
+	got, _ = st.FindByIDPrefix(ctx, "abc123", 10)
+	if len(got) != 1 || got[0].ShortID() != "abc1234" {
+		t.Fatalf("expected unique match abc1234, got %+v", got)
+	}
+
+	// trashed items are still found (restore needs them)
+	if err := st.Trash(ctx, "def00000-0000"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := st.FindByIDPrefix(ctx, "def0", 0); len(got) != 1 || got[0].DeletedAt.IsZero() {
+		t.Fatalf("expected trashed item found, got %+v", got)
+	}
+}
+
+func TestSQLiteStore_WalkRecent(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i, c := range []string{"oldest", "middle", "newest", "trashed"} {
+		it := core.Item{
+			ID:          c,
+			Content:     c,
+			Type:        core.ContentTypeText,
+			Fingerprint: core.Fingerprint(c),
+			CreatedAt:   now,
+			LastSeenAt:  now.Add(time.Duration(i) * time.Second),
+			Tags:        []string{"t"},
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.Trash(ctx, "trashed"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	err = st.WalkRecent(ctx, 0, func(it core.Item) error {
+		if len(it.Tags) != 1 {
+			t.Errorf("%s: tags = %v", it.ID, it.Tags)
+		}
+		got = append(got, it.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "newest middle oldest"; strings.Join(got, " ") != want {
+		t.Fatalf("walked %v, want %s", got, want)
+	}
+
+	stop := errors.New("stop")
+	n := 0
+	err = st.WalkRecent(ctx, 0, func(core.Item) error { n++; return stop })
+	if !errors.Is(err, stop) || n != 1 {
+		t.Fatalf("err = %v after %d items, want stop after 1", err, n)
+	}
+
+	got = got[:0]
+	_ = st.WalkRecent(ctx, 2, func(it core.Item) error { got = append(got, it.ID); return nil })
+	if len(got) != 2 {
+		t.Fatalf("limit 2 walked %v", got)
+	}
+}
@@ -0,0 +1,195 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+var ErrCollectionNotFound = errors.New("collection not found")
+
+func (s *Store) AddTags(ctx context.Context, itemID string, tags ...string) error {
+	tags = core.NormalizeTags(tags)
+	if len(tags) == 0 {
+		return nil
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, t := range tags {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO tags(name) VALUES(?) ON CONFLICT(name) DO NOTHING`, t); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO item_tags(item_id, tag_id)
+SELECT ?, id FROM tags WHERE name=?
+ON CONFLICT DO NOTHING
+`, itemID, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) RemoveTags(ctx context.Context, itemID string, tags ...string) error {
+	tags = core.NormalizeTags(tags)
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, t := range tags {
+			if _, err := tx.ExecContext(ctx, `
+DELETE FROM item_tags
+WHERE item_id=? AND tag_id=(SELECT id FROM tags WHERE name=?)
+`, itemID, t); err != nil {
+				return err
+			}
+		}
+		// drop tags nobody uses anymore
+		_, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM item_tags)`)
+		return err
+	})
+}
+
+func (s *Store) ListTags(ctx context.Context) ([]core.TagCount, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT t.name, COUNT(it.item_id)
+FROM tags t
+JOIN item_tags it ON it.tag_id = t.id
+JOIN items ON items.id = it.item_id AND items.deleted_at = 0
+GROUP BY t.id
+ORDER BY COUNT(it.item_id) DESC, t.name
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.TagCount
+	for rows.Next() {
+		var tc core.TagCount
+		if err := rows.Scan(&tc.Name, &tc.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) AddToCollection(ctx context.Context, name string, itemIDs ...string) error {
+	if name == "" {
+		return errors.New("collection name required")
+	}
+	now := s.now().UnixMilli()
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO collections(name, created_at) VALUES(?, ?)
+ON CONFLICT(name) DO NOTHING
+`, name, now); err != nil {
+			return err
+		}
+		for _, id := range itemIDs {
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO collection_items(collection_id, item_id, added_at)
+SELECT id, ?, ? FROM collections WHERE name=?
+ON CONFLICT DO NOTHING
+`, id, now, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) RemoveFromCollection(ctx context.Context, name string, itemIDs ...string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, id := range itemIDs {
+			if _, err := tx.ExecContext(ctx, `
+DELETE FROM collection_items
+WHERE item_id=? AND collection_id=(SELECT id FROM collections WHERE name=?)
+`, id, name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) ListCollections(ctx context.Context) ([]core.Collection, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT c.name, c.created_at, COUNT(items.id)
+FROM collections c
+LEFT JOIN collection_items ci ON ci.collection_id = c.id
+LEFT JOIN items ON items.id = ci.item_id AND items.deleted_at = 0
+GROUP BY c.id
+ORDER BY c.name
+`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Collection
+	for rows.Next() {
+		var c core.Collection
+		var cAt int64
+		if err := rows.Scan(&c.Name, &cAt, &c.Count); err != nil {
+			return nil, err
+		}
+		c.CreatedAt = time.UnixMilli(cAt)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// CollectionItems returns the items of a collection in the order they were added.
+func (s *Store) CollectionItems(ctx context.Context, name string) ([]core.Item, error) {
+	var exists int
+	err := s.db.QueryRowContext(ctx, `SELECT 1 FROM collections WHERE name=?`, name).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+itemColumns+`
+FROM items
+JOIN collection_items ci ON ci.item_id = items.id
+JOIN collections c ON c.id = ci.collection_id
+WHERE c.name=? AND items.deleted_at=0
+ORDER BY ci.added_at, items.created_at
+`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) DeleteCollection(ctx context.Context, name string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `
+DELETE FROM collection_items WHERE collection_id=(SELECT id FROM collections WHERE name=?)
+`, name); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM collections WHERE name=?`, name)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrCollectionNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,139 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func putText(t *testing.T, st *Store, content string, tags ...string) core.Item {
+	t.Helper()
+	now := time.Now()
+	it := core.Item{
+		ID:          uuid.NewString(),
+		Content:     content,
+		Type:        core.ContentTypeText,
+		Fingerprint: core.Fingerprint(content),
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		Tags:        tags,
+	}
+	if err := st.Put(context.Background(), it, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+	return it
+}
+
+func TestSQLiteStore_Tags(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	a := putText(t, st, "alpha", "Work")
+	b := putText(t, st, "beta")
+
+	if err := st.AddTags(ctx, b.ID, "work", "side project"); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := st.ListRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]core.Item{}
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	if got := byID[a.ID].Tags; len(got) != 1 || got[0] != "work" {
+		t.Fatalf("expected alpha tagged work, got %v", got)
+	}
+	if got := byID[b.ID].Tags; len(got) != 2 || got[0] != "side-project" || got[1] != "work" {
+		t.Fatalf("expected beta tagged side-project,work, got %v", got)
+	}
+
+	// recapturing with a tag keeps earlier tags on the surviving row
+	putText(t, st, "alpha", "again")
+	tags, err := st.ListTags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 || tags[0].Name != "work" || tags[0].Count != 2 {
+		t.Fatalf("unexpected tag counts: %+v", tags)
+	}
+
+	if err := st.RemoveTags(ctx, b.ID, "side-project"); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Delete(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	tags, err = st.ListTags(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "work" || tags[0].Count != 1 {
+		t.Fatalf("unexpected tag counts after removal: %+v", tags)
+	}
+}
+
+func TestSQLiteStore_Collections(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	a := putText(t, st, "alpha")
+	b := putText(t, st, "beta")
+
+	if err := st.AddToCollection(ctx, "onboarding", a.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AddToCollection(ctx, "onboarding", a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	cols, err := st.ListCollections(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 1 || cols[0].Name != "onboarding" || cols[0].Count != 2 {
+		t.Fatalf("unexpected collections: %+v", cols)
+	}
+
+	items, err := st.CollectionItems(ctx, "onboarding")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected 2 items in collection, got %d", len(items))
+	}
+
+	if err := st.RemoveFromCollection(ctx, "onboarding", a.ID); err != nil {
+		t.Fatal(err)
+	}
+	items, _ = st.CollectionItems(ctx, "onboarding")
+	if len(items) != 1 || items[0].ID != b.ID {
+		t.Fatalf("expected only beta left, got %+v", items)
+	}
+
+	if err := st.DeleteCollection(ctx, "onboarding"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CollectionItems(ctx, "onboarding"); !errors.Is(err, ErrCollectionNotFound) {
+		t.Fatalf("expected ErrCollectionNotFound, got %v", err)
+	}
+	if n, _ := st.Count(ctx); n != 2 {
+		t.Fatalf("expected items to survive collection delete, count=%d", n)
+	}
+}
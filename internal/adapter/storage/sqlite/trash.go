@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func (s *Store) Trash(ctx context.Context, id string) error {
+	return s.setDeletedAt(ctx, id, s.now().UnixMilli())
+}
+
+func (s *Store) Restore(ctx context.Context, id string) error {
+	return s.setDeletedAt(ctx, id, 0)
+}
+
+func (s *Store) setDeletedAt(ctx context.Context, id string, at int64) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE items SET deleted_at=? WHERE id=?`, at, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) ListTrash(ctx context.Context, limit int) ([]core.Item, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+itemColumns+`
+FROM items
+WHERE deleted_at > 0
+ORDER BY deleted_at DESC
+LIMIT ?
+`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []core.Item
+	for rows.Next() {
+		it, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+func (s *Store) PurgeTrash(ctx context.Context, cutoff time.Time) (int, error) {
+	before := int64(1<<63 - 1)
+	if !cutoff.IsZero() {
+		before = cutoff.UnixMilli()
+	}
+
+	var n int
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM items WHERE deleted_at > 0 AND deleted_at < ?`, before)
+		if err != nil {
+			return err
+		}
+		var ids []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			ids = append(ids, id)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if err := deleteItem(ctx, tx, id); err != nil {
+				return err
+			}
+		}
+		n = len(ids)
+		return nil
+	})
+	return n, err
+}
@@ -0,0 +1,97 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestSQLiteStore_Trash(t *testing.T) {
+	st, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	st.now = func() time.Time { return now }
+	put := func(content string) core.Item {
+		it := core.Item{
+			ID:          uuid.NewString(),
+			Content:     content,
+			Type:        core.ContentTypeText,
+			Fingerprint: core.Fingerprint(content),
+			CreatedAt:   now,
+			LastSeenAt:  now,
+			Tags:        []string{"work"},
+		}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+		return it
+	}
+	a, b := put("alpha"), put("beta")
+
+	if err := st.Trash(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := st.Count(ctx); n != 1 {
+		t.Fatalf("expected trashed item hidden from count, got %d", n)
+	}
+	if items, _ := st.ListRecent(ctx, 10); len(items) != 1 || items[0].ID != b.ID {
+		t.Fatalf("expected only beta listed, got %+v", items)
+	}
+	if tags, _ := st.ListTags(ctx); len(tags) != 1 || tags[0].Count != 1 {
+		t.Fatalf("expected trashed item left out of tag counts, got %+v", tags)
+	}
+
+	trash, err := st.ListTrash(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].ID != a.ID || trash[0].DeletedAt.IsZero() {
+		t.Fatalf("unexpected trash: %+v", trash)
+	}
+
+	if err := st.Restore(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := st.Count(ctx); n != 2 {
+		t.Fatalf("expected restored item counted, got %d", n)
+	}
+
+	// copying trashed content again brings it back
+	if err := st.Trash(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	put("beta")
+	if trash, _ := st.ListTrash(ctx, 10); len(trash) != 0 {
+		t.Fatalf("expected re-copy to restore, got %+v", trash)
+	}
+
+	// only items trashed before the cutoff are purged
+	if err := st.Trash(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	if err := st.Trash(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	n, err := st.PurgeTrash(ctx, now.Add(-time.Minute))
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 purged, got %d (%v)", n, err)
+	}
+	if n, _ := st.PurgeTrash(ctx, time.Time{}); n != 1 {
+		t.Fatalf("expected empty-trash to delete the rest, got %d", n)
+	}
+	if trash, _ := st.ListTrash(ctx, 10); len(trash) != 0 {
+		t.Fatalf("expected empty trash, got %+v", trash)
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/its-jojoo/otterclip/internal/core"
@@ -11,7 +12,7 @@ type PutMode int
 
 const (
 	PutInsert PutMode = iota
-	PutMerge          // used when dedupe wants to update LastSeenAt
+	PutMerge          // update an existing item by ID (dedupe, edits)
 )
 
 type Store interface {
@@ -19,8 +20,125 @@ type Store interface {
 	ListRecent(ctx context.Context, limit int) ([]core.Item, error)
 
 	SetPinned(ctx context.Context, id string, pinned bool) error
+	SetTitle(ctx context.Context, id string, title string) error
+	SetNote(ctx context.Context, id string, note string) error
 	Delete(ctx context.Context, id string) error
 
 	Count(ctx context.Context) (int, error)
 	Now() time.Time
 }
+
+// TagStore is implemented by stores that can organise items with tags and
+// named collections (both many-to-many with items).
+type TagStore interface {
+	AddTags(ctx context.Context, itemID string, tags ...string) error
+	RemoveTags(ctx context.Context, itemID string, tags ...string) error
+	ListTags(ctx context.Context) ([]core.TagCount, error)
+
+	AddToCollection(ctx context.Context, name string, itemIDs ...string) error
+	RemoveFromCollection(ctx context.Context, name string, itemIDs ...string) error
+	ListCollections(ctx context.Context) ([]core.Collection, error)
+	CollectionItems(ctx context.Context, name string) ([]core.Item, error)
+	DeleteCollection(ctx context.Context, name string) error
+}
+
+// SnippetStore keeps named snippets, separate from clipboard history.
+type SnippetStore interface {
+	PutSnippet(ctx context.Context, sn core.Snippet) error
+	GetSnippet(ctx context.Context, name string) (core.Snippet, error)
+	ListSnippets(ctx context.Context) ([]core.Snippet, error)
+	DeleteSnippet(ctx context.Context, name string) error
+}
+
+// SettingsStore persists small key/value state (modes, preferences).
+type SettingsStore interface {
+	GetSetting(ctx context.Context, key string) (string, bool, error)
+	SetSetting(ctx context.Context, key, value string) error
+}
+
+// QueueStore persists the paste queue. Entries reference items by
+// fingerprint, which survives dedupe upserts; entries whose item is gone
+// are skipped.
+type QueueStore interface {
+	QueuePush(ctx context.Context, fingerprint string) error
+	// QueueList returns queued items, oldest push first.
+	QueueList(ctx context.Context) ([]core.Item, error)
+	// QueuePop removes and returns the oldest entry, or the newest when
+	// newest is true. It returns ErrQueueEmpty when nothing is queued.
+	QueuePop(ctx context.Context, newest bool) (core.Item, error)
+	QueueClear(ctx context.Context) error
+}
+
+var ErrQueueEmpty = errors.New("paste queue is empty")
+
+// ItemFinder is implemented by stores that can look items up by ID.
+type ItemFinder interface {
+	// FindByIDPrefix returns up to limit items whose ID starts with prefix,
+	// trashed items included.
+	FindByIDPrefix(ctx context.Context, prefix string, limit int) ([]core.Item, error)
+	// FindByFingerprint returns the item holding the given content
+	// fingerprint, trashed or not: the item a dedupe upsert kept.
+	FindByFingerprint(ctx context.Context, fp string) (core.Item, error)
+}
+
+// ItemWalker is implemented by stores that can stream the history instead
+// of loading it into memory.
+type ItemWalker interface {
+	// WalkRecent calls fn for up to limit items (all when limit <= 0) in
+	// ListRecent order, stopping at the first error fn returns. fn must not
+	// use the store.
+	WalkRecent(ctx context.Context, limit int, fn func(core.Item) error) error
+}
+
+// TrashStore is implemented by stores with soft delete. Trashed items are
+// hidden from ListRecent, Count, tags and collections until restored or
+// purged; capturing the same content again restores them.
+type TrashStore interface {
+	Trash(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+	// ListTrash returns trashed items, most recently trashed first.
+	ListTrash(ctx context.Context, limit int) ([]core.Item, error)
+	// PurgeTrash permanently deletes items trashed before cutoff; the zero
+	// time empties the trash. It returns how many items were deleted.
+	PurgeTrash(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// RevisionStore is implemented by stores that keep the previous contents
+// of edited items: PutMerge records a revision whenever content changes.
+type RevisionStore interface {
+	// ListRevisions returns an item's revisions, oldest first.
+	ListRevisions(ctx context.Context, itemID string) ([]core.Revision, error)
+}
+
+// CounterStore is implemented by stores that keep named event counters
+// (core.CountStored and friends) in hourly buckets.
+type CounterStore interface {
+	// AddCount adds delta to the counter name for the hour containing at.
+	AddCount(ctx context.Context, name string, at time.Time, delta int) error
+	// Counts returns the hourly counts of name since since, oldest first.
+	// Hours with nothing counted are left out.
+	Counts(ctx context.Context, name string, since time.Time) ([]HourCount, error)
+}
+
+// SightingStore is implemented by stores that count how many times each
+// item was captured. Put counts an item's first sighting; capture reports
+// the repeats, so imports, transforms and snippets saving content already
+// in history don't count as copies.
+type SightingStore interface {
+	// AddSighting counts another capture of the item holding fingerprint
+	// fp.
+	AddSighting(ctx context.Context, fp string) error
+}
+
+// HourCount is a count for the hour starting at Hour.
+type HourCount struct {
+	Hour time.Time
+	N    int
+}
+
+// ErrNotFound is returned for lookups and updates of missing rows.
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicate is returned by PutMerge when the new content belongs to
+// another item (fingerprints are unique).
+var ErrDuplicate = errors.New("content duplicates another item")
@@ -0,0 +1,117 @@
+package tray
+
+import (
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// layout is a dbusmenu node, D-Bus signature (ia{sv}av).
+type layout struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+	Children   []dbus.Variant
+}
+
+// itemProperties is an entry of GetGroupProperties, signature (ia{sv}).
+type itemProperties struct {
+	ID         int32
+	Properties map[string]dbus.Variant
+}
+
+type entry struct {
+	item     MenuItem
+	children []int32
+}
+
+// menu is a MenuItem tree flattened to dbusmenu IDs. The root is 0 and
+// items are numbered depth first, so the IDs change whenever the menu
+// does; the revision tells hosts to fetch it again.
+type menu map[int32]*entry
+
+func newMenu(items []MenuItem) menu {
+	m := menu{0: {}}
+	next := int32(1)
+	var add func(parent int32, items []MenuItem)
+	add = func(parent int32, items []MenuItem) {
+		for _, it := range items {
+			id := next
+			next++
+			m[id] = &entry{item: it}
+			m[parent].children = append(m[parent].children, id)
+			add(id, it.Children)
+		}
+	}
+	add(0, items)
+	return m
+}
+
+// layout returns the subtree at id, depth levels deep (-1 for all), with
+// only the named properties (all when names is empty).
+func (m menu) layout(id int32, depth int32, names []string) (layout, bool) {
+	e, ok := m[id]
+	if !ok {
+		return layout{}, false
+	}
+	l := layout{ID: id, Properties: m.properties(id, names), Children: []dbus.Variant{}}
+	if depth == 0 {
+		return l, true
+	}
+	for _, c := range e.children {
+		child, _ := m.layout(c, depth-1, names)
+		l.Children = append(l.Children, dbus.MakeVariant(child))
+	}
+	return l, true
+}
+
+// properties returns an item's dbusmenu properties, leaving out those
+// equal to the spec defaults.
+func (m menu) properties(id int32, names []string) map[string]dbus.Variant {
+	props := map[string]dbus.Variant{}
+	e, ok := m[id]
+	if !ok {
+		return props
+	}
+	it := e.item
+	if id == 0 {
+		props["children-display"] = dbus.MakeVariant("submenu")
+	} else if it.Separator {
+		props["type"] = dbus.MakeVariant("separator")
+	} else {
+		// dbusmenu marks mnemonics with underscores
+		props["label"] = dbus.MakeVariant(strings.ReplaceAll(it.Label, "_", "__"))
+		if it.Disabled {
+			props["enabled"] = dbus.MakeVariant(false)
+		}
+		if it.Toggle {
+			props["toggle-type"] = dbus.MakeVariant("checkmark")
+			state := int32(0)
+			if it.Checked {
+				state = 1
+			}
+			props["toggle-state"] = dbus.MakeVariant(state)
+		}
+		if len(it.Children) > 0 {
+			props["children-display"] = dbus.MakeVariant("submenu")
+		}
+	}
+	if len(names) == 0 {
+		return props
+	}
+	out := map[string]dbus.Variant{}
+	for _, n := range names {
+		if v, ok := props[n]; ok {
+			out[n] = v
+		}
+	}
+	return out
+}
+
+// click returns the handler of item id, if it has one and is enabled.
+func (m menu) click(id int32) func() {
+	e, ok := m[id]
+	if !ok || id == 0 || e.item.Disabled || e.item.Separator {
+		return nil
+	}
+	return e.item.OnClick
+}
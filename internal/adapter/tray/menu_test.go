@@ -0,0 +1,56 @@
+package tray
+
+import "testing"
+
+func TestMenu_Layout(t *testing.T) {
+	clicked := 0
+	m := newMenu([]MenuItem{
+		{Label: "snake_case", OnClick: func() { clicked++ }},
+		{Separator: true},
+		{Label: "Pinned", Children: []MenuItem{{Label: "a"}, {Label: "b", Disabled: true, OnClick: func() {}}}},
+		{Label: "Pause", Toggle: true, Checked: true},
+	})
+
+	root, ok := m.layout(0, -1, nil)
+	if !ok || len(root.Children) != 4 {
+		t.Fatalf("expected 4 top-level entries, got %+v", root)
+	}
+	first := root.Children[0].Value().(layout)
+	if first.ID != 1 || first.Properties["label"].Value() != "snake__case" {
+		t.Fatalf("expected escaped label on item 1, got %+v", first)
+	}
+	if got := root.Children[1].Value().(layout).Properties["type"].Value(); got != "separator" {
+		t.Fatalf("expected a separator, got %v", got)
+	}
+	pinned := root.Children[2].Value().(layout)
+	if pinned.ID != 3 || len(pinned.Children) != 2 || pinned.Properties["children-display"].Value() != "submenu" {
+		t.Fatalf("expected a submenu with 2 entries, got %+v", pinned)
+	}
+	pause := root.Children[3].Value().(layout)
+	if pause.ID != 6 || pause.Properties["toggle-state"].Value() != int32(1) {
+		t.Fatalf("expected a checked toggle numbered after the submenu, got %+v", pause)
+	}
+
+	// depth 0 stops at the node, and names filter properties
+	sub, _ := m.layout(3, 0, []string{"label"})
+	if len(sub.Children) != 0 || len(sub.Properties) != 1 {
+		t.Fatalf("expected a bare node with only its label, got %+v", sub)
+	}
+	if _, ok := m.layout(42, -1, nil); ok {
+		t.Fatal("expected unknown id to fail")
+	}
+
+	if fn := m.click(1); fn == nil {
+		t.Fatal("expected a handler for item 1")
+	} else {
+		fn()
+	}
+	for _, id := range []int32{0, 2, 5, 42} { // root, separator, disabled, unknown
+		if m.click(id) != nil {
+			t.Errorf("expected no handler for %d", id)
+		}
+	}
+	if clicked != 1 {
+		t.Fatalf("expected one click, got %d", clicked)
+	}
+}
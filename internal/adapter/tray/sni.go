@@ -0,0 +1,369 @@
+package tray
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	itemPath    = "/StatusNotifierItem"
+	itemIface   = "org.kde.StatusNotifierItem"
+	menuPath    = "/MenuBar"
+	menuIface   = "com.canonical.dbusmenu"
+	watcherName = "org.kde.StatusNotifierWatcher"
+	watcherPath = "/StatusNotifierWatcher"
+)
+
+// Theme icons for the two capture states.
+const (
+	IconActive = "edit-paste"
+	IconPaused = "media-playback-pause"
+)
+
+// pixmap is an ARGB32 image, signature (iiay).
+type pixmap struct {
+	Width, Height int32
+	Data          []byte
+}
+
+// toolTip is the SNI tooltip, signature (sa(iiay)ss).
+type toolTip struct {
+	IconName    string
+	Pixmaps     []pixmap
+	Title       string
+	Description string
+}
+
+// menuEvent is an entry of EventGroup, signature (isvu).
+type menuEvent struct {
+	ID        int32
+	EventID   string
+	Data      dbus.Variant
+	Timestamp uint32
+}
+
+var instances atomic.Int32
+
+// SNI is a StatusNotifierItem. When no watcher runs yet it keeps waiting
+// and registers as soon as one appears, as it does when a panel restarts.
+type SNI struct {
+	conn      *dbus.Conn
+	name      string
+	props     *prop.Properties
+	activated chan struct{}
+	signals   chan *dbus.Signal
+
+	mu       sync.Mutex
+	menu     menu
+	revision uint32
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// Connect shows a tray icon on the session bus.
+func Connect() (*SNI, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupported, err)
+	}
+	s, err := NewSNI(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSNI exports the item and its menu on conn, which it closes on Close.
+func NewSNI(conn *dbus.Conn) (*SNI, error) {
+	s := &SNI{
+		conn:      conn,
+		name:      fmt.Sprintf("org.kde.StatusNotifierItem-%d-%d", os.Getpid(), instances.Add(1)),
+		activated: make(chan struct{}, 1),
+		signals:   make(chan *dbus.Signal, 4),
+		menu:      newMenu(nil),
+	}
+	if err := s.export(); err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.RequestName(s.name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return nil, fmt.Errorf("tray: request name: %w", err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return nil, fmt.Errorf("tray: %s is taken", s.name)
+	}
+
+	err = conn.AddMatchSignal(
+		dbus.WithMatchInterface("org.freedesktop.DBus"),
+		dbus.WithMatchMember("NameOwnerChanged"),
+		dbus.WithMatchArg(0, watcherName),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tray: watch for %s: %w", watcherName, err)
+	}
+	conn.Signal(s.signals)
+	s.wg.Add(1)
+	go s.reregister()
+
+	if err := s.register(); err != nil && !isServiceUnknown(err) {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SNI) export() error {
+	props, err := prop.Export(s.conn, itemPath, prop.Map{itemIface: {
+		"Category":          {Value: "ApplicationStatus", Emit: prop.EmitFalse},
+		"Id":                {Value: "otterclip", Emit: prop.EmitFalse},
+		"Title":             {Value: "OtterClip", Emit: prop.EmitFalse},
+		"Status":            {Value: "Active", Emit: prop.EmitFalse},
+		"WindowId":          {Value: int32(0), Emit: prop.EmitFalse},
+		"IconName":          {Value: IconActive, Emit: prop.EmitFalse},
+		"IconPixmap":        {Value: []pixmap{}, Emit: prop.EmitFalse},
+		"OverlayIconName":   {Value: "", Emit: prop.EmitFalse},
+		"AttentionIconName": {Value: "", Emit: prop.EmitFalse},
+		"ToolTip":           {Value: toolTip{Pixmaps: []pixmap{}, Title: "OtterClip"}, Emit: prop.EmitFalse},
+		"ItemIsMenu":        {Value: false, Emit: prop.EmitFalse},
+		"Menu":              {Value: dbus.ObjectPath(menuPath), Emit: prop.EmitFalse},
+	}})
+	if err != nil {
+		return fmt.Errorf("tray: export item: %w", err)
+	}
+	s.props = props
+	if err := s.conn.Export(itemHandler{s}, itemPath, itemIface); err != nil {
+		return fmt.Errorf("tray: export item: %w", err)
+	}
+
+	menuProps, err := prop.Export(s.conn, menuPath, prop.Map{menuIface: {
+		"Version":       {Value: uint32(3), Emit: prop.EmitFalse},
+		"TextDirection": {Value: "ltr", Emit: prop.EmitFalse},
+		"Status":        {Value: "normal", Emit: prop.EmitFalse},
+		"IconThemePath": {Value: []string{}, Emit: prop.EmitFalse},
+	}})
+	if err != nil {
+		return fmt.Errorf("tray: export menu: %w", err)
+	}
+	if err := s.conn.Export(menuHandler{s}, menuPath, menuIface); err != nil {
+		return fmt.Errorf("tray: export menu: %w", err)
+	}
+
+	item := introspect.Node{Name: itemPath, Interfaces: []introspect.Interface{
+		introspect.IntrospectData,
+		prop.IntrospectData,
+		{
+			Name:       itemIface,
+			Methods:    introspect.Methods(itemHandler{}),
+			Properties: props.Introspection(itemIface),
+			Signals: []introspect.Signal{
+				{Name: "NewTitle"}, {Name: "NewIcon"}, {Name: "NewToolTip"},
+			},
+		},
+	}}
+	menuNode := introspect.Node{Name: menuPath, Interfaces: []introspect.Interface{
+		introspect.IntrospectData,
+		prop.IntrospectData,
+		{
+			Name:       menuIface,
+			Methods:    introspect.Methods(menuHandler{}),
+			Properties: menuProps.Introspection(menuIface),
+			Signals: []introspect.Signal{
+				{Name: "LayoutUpdated", Args: []introspect.Arg{{Name: "revision", Type: "u"}, {Name: "parent", Type: "i"}}},
+			},
+		},
+	}}
+	for path, node := range map[dbus.ObjectPath]*introspect.Node{itemPath: &item, menuPath: &menuNode} {
+		if err := s.conn.Export(introspect.NewIntrospectable(node), path, "org.freedesktop.DBus.Introspectable"); err != nil {
+			return fmt.Errorf("tray: export introspection: %w", err)
+		}
+	}
+	return nil
+}
+
+// register announces the item to the watcher.
+func (s *SNI) register() error {
+	obj := s.conn.Object(watcherName, watcherPath)
+	if err := obj.Call(watcherName+".RegisterStatusNotifierItem", 0, s.name).Err; err != nil {
+		return fmt.Errorf("tray: register with %s: %w", watcherName, err)
+	}
+	return nil
+}
+
+// reregister registers again whenever a watcher takes the name.
+func (s *SNI) reregister() {
+	defer s.wg.Done()
+	for sig := range s.signals {
+		if sig.Name != "org.freedesktop.DBus.NameOwnerChanged" || len(sig.Body) != 3 {
+			continue
+		}
+		if owner, _ := sig.Body[2].(string); owner != "" {
+			s.register()
+		}
+	}
+}
+
+func isServiceUnknown(err error) bool {
+	var derr dbus.Error
+	return errors.As(err, &derr) && derr.Name == "org.freedesktop.DBus.Error.ServiceUnknown"
+}
+
+// Set updates the icon, tooltip and menu and tells the host.
+func (s *SNI) Set(st State) error {
+	icon := IconActive
+	if st.Paused {
+		icon = IconPaused
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("tray: closed")
+	}
+	s.menu = newMenu(st.Menu)
+	s.revision++
+	revision := s.revision
+	s.mu.Unlock()
+
+	s.props.SetMust(itemIface, "IconName", icon)
+	s.props.SetMust(itemIface, "ToolTip", toolTip{Pixmaps: []pixmap{}, Title: "OtterClip", Description: st.Tooltip})
+	return errors.Join(
+		s.conn.Emit(itemPath, itemIface+".NewIcon"),
+		s.conn.Emit(itemPath, itemIface+".NewToolTip"),
+		s.conn.Emit(menuPath, menuIface+".LayoutUpdated", revision, int32(0)),
+	)
+}
+
+func (s *SNI) Activated() <-chan struct{} { return s.activated }
+
+// Close removes the icon and closes the bus connection.
+func (s *SNI) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.activated)
+	s.mu.Unlock()
+
+	s.conn.RemoveSignal(s.signals)
+	close(s.signals)
+	s.wg.Wait()
+	s.conn.ReleaseName(s.name)
+	return s.conn.Close()
+}
+
+func (s *SNI) activate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.activated <- struct{}{}:
+	default:
+	}
+}
+
+// itemHandler holds the org.kde.StatusNotifierItem methods, kept off SNI
+// so Set and Close aren't exported on the bus.
+type itemHandler struct{ s *SNI }
+
+// Activate is a primary click on the icon.
+func (h itemHandler) Activate(x, y int32) *dbus.Error {
+	h.s.activate()
+	return nil
+}
+
+func (h itemHandler) SecondaryActivate(x, y int32) *dbus.Error {
+	h.s.activate()
+	return nil
+}
+
+// ContextMenu is only called by hosts that don't show Menu themselves.
+func (h itemHandler) ContextMenu(x, y int32) *dbus.Error { return nil }
+
+func (h itemHandler) Scroll(delta int32, orientation string) *dbus.Error { return nil }
+
+// menuHandler holds the com.canonical.dbusmenu methods.
+type menuHandler struct{ s *SNI }
+
+func (h menuHandler) current() (menu, uint32) {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	return h.s.menu, h.s.revision
+}
+
+func (h menuHandler) GetLayout(parent, depth int32, names []string) (uint32, layout, *dbus.Error) {
+	m, revision := h.current()
+	l, ok := m.layout(parent, depth, names)
+	if !ok {
+		return 0, layout{}, dbus.MakeFailedError(fmt.Errorf("no menu item %d", parent))
+	}
+	return revision, l, nil
+}
+
+func (h menuHandler) GetGroupProperties(ids []int32, names []string) ([]itemProperties, *dbus.Error) {
+	m, _ := h.current()
+	if len(ids) == 0 {
+		for id := range m {
+			ids = append(ids, id)
+		}
+	}
+	out := []itemProperties{}
+	for _, id := range ids {
+		if _, ok := m[id]; ok {
+			out = append(out, itemProperties{ID: id, Properties: m.properties(id, names)})
+		}
+	}
+	return out, nil
+}
+
+func (h menuHandler) GetProperty(id int32, name string) (dbus.Variant, *dbus.Error) {
+	m, _ := h.current()
+	v, ok := m.properties(id, []string{name})[name]
+	if !ok {
+		return dbus.Variant{}, dbus.MakeFailedError(fmt.Errorf("menu item %d has no %s", id, name))
+	}
+	return v, nil
+}
+
+func (h menuHandler) Event(id int32, eventID string, data dbus.Variant, timestamp uint32) *dbus.Error {
+	if eventID != "clicked" {
+		return nil
+	}
+	m, _ := h.current()
+	if fn := m.click(id); fn != nil {
+		go fn()
+	}
+	return nil
+}
+
+func (h menuHandler) EventGroup(events []menuEvent) ([]int32, *dbus.Error) {
+	m, _ := h.current()
+	unknown := []int32{}
+	for _, e := range events {
+		if _, ok := m[e.ID]; !ok {
+			unknown = append(unknown, e.ID)
+			continue
+		}
+		h.Event(e.ID, e.EventID, e.Data, e.Timestamp)
+	}
+	return unknown, nil
+}
+
+// AboutToShow reports no update needed: the menu is rebuilt whenever the
+// history changes, not when it opens.
+func (h menuHandler) AboutToShow(id int32) (bool, *dbus.Error) { return false, nil }
+
+func (h menuHandler) AboutToShowGroup(ids []int32) ([]int32, []int32, *dbus.Error) {
+	return []int32{}, []int32{}, nil
+}
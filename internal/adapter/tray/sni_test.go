@@ -0,0 +1,172 @@
+package tray
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// startBus runs a private session bus for the test, skipping when
+// dbus-daemon isn't installed.
+func startBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address=1", "--address=unix:dir="+t.TempDir())
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("dbus-daemon: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func connect(t *testing.T, addr string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// watcher stands in for the panel's StatusNotifierWatcher.
+type watcher struct{ items chan string }
+
+func (w watcher) RegisterStatusNotifierItem(service string) *dbus.Error {
+	w.items <- service
+	return nil
+}
+
+func (w watcher) start(t *testing.T, conn *dbus.Conn) {
+	t.Helper()
+	if err := conn.Export(w, watcherPath, watcherName); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.RequestName(watcherName, dbus.NameFlagDoNotQueue); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (w watcher) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case name := <-w.items:
+		return name
+	case <-time.After(2 * time.Second):
+		t.Fatal("item not registered")
+		return ""
+	}
+}
+
+func TestSNI_RegistersWhenWatcherAppears(t *testing.T) {
+	addr := startBus(t)
+	s, err := NewSNI(connect(t, addr))
+	if err != nil {
+		t.Fatalf("expected no watcher to be fine, got %v", err)
+	}
+	defer s.Close()
+
+	host := connect(t, addr)
+	defer host.Close()
+	w := watcher{items: make(chan string, 1)}
+	w.start(t, host)
+	if got := w.next(t); got != s.name {
+		t.Fatalf("registered %q, want %q", got, s.name)
+	}
+}
+
+func TestSNI_MenuAndActivation(t *testing.T) {
+	addr := startBus(t)
+	host := connect(t, addr)
+	defer host.Close()
+	w := watcher{items: make(chan string, 1)}
+	w.start(t, host)
+
+	s, err := NewSNI(connect(t, addr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	name := w.next(t)
+
+	clicks := make(chan string, 1)
+	err = s.Set(State{
+		Paused:  true,
+		Tooltip: "paused",
+		Menu: []MenuItem{
+			{Label: "Resume capturing", OnClick: func() { clicks <- "resume" }},
+			{Separator: true},
+			{Label: "Quit", OnClick: func() { clicks <- "quit" }},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := host.Object(name, itemPath)
+	icon, err := item.GetProperty(itemIface + ".IconName")
+	if err != nil || icon.Value() != IconPaused {
+		t.Fatalf("expected the paused icon, got %v (%v)", icon, err)
+	}
+	menuPathProp, err := item.GetProperty(itemIface + ".Menu")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	menuObj := host.Object(name, menuPathProp.Value().(dbus.ObjectPath))
+	var (
+		revision uint32
+		root     layout
+	)
+	if err := menuObj.Call(menuIface+".GetLayout", 0, int32(0), int32(-1), []string{}).Store(&revision, &root); err != nil {
+		t.Fatal(err)
+	}
+	if revision != 1 || len(root.Children) != 3 {
+		t.Fatalf("expected revision 1 with 3 entries, got %d %+v", revision, root)
+	}
+	var last layout
+	if err := dbus.Store([]any{root.Children[2].Value()}, &last); err != nil {
+		t.Fatal(err)
+	}
+	if last.Properties["label"].Value() != "Quit" {
+		t.Fatalf("expected Quit last, got %+v", last)
+	}
+
+	call := menuObj.Call(menuIface+".Event", 0, last.ID, "clicked", dbus.MakeVariant(""), uint32(0))
+	if call.Err != nil {
+		t.Fatal(call.Err)
+	}
+	select {
+	case got := <-clicks:
+		if got != "quit" {
+			t.Fatalf("clicked %s, want quit", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("click not delivered")
+	}
+
+	if err := item.Call(itemIface+".Activate", 0, int32(0), int32(0)).Err; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.Activated():
+	case <-time.After(2 * time.Second):
+		t.Fatal("activation not delivered")
+	}
+}
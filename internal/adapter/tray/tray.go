@@ -0,0 +1,42 @@
+// Package tray shows a status icon with a menu. The Linux implementation
+// is a StatusNotifierItem with a com.canonical.dbusmenu menu, exported on
+// the session bus and announced to the StatusNotifierWatcher that panels
+// (KDE, waybar, the GNOME AppIndicator extension, ...) run.
+package tray
+
+import "errors"
+
+// ErrUnsupported is returned by Connect when there is no session bus.
+var ErrUnsupported = errors.New("tray: no session bus")
+
+// MenuItem is an entry of the tray menu.
+type MenuItem struct {
+	Label    string
+	Disabled bool
+	// Separator draws a line; the other fields are ignored.
+	Separator bool
+	// Toggle shows a check mark, set when Checked.
+	Toggle  bool
+	Checked bool
+	// Children make the item a submenu.
+	Children []MenuItem
+	// OnClick runs when the item is chosen, on its own goroutine.
+	OnClick func()
+}
+
+// State is everything the tray displays.
+type State struct {
+	// Paused switches to the paused icon.
+	Paused  bool
+	Tooltip string
+	Menu    []MenuItem
+}
+
+// Tray is a status icon.
+type Tray interface {
+	// Set replaces the icon state and menu.
+	Set(State) error
+	// Activated reports clicks on the icon itself. It is closed by Close.
+	Activated() <-chan struct{}
+	Close() error
+}
@@ -0,0 +1,27 @@
+package core
+
+import "strings"
+
+// ConcealedTargets are clipboard formats that password managers and other
+// apps offer to tell clipboard history tools to stay away.
+var ConcealedTargets = []string{
+	"x-kde-passwordManagerHint",                    // KeePassXC, KDE
+	"ExcludeClipboardContentFromMonitorProcessing", // Windows convention
+	"CanIncludeInClipboardHistory",                 // Windows (value 0)
+	"org.nspasteboard.ConcealedType",               // macOS (nspasteboard.org)
+	"org.nspasteboard.TransientType",
+}
+
+// IsConcealed reports whether any of the offered targets (MIME types or
+// pasteboard types) marks the clipboard content as sensitive.
+func IsConcealed(targets []string) bool {
+	for _, t := range targets {
+		t = strings.TrimSpace(t)
+		for _, c := range ConcealedTargets {
+			if strings.EqualFold(t, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package core
+
+import "testing"
+
+func TestIsConcealed(t *testing.T) {
+	if !IsConcealed([]string{"TARGETS", "UTF8_STRING", "x-kde-passwordManagerHint"}) {
+		t.Fatalf("expected kde password manager hint to be concealed")
+	}
+	if !IsConcealed([]string{"public.utf8-plain-text", "org.nspasteboard.ConcealedType"}) {
+		t.Fatalf("expected nspasteboard concealed type to be concealed")
+	}
+	if IsConcealed([]string{"TARGETS", "UTF8_STRING", "text/plain"}) {
+		t.Fatalf("did not expect plain text to be concealed")
+	}
+	if IsConcealed(nil) {
+		t.Fatalf("did not expect empty targets to be concealed")
+	}
+}
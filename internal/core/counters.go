@@ -0,0 +1,13 @@
+package core
+
+// Counters kept by capture (see storage.CounterStore). Most clipboard
+// events leave no new item behind, so they are counted instead.
+const (
+	CountStored  = "stored"  // a clip became a new item
+	CountDeduped = "deduped" // a clip repeated an item already in history
+
+	CountIgnoredPrivacy   = "ignored.privacy"   // matched the privacy filter
+	CountIgnoredApp       = "ignored.app"       // source app has an ignore rule
+	CountIgnoredConcealed = "ignored.concealed" // flagged by a password manager
+	CountFiltered         = "filtered"          // dropped by a capture processor
+)
@@ -1,55 +1,557 @@
 package core
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
 	"net/url"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 )
 
-func DetectType(content string) ContentType {
+// Detector scores how likely content is of a given type, from 0 (no) to 1
+// (certain).
+type Detector interface {
+	Type() ContentType
+	Detect(s string) float64
+}
+
+// DetectorFunc adapts a scoring function to the Detector interface.
+func DetectorFunc(t ContentType, fn func(s string) float64) Detector {
+	return funcDetector{t: t, fn: fn}
+}
+
+type funcDetector struct {
+	t  ContentType
+	fn func(s string) float64
+}
+
+func (d funcDetector) Type() ContentType       { return d.t }
+func (d funcDetector) Detect(s string) float64 { return d.fn(s) }
+
+type Detection struct {
+	Type       ContentType
+	Confidence float64
+}
+
+// DetectorRegistry runs a set of detectors and picks the most confident.
+// Ties go to the detector registered first.
+type DetectorRegistry struct {
+	// Threshold is the minimum confidence for a type to win over plain text.
+	Threshold float64
+
+	detectors []Detector
+}
+
+func NewDetectorRegistry(ds ...Detector) *DetectorRegistry {
+	return &DetectorRegistry{Threshold: 0.5, detectors: ds}
+}
+
+func (r *DetectorRegistry) Register(d Detector) {
+	r.detectors = append(r.detectors, d)
+}
+
+// Detect returns every type with a positive score, most confident first.
+func (r *DetectorRegistry) Detect(content string) []Detection {
 	s := strings.TrimSpace(content)
 	if s == "" {
+		return nil
+	}
+
+	out := make([]Detection, 0, 4)
+	for _, d := range r.detectors {
+		if c := d.Detect(s); c > 0 {
+			out = append(out, Detection{Type: d.Type(), Confidence: min(c, 1)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Confidence > out[j].Confidence })
+	return out
+}
+
+// Best returns the most confident type, or text if nothing reaches the
+// threshold.
+func (r *DetectorRegistry) Best(content string) ContentType {
+	ds := r.Detect(content)
+	if len(ds) == 0 || ds[0].Confidence < r.Threshold {
 		return ContentTypeText
 	}
+	return ds[0].Type
+}
+
+// DefaultDetectors is the registry used by DetectType.
+var DefaultDetectors = NewDetectorRegistry(
+	DetectorFunc(ContentTypeUUID, detectUUID),
+	DetectorFunc(ContentTypeIP, detectIP),
+	DetectorFunc(ContentTypeEmail, detectEmail),
+	DetectorFunc(ContentTypeURL, detectURL),
+	DetectorFunc(ContentTypeColor, detectColor),
+	DetectorFunc(ContentTypeTimestamp, detectTimestamp),
+	DetectorFunc(ContentTypePhone, detectPhone),
+	DetectorFunc(ContentTypeJSON, detectJSON),
+	DetectorFunc(ContentTypeDiff, detectDiff),
+	DetectorFunc(ContentTypeStackTrace, detectStackTrace),
+	DetectorFunc(ContentTypeSQL, detectSQL),
+	DetectorFunc(ContentTypePath, detectPath),
+	DetectorFunc(ContentTypeBase64, detectBase64),
+	DetectorFunc(ContentTypeCommand, detectCommand),
+	DetectorFunc(ContentTypeMarkdown, detectMarkdown),
+	DetectorFunc(ContentTypeYAML, detectYAML),
+	DetectorFunc(ContentTypeCode, detectCode),
+)
 
-	// URL detection
+func DetectType(content string) ContentType {
+	return DefaultDetectors.Best(content)
+}
+
+var (
+	reUUID      = regexp.MustCompile(`^(?i)(urn:uuid:)?\{?[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\}?$`)
+	reEmail     = regexp.MustCompile(`^(?i)(mailto:)?[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`)
+	reHexColor  = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	reFuncColor = regexp.MustCompile(`^(?i)(rgba?|hsla?)\(\s*[\d.]+%?\s*[, ]\s*[\d.]+%?\s*[, ]\s*[\d.]+%?\s*([,/]\s*[\d.]+%?\s*)?\)$`)
+	rePhone     = regexp.MustCompile(`^\+?[\d\s().\-]{7,20}$`)
+	reUnixTime  = regexp.MustCompile(`^\d{10}(\d{3})?$`)
+	reSQL       = regexp.MustCompile(`^(?is)(select\s.+|insert\s+into\s.+|update\s+\S+\s+set\s.+|delete\s+from\s.+|create\s+(table|index|unique\s+index|view)\s.+|alter\s+table\s.+|drop\s+(table|index|view)\s.+|with\s+\w+\s+as\s*\(.+)$`)
+	// reSQLClause matches what queries have beyond the leading keyword and
+	// prose doesn't: a comparison, a column star, a value list, a
+	// subquery, a bare table name after FROM or DDL naming its object.
+	reSQLClause = regexp.MustCompile(`(?is)(;\s*$|=|<>|\bselect\s+(\w+\.)?\*|\(\*\)|\bvalues\s*\(|\(\s*select\s|` +
+		`\b(where|and|or)\s+[\w.]+\s*(<|>|\s(like|in|is|between)\s)|\b(group|order)\s+by\s+[\w.]+\s*(,|$)|` +
+		`\bfrom\s+[\w."]+\s*$|^select\s+(distinct\s+)?[\w.]+(\s*,\s*[\w.]+)+\s+from\s|` +
+		`^(create|drop)\s+(table|index|unique\s+index|view)\s+(if\s+(not\s+)?exists\s+)?[\w.]+\s*(\(|;|$|\son\s|\sas\s)|` +
+		`^alter\s+table\s+[\w.]+\s+(add|drop|rename|alter)\s)`)
+	reWinPath   = regexp.MustCompile(`^[a-zA-Z]:\\[^<>:"|?*\n]*$`)
+	reYAMLKey   = regexp.MustCompile(`^\s*[\w.\-"']+:(\s|$)`)
+	reYAMLList  = regexp.MustCompile(`^\s*- \S`)
+	reMDLink    = regexp.MustCompile(`\[[^\]]+\]\([^)]+\)`)
+	reMDHeading = regexp.MustCompile(`^#{1,6} \S`)
+	reMDList    = regexp.MustCompile(`^\s*([-*+]|\d+\.) (\[[ xX]\] )?\S`)
+	reJSFrame   = regexp.MustCompile(`^\s*at .+\(.+:\d+:\d+\)$`)
+	reJavaFrame = regexp.MustCompile(`^\s*at [\w$.]+\(.*\)$`)
+	reGoFrame   = regexp.MustCompile(`^\s+/.+\.go:\d+`)
+)
+
+func isSingleToken(s string) bool {
+	return !strings.ContainsFunc(s, unicode.IsSpace)
+}
+
+func detectUUID(s string) float64 {
+	if reUUID.MatchString(s) {
+		return 0.99
+	}
+	return 0
+}
+
+func detectIP(s string) float64 {
+	if !isSingleToken(s) {
+		return 0
+	}
+	if net.ParseIP(s) != nil {
+		return 0.97
+	}
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return 0.97
+	}
+	return 0
+}
+
+func detectEmail(s string) float64 {
+	if reEmail.MatchString(s) {
+		return 0.96
+	}
+	return 0
+}
+
+func detectURL(s string) float64 {
+	if !isSingleToken(s) {
+		return 0
+	}
 	if u, err := url.Parse(s); err == nil && u.Scheme != "" && u.Host != "" {
-		return ContentTypeURL
+		return 0.95
+	}
+	if strings.HasPrefix(s, "www.") && strings.Count(s, ".") >= 2 {
+		return 0.7
+	}
+	return 0
+}
+
+func detectColor(s string) float64 {
+	if reHexColor.MatchString(s) {
+		return 0.95
+	}
+	if reFuncColor.MatchString(s) {
+		return 0.95
 	}
+	return 0
+}
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+	time.UnixDate,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05.000",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"02/Jan/2006:15:04:05 -0700", // access logs
+}
+
+func detectTimestamp(s string) float64 {
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return 0.95
+		}
+	}
+	if reUnixTime.MatchString(s) {
+		// plausible seconds/millis between 2001 and 2096
+		secs := s
+		if len(s) == 13 {
+			secs = s[:10]
+		}
+		if secs >= "1000000000" && secs <= "3999999999" {
+			return 0.7
+		}
+	}
+	return 0
+}
 
-	// Command-ish detection (very simple MVP heuristic)
-	if looksLikeCommand(s) {
-		return ContentTypeCommand
+func detectPhone(s string) float64 {
+	if !rePhone.MatchString(s) {
+		return 0
+	}
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
 	}
+	if digits < 7 || digits > 15 {
+		return 0
+	}
+	if !strings.ContainsAny(s, "+ ()-") && strings.Contains(s, ".") && !dottedPhone(s) {
+		return 0 // 3.14159265
+	}
+	if strings.HasPrefix(s, "+") || strings.ContainsAny(s, " ()-.") {
+		return 0.85
+	}
+	// a bare run of digits is more often an id or a number
+	return 0.4
+}
 
-	// Code-ish detection (very simple MVP heuristic)
-	if looksLikeCode(s) {
-		return ContentTypeCode
+// dottedPhone reports whether a number separated only by dots splits into
+// phone-like groups, as in 555.123.4567 or 01.23.45.67.89.
+func dottedPhone(s string) bool {
+	groups := strings.Split(s, ".")
+	if len(groups) < 3 || len(s)-len(groups)+1 < 9 {
+		return false
 	}
+	for _, g := range groups {
+		if len(g) < 2 || len(g) > 4 {
+			return false
+		}
+	}
+	return true
+}
 
-	return ContentTypeText
+func detectJSON(s string) float64 {
+	if (s[0] == '{' || s[0] == '[') && json.Valid([]byte(s)) {
+		return 0.98
+	}
+	return 0
 }
 
-func looksLikeCommand(s string) bool {
-	// starts with common shell prompt or contains flags/pipes
+func detectDiff(s string) float64 {
+	if strings.HasPrefix(s, "diff --git ") {
+		return 0.98
+	}
+	hasOld := strings.HasPrefix(s, "--- ") || strings.Contains(s, "\n--- ")
+	hasNew := strings.Contains(s, "+++ ")
+	hasHunk := strings.Contains(s, "@@ -")
+	switch {
+	case hasOld && hasNew && hasHunk:
+		return 0.97
+	case hasHunk && strings.Contains(s, " @@"):
+		return 0.8
+	}
+	return 0
+}
+
+func detectStackTrace(s string) float64 {
+	switch {
+	case strings.HasPrefix(s, "Traceback (most recent call last):"):
+		return 0.98
+	case strings.HasPrefix(s, "panic: ") && strings.Contains(s, "goroutine "):
+		return 0.98
+	case strings.HasPrefix(s, "goroutine ") && strings.Contains(s, " ["):
+		return 0.95
+	case strings.Contains(s, "Exception in thread "):
+		return 0.95
+	}
+
+	frames := 0
+	for _, line := range strings.Split(s, "\n") {
+		if reJSFrame.MatchString(line) || reJavaFrame.MatchString(line) || reGoFrame.MatchString(line) {
+			frames++
+		}
+	}
+	switch {
+	case frames >= 2:
+		return 0.9
+	case frames == 1:
+		return 0.5
+	}
+	return 0
+}
+
+func detectSQL(s string) float64 {
+	if !reSQL.MatchString(s) {
+		return 0
+	}
+	// "Delete from the queue all stale jobs" starts like a query; without
+	// upper-case keywords, ask for query structure, and no sentence
+	// ending, before calling it SQL
+	keyword := s
+	if i := strings.IndexFunc(s, unicode.IsSpace); i > 0 {
+		keyword = s[:i]
+	}
+	if keyword != strings.ToUpper(keyword) &&
+		(strings.ContainsAny(s[len(s)-1:], ".!?") || !reSQLClause.MatchString(s)) {
+		return 0
+	}
+	low := strings.ToLower(s)
+	if strings.HasPrefix(low, "select") && !strings.Contains(low, " from ") && !strings.Contains(low, "\nfrom ") {
+		// "select all files" is English, "SELECT 1" is SQL
+		if strings.HasPrefix(s, "SELECT") {
+			return 0.6
+		}
+		return 0
+	}
+	return 0.9
+}
+
+func detectPath(s string) float64 {
+	if strings.Contains(s, "\n") {
+		return 0
+	}
+	if reWinPath.MatchString(s) {
+		return 0.85
+	}
+	if !isSingleToken(s) {
+		return 0
+	}
+	switch {
+	case strings.HasPrefix(s, "~/"), strings.HasPrefix(s, "./"), strings.HasPrefix(s, "../"):
+		return 0.85
+	case strings.HasPrefix(s, "/") && len(s) > 1 && !strings.HasPrefix(s, "//"):
+		if strings.Count(s, "/") >= 2 || strings.Contains(s, ".") {
+			return 0.85
+		}
+		return 0.6
+	}
+	return 0
+}
+
+func detectBase64(s string) float64 {
+	if len(s) < 16 || !isSingleToken(s) {
+		return 0
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r == '+' || r == '/' || r == '=':
+			symbol = true
+		case r == '-' || r == '_':
+		default:
+			return 0
+		}
+	}
+	if !upper || !lower {
+		return 0
+	}
+	if !digit && !symbol && len(s)%4 != 0 {
+		return 0 // ThisIsACamelCaseIdentifier
+	}
+
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if _, err := enc.DecodeString(s); err == nil {
+			if digit || strings.HasSuffix(s, "=") {
+				return 0.75
+			}
+			return 0.55
+		}
+	}
+	return 0
+}
+
+var commandNames = map[string]bool{
+	"git": true, "docker": true, "kubectl": true, "helm": true, "npm": true, "npx": true,
+	"yarn": true, "pnpm": true, "go": true, "cargo": true, "make": true, "curl": true,
+	"wget": true, "ssh": true, "scp": true, "rsync": true, "ls": true, "cd": true,
+	"cat": true, "grep": true, "rg": true, "find": true, "sed": true, "awk": true,
+	"brew": true, "apt": true, "apt-get": true, "dnf": true, "pacman": true, "pip": true,
+	"python": true, "python3": true, "node": true, "tar": true, "chmod": true,
+	"chown": true, "export": true, "systemctl": true, "journalctl": true, "terraform": true,
+	"aws": true, "gcloud": true, "az": true, "psql": true, "mysql": true, "redis-cli": true,
+}
+
+func detectCommand(s string) float64 {
 	if strings.HasPrefix(s, "$ ") || strings.HasPrefix(s, "sudo ") {
-		return true
+		return 0.9
 	}
-	if strings.Contains(s, " --") || strings.Contains(s, " | ") || strings.Contains(s, " && ") {
-		return true
+
+	first, _, _ := strings.Cut(s, " ")
+	known := commandNames[first]
+	lines := strings.Count(s, "\n") + 1
+
+	score := 0.0
+	if known {
+		score += 0.45
+	}
+	if (strings.Contains(s, " --") || strings.Contains(s, " -") && known) && !proseBeforeFlags(s) {
+		score += 0.3
+	}
+	if strings.Contains(s, " | ") || strings.Contains(s, " && ") || strings.Contains(s, " \\\n") {
+		score += 0.35
+	}
+	if lines > 3 && !strings.Contains(s, " \\\n") {
+		// scripts are code, not a command line
+		score -= 0.3
+	}
+	return score
+}
+
+// proseWords are common English words that don't appear in a command
+// line before its flags.
+var proseWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "you": true, "your": true,
+	"i": true, "we": true, "it": true, "is": true, "are": true, "sure": true,
+	"and": true, "of": true, "for": true, "with": true, "that": true, "this": true,
+	"then": true, "please": true, "should": true, "can": true, "will": true,
+}
+
+// proseBeforeFlags reports whether the words before the first flag read
+// like a sentence, as in "make sure you pass -v".
+func proseBeforeFlags(s string) bool {
+	line, _, _ := strings.Cut(s, "\n")
+	for _, w := range strings.Fields(line)[1:] {
+		if strings.HasPrefix(w, "-") {
+			break
+		}
+		if proseWords[strings.ToLower(w)] {
+			return true
+		}
 	}
 	return false
 }
 
-func looksLikeCode(s string) bool {
-	// tiny heuristic: braces/semicolons/keywords
-	if strings.Contains(s, "{") && strings.Contains(s, "}") {
-		return true
+var codeKeywords = []string{
+	"package ", "import ", "func ", "function ", "def ", "class ", "const ",
+	"let ", "var ", "return ", "if (", "for (", "while (", "#include",
+	"public ", "private ", "fn ", "impl ", "struct ", "interface ", "export ",
+	"async ", "from ",
+}
+
+func detectCode(s string) float64 {
+	if strings.HasPrefix(s, "#!/") && strings.Contains(s, "\n") {
+		return 0.9 // a script
 	}
-	if strings.Contains(s, "function ") || strings.Contains(s, "package ") || strings.Contains(s, "import ") {
-		return true
+	score := 0.0
+
+	lines := strings.Split(s, "\n")
+	keywordLines := 0
+	for _, line := range lines {
+		t := strings.TrimSpace(line)
+		for _, kw := range codeKeywords {
+			if strings.HasPrefix(t, kw) {
+				keywordLines++
+				break
+			}
+		}
+	}
+	score += 0.35 * float64(min(keywordLines, 2))
+
+	// braces only count when they look like blocks, not prose like "{name}"
+	if strings.Contains(s, "{") && strings.Contains(s, "}") &&
+		(strings.Contains(s, ") {") || strings.Contains(s, "{\n") || strings.Contains(s, "=> {")) {
+		score += 0.3
 	}
 	if strings.Contains(s, ";") && strings.Contains(s, "=") {
-		return true
+		score += 0.2
 	}
-	return false
+	if strings.Contains(s, "=>") || strings.Contains(s, ":=") || strings.Contains(s, "->") {
+		score += 0.1
+	}
+	return min(score, 0.9)
+}
+
+func detectMarkdown(s string) float64 {
+	signals := 0
+	for _, line := range strings.Split(s, "\n") {
+		switch {
+		case reMDHeading.MatchString(line), strings.HasPrefix(line, "```"), strings.HasPrefix(line, "> "):
+			signals += 2
+		case reMDList.MatchString(line):
+			signals++
+		}
+	}
+	if reMDLink.MatchString(s) {
+		signals += 2
+	}
+	if strings.Contains(s, "**") || strings.Contains(s, "__") {
+		signals++
+	}
+
+	switch {
+	case signals >= 4:
+		return 0.85
+	case signals >= 2:
+		return 0.6
+	case signals == 1:
+		return 0.3
+	}
+	return 0
+}
+
+func detectYAML(s string) float64 {
+	lines := strings.Split(s, "\n")
+	if len(lines) < 2 && !strings.HasPrefix(s, "---") {
+		return 0
+	}
+
+	keys, items, other := 0, 0, 0
+	for _, line := range lines {
+		t := strings.TrimSpace(line)
+		switch {
+		case t == "" || t == "---" || strings.HasPrefix(t, "#"):
+		case reYAMLKey.MatchString(line):
+			keys++
+		case reYAMLList.MatchString(line):
+			items++
+		default:
+			other++
+		}
+	}
+	if keys == 0 {
+		return 0
+	}
+	if other == 0 && keys+items >= 2 {
+		return 0.8
+	}
+	if other*3 < keys+items {
+		return 0.55
+	}
+	return 0
 }
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"testing"
+)
+
+type corpusSample struct {
+	want    ContentType
+	content string
+	line    int
+}
+
+func loadDetectCorpus(t *testing.T) []corpusSample {
+	t.Helper()
+
+	f, err := os.Open("testdata/detect_corpus.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var out []corpusSample
+	var cur *corpusSample
+	var body []string
+	flush := func() {
+		if cur != nil {
+			cur.content = strings.Join(body, "\n")
+			out = append(out, *cur)
+		}
+		body = body[:0]
+	}
+
+	sc := bufio.NewScanner(f)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := sc.Text()
+		if typ, ok := strings.CutPrefix(line, "=== "); ok {
+			flush()
+			want, ok := ParseContentType(typ)
+			if !ok {
+				t.Fatalf("corpus line %d: unknown type %q", n, typ)
+			}
+			cur = &corpusSample{want: want, line: n}
+			continue
+		}
+		if cur == nil {
+			continue // header comments
+		}
+		body = append(body, line)
+	}
+	flush()
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestDetectTypeCorpus(t *testing.T) {
+	samples := loadDetectCorpus(t)
+	if len(samples) == 0 {
+		t.Fatal("empty corpus")
+	}
+
+	covered := make(map[ContentType]bool)
+	for _, s := range samples {
+		covered[s.want] = true
+		if got := DetectType(s.content); got != s.want {
+			t.Errorf("corpus line %d: expected %s, got %s (%v)\n%s", s.line, s.want, got, DefaultDetectors.Detect(s.content), s.content)
+		}
+	}
+
+	for _, typ := range ContentTypes() {
+		if !covered[typ] {
+			t.Errorf("corpus has no sample for %s", typ)
+		}
+	}
+}
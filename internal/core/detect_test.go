@@ -1,6 +1,9 @@
 package core
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestDetectTypeURL(t *testing.T) {
 	if DetectType("https://example.com/path") != ContentTypeURL {
@@ -19,3 +22,33 @@ func TestDetectTypeCode(t *testing.T) {
 		t.Fatalf("expected code")
 	}
 }
+
+func TestDetectTypeBracesAreNotCode(t *testing.T) {
+	if got := DetectType("Dear {name}, your order {id} has shipped."); got != ContentTypeText {
+		t.Fatalf("expected text, got %s", got)
+	}
+}
+
+func TestDetectorRegistryCustom(t *testing.T) {
+	jira := ContentType("jira")
+	r := NewDetectorRegistry(DetectorFunc(jira, func(s string) float64 {
+		if strings.HasPrefix(s, "OPS-") {
+			return 0.9
+		}
+		return 0
+	}))
+	r.Register(DetectorFunc(ContentTypeCommand, func(string) float64 { return 0.6 }))
+
+	if got := r.Best("OPS-123"); got != jira {
+		t.Fatalf("expected custom type, got %s", got)
+	}
+	ds := r.Detect("OPS-123")
+	if len(ds) != 2 || ds[0].Type != jira || ds[1].Type != ContentTypeCommand {
+		t.Fatalf("expected detections sorted by confidence, got %v", ds)
+	}
+
+	r.Threshold = 0.95
+	if got := r.Best("OPS-123"); got != ContentTypeText {
+		t.Fatalf("expected text below threshold, got %s", got)
+	}
+}
@@ -0,0 +1,95 @@
+package core
+
+import "strings"
+
+type DiffOp byte
+
+const (
+	DiffEqual  DiffOp = ' '
+	DiffDelete DiffOp = '-'
+	DiffInsert DiffOp = '+'
+)
+
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// maxDiffCells bounds the LCS table; larger inputs diff as a full
+// replacement.
+const maxDiffCells = 4_000_000
+
+// DiffLines computes a line diff turning a into b (longest common
+// subsequence; deletions are listed before insertions).
+func DiffLines(a, b string) []DiffLine {
+	x, y := splitLines(a), splitLines(b)
+	n, m := len(x), len(y)
+
+	if (n+1)*(m+1) > maxDiffCells {
+		out := make([]DiffLine, 0, n+m)
+		for _, l := range x {
+			out = append(out, DiffLine{DiffDelete, l})
+		}
+		for _, l := range y {
+			out = append(out, DiffLine{DiffInsert, l})
+		}
+		return out
+	}
+
+	// lcs[i][j] = LCS length of x[i:] and y[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	out := make([]DiffLine, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, DiffLine{DiffEqual, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, DiffLine{DiffDelete, x[i]})
+			i++
+		default:
+			out = append(out, DiffLine{DiffInsert, y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		out = append(out, DiffLine{DiffDelete, x[i]})
+	}
+	for ; j < m; j++ {
+		out = append(out, DiffLine{DiffInsert, y[j]})
+	}
+	return out
+}
+
+// FormatDiff renders lines with "-", "+" and " " prefixes.
+func FormatDiff(lines []DiffLine) string {
+	var b strings.Builder
+	for _, l := range lines {
+		b.WriteByte(byte(l.Op))
+		b.WriteString(l.Text)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
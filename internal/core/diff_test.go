@@ -0,0 +1,23 @@
+package core
+
+import "testing"
+
+func TestDiffLines(t *testing.T) {
+	got := FormatDiff(DiffLines("a\nb\nc\nd", "a\nc\nx\nd\ne"))
+	want := " a\n-b\n c\n+x\n d\n+e\n"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiffLinesEdgeCases(t *testing.T) {
+	if d := DiffLines("same", "same"); len(d) != 1 || d[0].Op != DiffEqual {
+		t.Fatalf("expected single equal line, got %+v", d)
+	}
+	if got := FormatDiff(DiffLines("", "new")); got != "+new\n" {
+		t.Fatalf("expected pure insertion, got %q", got)
+	}
+	if got := FormatDiff(DiffLines("old teh", "old the")); got != "-old teh\n+old the\n" {
+		t.Fatalf("expected replacement, got %q", got)
+	}
+}
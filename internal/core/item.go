@@ -4,6 +4,9 @@ import (
 	"time"
 )
 
+// Item is one clipboard history entry. Capture stores Content normalized;
+// edits, saved transforms and imports store it as written. Capture and
+// edits keep it to MaxContentLen bytes.
 type Item struct {
 	ID          string      `json:"id"`
 	Content     string      `json:"content"`
@@ -14,4 +17,47 @@ type Item struct {
 	LastSeenAt time.Time `json:"last_seen_at"`
 
 	Pinned bool `json:"pinned"`
+
+	// Title and Note are user annotations; capture never sets them.
+	Title string `json:"title,omitempty"`
+	Note  string `json:"note,omitempty"`
+
+	Source Source `json:"source,omitzero"`
+
+	// ExpiresAt is set for short-lived items (e.g. concealed content kept
+	// with a TTL). Zero means the item never expires.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+
+	// DeletedAt is set while the item sits in the trash.
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+
+	// Meta holds annotations added by capture processors (e.g. "jira": "OPS-12").
+	Meta map[string]string `json:"meta,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Meta keys linking an item to the item it was derived from.
+const (
+	MetaDerivedFrom = "derived_from" // ID of the original item
+	MetaTransform   = "transform"    // transform that produced the content
+)
+
+// Items are shown by a short prefix of their ID; any unique prefix of at
+// least MinIDPrefix characters resolves back to the item.
+const (
+	ShortIDLen  = 7
+	MinIDPrefix = 4
+)
+
+// ShortID is a short, stable, typable handle for the item.
+func (it Item) ShortID() string {
+	if len(it.ID) <= ShortIDLen {
+		return it.ID
+	}
+	return it.ID[:ShortIDLen]
+}
+
+func (it Item) Expired(now time.Time) bool {
+	return !it.ExpiresAt.IsZero() && !now.Before(it.ExpiresAt)
 }
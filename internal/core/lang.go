@@ -0,0 +1,152 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+type Language string
+
+const (
+	LangGo         Language = "go"
+	LangPython     Language = "python"
+	LangTypeScript Language = "typescript"
+	LangJavaScript Language = "javascript"
+	LangRust       Language = "rust"
+	LangSQL        Language = "sql"
+	LangShell      Language = "shell"
+)
+
+// MetaLanguage is the Item.Meta key holding the Language of code, scripts,
+// SQL and command lines (see ItemLanguage).
+const MetaLanguage = "lang"
+
+// langFeature is a token pattern and how much a match counts toward its
+// language. Matches are counted per pattern up to maxFeatureHits.
+type langFeature struct {
+	re     *regexp.Regexp
+	weight float64
+}
+
+const (
+	maxFeatureHits = 3
+	minLangScore   = 3
+)
+
+var langModel = []struct {
+	lang     Language
+	features []langFeature
+}{
+	{LangGo, []langFeature{
+		{regexp.MustCompile(`(?m)^package \w+\s*$`), 6},
+		{regexp.MustCompile(`\bfunc (\(\w+ \*?\w+\) )?\w*\(`), 3},
+		{regexp.MustCompile(`:=`), 2},
+		{regexp.MustCompile(`\berr != nil\b`), 4},
+		{regexp.MustCompile(`\bfmt\.\w+\(`), 2},
+		{regexp.MustCompile(`(?m)^import \($`), 4},
+		{regexp.MustCompile(`\bchan\b|\bgo func\b|\bdefer\b`), 2},
+		{regexp.MustCompile(`\[\](string|int|byte|\*?\w+)\b`), 1},
+		{regexp.MustCompile(`(?m)\) \*?[\w.\[\]]+ \{$`), 3}, // ") error {"
+	}},
+	{LangPython, []langFeature{
+		{regexp.MustCompile(`(?m)^\s*def \w+\(.*\)( -> [\w\[\], ]+)?:\s*$`), 5},
+		{regexp.MustCompile(`(?m)^\s*class \w+(\(.*\))?:\s*$`), 4},
+		{regexp.MustCompile(`(?m)^\s*(if|elif|else|for|while|with|try|except|finally)\b[^{;]*:\s*$`), 2},
+		{regexp.MustCompile(`(?m)^from [\w.]+ import \w+`), 4},
+		{regexp.MustCompile(`\bself\b`), 2},
+		{regexp.MustCompile(`\b(None|True|False)\b`), 1},
+		{regexp.MustCompile(`\belif\b|\blambda\b|__\w+__`), 3},
+		{regexp.MustCompile(`\bprint\(|\bf"`), 1},
+	}},
+	{LangTypeScript, []langFeature{
+		{regexp.MustCompile(`[\w)]\??:\s*(string|number|boolean|any|void|unknown|never|Record<|Promise<)`), 4},
+		{regexp.MustCompile(`(?m)^\s*(export )?interface \w+`), 4},
+		{regexp.MustCompile(`(?m)^\s*(export )?type \w+(<.*>)? =`), 4},
+		{regexp.MustCompile(`\b(readonly|implements|private|public|enum)\b`), 2},
+		{regexp.MustCompile(`\bas (const|string|number|\w+)\b`), 1},
+		{regexp.MustCompile(`\w<[A-Z]?\w+(\[\])?>\(`), 3}, // useState<string>(
+		{regexp.MustCompile(`(?m)^import .* from ['"]`), 1},
+		{regexp.MustCompile(`=>`), 0.5},
+	}},
+	{LangJavaScript, []langFeature{
+		{regexp.MustCompile(`\bfunction\b`), 2},
+		{regexp.MustCompile(`\bconsole\.\w+\(`), 3},
+		{regexp.MustCompile(`\brequire\(['"]`), 4},
+		{regexp.MustCompile(`\bmodule\.exports\b`), 4},
+		{regexp.MustCompile(`\b(document|window)\.\w+`), 3},
+		{regexp.MustCompile(`===|!==`), 2},
+		{regexp.MustCompile(`\b(const|let|var) \w+ = `), 1},
+		{regexp.MustCompile(`(?m)^import .* from ['"]`), 1},
+		{regexp.MustCompile(`=>`), 0.5},
+	}},
+	{LangRust, []langFeature{
+		{regexp.MustCompile(`\bfn \w+(<.*>)?\(`), 4},
+		{regexp.MustCompile(`\blet mut\b`), 4},
+		{regexp.MustCompile(`(?m)^\s*impl\b`), 4},
+		{regexp.MustCompile(`\b\w+!\(`), 2},
+		{regexp.MustCompile(`(?m)^use \w+(::\w+)+`), 4},
+		{regexp.MustCompile(`&str\b|&mut\b|\bOption<|\bResult<`), 3},
+		{regexp.MustCompile(`::`), 1},
+		{regexp.MustCompile(`\bVec<|\bvec!\[|\|\w+\| `), 3},
+	}},
+	{LangSQL, []langFeature{
+		{regexp.MustCompile(`(?is)\bselect\b.+\bfrom\b`), 5},
+		{regexp.MustCompile(`(?i)\b(insert into|delete from|create table|alter table|drop table)\b`), 5},
+		{regexp.MustCompile(`(?i)\bupdate \w+ set\b`), 5},
+		{regexp.MustCompile(`(?i)\b(where|join|group by|order by|having|limit)\b`), 1.5},
+	}},
+	{LangShell, []langFeature{
+		{regexp.MustCompile(`(?m)^#!/(usr/)?bin/(env )?(ba|z|k)?sh`), 8},
+		{regexp.MustCompile(`(?m)^\s*(echo|export|cd|sudo|mkdir|rm|chmod|source|alias)\b`), 2},
+		{regexp.MustCompile(`(?m)^\s*if \[\[? `), 4},
+		{regexp.MustCompile(`(?m)^\s*(fi|done|esac)\s*$`), 4},
+		{regexp.MustCompile(`\$\(|\$\{\w+`), 2},
+		{regexp.MustCompile(`\$\w+`), 1},
+		{regexp.MustCompile(` \| \w+`), 1},
+		{regexp.MustCompile(`&&|\|\|`), 1},
+	}},
+}
+
+// ItemLanguage returns the language recorded in MetaLanguage for content
+// of type typ: detected for code and shell scripts, implied by the type
+// for SQL and command lines. It returns "" for other types.
+func ItemLanguage(typ ContentType, content string) Language {
+	switch typ {
+	case ContentTypeCode:
+		lang, _ := DetectLanguage(content)
+		return lang
+	case ContentTypeSQL:
+		return LangSQL
+	case ContentTypeCommand:
+		return LangShell
+	}
+	return ""
+}
+
+// DetectLanguage guesses the programming language of a code snippet using
+// a small weighted token model. It returns "" when nothing scores high
+// enough; the confidence is the winner's share of all scores.
+func DetectLanguage(code string) (Language, float64) {
+	if strings.TrimSpace(code) == "" {
+		return "", 0
+	}
+
+	var best Language
+	var bestScore, total float64
+	for _, m := range langModel {
+		score := 0.0
+		for _, f := range m.features {
+			hits := len(f.re.FindAllStringIndex(code, maxFeatureHits))
+			score += float64(hits) * f.weight
+		}
+		total += score
+		if score > bestScore {
+			best, bestScore = m.lang, score
+		}
+	}
+
+	if bestScore < minLangScore {
+		return "", 0
+	}
+	return best, bestScore / total
+}
@@ -0,0 +1,71 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDetectLanguageCorpus measures accuracy against testdata/lang/<lang>/*.
+func TestDetectLanguageCorpus(t *testing.T) {
+	dirs, err := os.ReadDir("testdata/lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	total, correct := 0, 0
+	for _, d := range dirs {
+		want := Language(d.Name())
+		files, err := filepath.Glob(filepath.Join("testdata/lang", d.Name(), "*.txt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			b, err := os.ReadFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			total++
+			got, conf := DetectLanguage(string(b))
+			if got == want {
+				correct++
+				continue
+			}
+			t.Logf("%s: expected %s, got %q (%.2f)", f, want, got, conf)
+		}
+	}
+
+	if total == 0 {
+		t.Fatal("empty corpus")
+	}
+	acc := float64(correct) / float64(total)
+	t.Logf("language accuracy: %d/%d (%.0f%%)", correct, total, acc*100)
+	if acc < 0.9 {
+		t.Fatalf("accuracy %.2f below 0.90", acc)
+	}
+}
+
+func TestDetectLanguageProse(t *testing.T) {
+	if got, _ := DetectLanguage("just some notes about the meeting"); got != "" {
+		t.Fatalf("expected no language for prose, got %s", got)
+	}
+}
+
+func TestItemLanguage(t *testing.T) {
+	cases := []struct {
+		typ     ContentType
+		content string
+		want    Language
+	}{
+		{ContentTypeCode, "#!/bin/sh\necho hi\n", LangShell},
+		{ContentTypeCode, "just some notes", ""},
+		{ContentTypeSQL, "select 1", LangSQL},
+		{ContentTypeCommand, "git status", LangShell},
+		{ContentTypeText, "SELECT id FROM users", ""},
+	}
+	for _, c := range cases {
+		if got := ItemLanguage(c.typ, c.content); got != c.want {
+			t.Errorf("ItemLanguage(%s, %q) = %q, want %q", c.typ, c.content, got, c.want)
+		}
+	}
+}
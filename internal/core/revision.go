@@ -0,0 +1,13 @@
+package core
+
+import "time"
+
+// Revision is a previous content of an edited item. Revisions are numbered
+// from 1 (the content as captured) upward; the item itself holds the
+// current content.
+type Revision struct {
+	ItemID     string    `json:"item_id"`
+	Rev        int       `json:"rev"`
+	Content    string    `json:"content"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
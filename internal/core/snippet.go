@@ -0,0 +1,12 @@
+package core
+
+import "time"
+
+// Snippet is a named, reusable template kept apart from history: it is
+// never evicted and may contain placeholders (see ExpandTemplate).
+type Snippet struct {
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Source describes the application that owned the clipboard when an item
+// was captured. All fields are optional; adapters fill what they can.
+type Source struct {
+	AppID       string `json:"app_id,omitempty"`       // e.g. bundle id or desktop file id
+	WindowClass string `json:"window_class,omitempty"` // e.g. X11 WM_CLASS
+	Process     string `json:"process,omitempty"`      // executable name
+}
+
+func (s Source) IsZero() bool {
+	return s.AppID == "" && s.WindowClass == "" && s.Process == ""
+}
+
+// Matches reports whether name matches any of the source fields
+// (case-insensitive, exact or substring).
+func (s Source) Matches(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return false
+	}
+	for _, f := range []string{s.AppID, s.WindowClass, s.Process} {
+		if f != "" && strings.Contains(strings.ToLower(f), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the most descriptive non-empty field.
+func (s Source) String() string {
+	switch {
+	case s.AppID != "":
+		return s.AppID
+	case s.WindowClass != "":
+		return s.WindowClass
+	default:
+		return s.Process
+	}
+}
+
+// AppRule applies to clips copied from a matching application.
+type AppRule struct {
+	App    string      // matched against Source via Source.Matches
+	Ignore bool        // never capture from this app
+	Type   ContentType // if set, overrides the detected type
+}
+
+type AppRules []AppRule
+
+// Match returns the first rule matching src.
+func (rs AppRules) Match(src Source) (AppRule, bool) {
+	if src.IsZero() {
+		return AppRule{}, false
+	}
+	for _, r := range rs {
+		if src.Matches(r.App) {
+			return r, true
+		}
+	}
+	return AppRule{}, false
+}
+
+// ParseAppRules parses a comma-separated list of app=action pairs where
+// action is "ignore" or a content type, e.g. "keepassxc=ignore,kitty=command".
+func ParseAppRules(s string) (AppRules, error) {
+	var out AppRules
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		app, action, ok := strings.Cut(part, "=")
+		app = strings.TrimSpace(app)
+		action = strings.ToLower(strings.TrimSpace(action))
+		if !ok || app == "" || action == "" {
+			return nil, fmt.Errorf("invalid app rule %q (expected app=action)", part)
+		}
+
+		r := AppRule{App: app}
+		if action == "ignore" {
+			r.Ignore = true
+		} else if t, ok := ParseContentType(action); ok {
+			r.Type = t
+		} else {
+			return nil, fmt.Errorf("invalid app rule action %q (expected ignore|%s)", action, ContentTypeNames("|"))
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
@@ -0,0 +1,36 @@
+package core
+
+import "testing"
+
+func TestParseAppRules(t *testing.T) {
+	rs, err := ParseAppRules("KeePassXC=ignore, kitty=command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rs))
+	}
+
+	r, ok := rs.Match(Source{WindowClass: "keepassxc"})
+	if !ok || !r.Ignore {
+		t.Fatalf("expected keepassxc to be ignored")
+	}
+
+	r, ok = rs.Match(Source{Process: "kitty"})
+	if !ok || r.Type != ContentTypeCommand {
+		t.Fatalf("expected kitty rule to set command type")
+	}
+
+	if _, ok := rs.Match(Source{AppID: "org.mozilla.firefox"}); ok {
+		t.Fatalf("did not expect firefox to match")
+	}
+}
+
+func TestParseAppRulesInvalid(t *testing.T) {
+	if _, err := ParseAppRules("kitty"); err == nil {
+		t.Fatalf("expected error for missing action")
+	}
+	if _, err := ParseAppRules("kitty=bogus"); err == nil {
+		t.Fatalf("expected error for unknown action")
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// NormalizeTag lowercases a tag and replaces inner whitespace with dashes.
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// NormalizeTags normalizes, dedupes and drops empty tags, keeping order.
+func NormalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		t = NormalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Collection is a named, user-curated group of items.
+type Collection struct {
+	Name      string    `json:"name"`
+	Count     int       `json:"count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TagRule adds Tag to items matching all of its non-empty conditions.
+type TagRule struct {
+	Tag     string
+	Type    ContentType    // item type must equal
+	App     string         // item source must match (Source.Matches)
+	Pattern *regexp.Regexp // content must match
+}
+
+type TagRules []TagRule
+
+// Apply returns the tags whose rules match it.
+func (rs TagRules) Apply(it Item) []string {
+	var out []string
+	for _, r := range rs {
+		if r.Type != "" && it.Type != r.Type {
+			continue
+		}
+		if r.App != "" && !it.Source.Matches(r.App) {
+			continue
+		}
+		if r.Pattern != nil && !r.Pattern.MatchString(it.Content) {
+			continue
+		}
+		out = append(out, r.Tag)
+	}
+	return NormalizeTags(out)
+}
+
+// ParseTagRules parses a semicolon-separated list of tag=condition pairs.
+// A condition is type:<type>, app:<name> or re:<regexp>; several can be
+// combined with "+", e.g. "jira=re:\b[A-Z]+-\d+\b;shell=app:kitty+type:command".
+// Since regexps may contain "+", re: must be the last condition.
+func ParseTagRules(s string) (TagRules, error) {
+	var out TagRules
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		tag, conds, ok := strings.Cut(part, "=")
+		tag = NormalizeTag(tag)
+		if !ok || tag == "" || strings.TrimSpace(conds) == "" {
+			return nil, fmt.Errorf("invalid tag rule %q (expected tag=condition)", part)
+		}
+
+		r := TagRule{Tag: tag}
+		for _, c := range splitConditions(conds) {
+			key, val, ok := strings.Cut(strings.TrimSpace(c), ":")
+			if !ok || val == "" {
+				return nil, fmt.Errorf("invalid tag rule condition %q (expected type:, app: or re:)", c)
+			}
+			switch key {
+			case "type":
+				t, ok := ParseContentType(val)
+				if !ok {
+					return nil, fmt.Errorf("invalid tag rule type %q", val)
+				}
+				r.Type = t
+			case "app":
+				r.App = val
+			case "re":
+				re, err := regexp.Compile(val)
+				if err != nil {
+					return nil, fmt.Errorf("invalid tag rule regexp %q: %w", val, err)
+				}
+				r.Pattern = re
+			default:
+				return nil, fmt.Errorf("invalid tag rule condition %q (expected type:, app: or re:)", c)
+			}
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// splitConditions splits on "+" up to the first re: condition, which takes
+// the rest of the string.
+func splitConditions(s string) []string {
+	var out []string
+	for s != "" {
+		if strings.HasPrefix(strings.TrimSpace(s), "re:") {
+			return append(out, s)
+		}
+		c, rest, _ := strings.Cut(s, "+")
+		out = append(out, c)
+		s = rest
+	}
+	return out
+}
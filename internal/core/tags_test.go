@@ -0,0 +1,39 @@
+package core
+
+import "testing"
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Work ", "work", "Side  Project", ""})
+	if len(got) != 2 || got[0] != "work" || got[1] != "side-project" {
+		t.Fatalf("unexpected tags: %v", got)
+	}
+}
+
+func TestTagRules(t *testing.T) {
+	rs, err := ParseTagRules(`jira=re:\b[A-Z]+-\d+\b; shell=app:kitty+type:command`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := rs.Apply(Item{Content: "see OPS-42", Type: ContentTypeText})
+	if len(got) != 1 || got[0] != "jira" {
+		t.Fatalf("expected jira tag, got %v", got)
+	}
+
+	got = rs.Apply(Item{Content: "ls -la", Type: ContentTypeCommand, Source: Source{Process: "kitty"}})
+	if len(got) != 1 || got[0] != "shell" {
+		t.Fatalf("expected shell tag, got %v", got)
+	}
+
+	if got := rs.Apply(Item{Content: "ls -la", Type: ContentTypeCommand}); len(got) != 0 {
+		t.Fatalf("expected no tags without matching app, got %v", got)
+	}
+}
+
+func TestParseTagRulesInvalid(t *testing.T) {
+	for _, s := range []string{"jira", "jira=bogus:x", "jira=type:nope", "jira=re:("} {
+		if _, err := ParseTagRules(s); err == nil {
+			t.Fatalf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TemplateEnv supplies values for snippet placeholders:
+//
+//	{{date}} {{date:<go layout>}} {{time}} {{datetime}}
+//	{{clipboard}} {{uuid}} {{input:<name>}}
+//
+// A nil function makes its placeholder an error.
+type TemplateEnv struct {
+	Now       func() time.Time
+	Clipboard func() (string, error)
+	UUID      func() string
+	// Input is asked once per distinct input name, in order of appearance.
+	Input func(name string) (string, error)
+}
+
+var ErrUnclosedPlaceholder = errors.New("unclosed placeholder")
+
+// Placeholder is one {{...}} occurrence in a template.
+type Placeholder struct {
+	Kind string // date, time, datetime, clipboard, uuid, input
+	Arg  string // layout for date, name for input
+}
+
+// TemplatePart is either literal text or a placeholder.
+type TemplatePart struct {
+	Literal     string
+	Placeholder *Placeholder
+}
+
+// ParseTemplate splits body into literal text and placeholders. "{{{{" is a
+// literal "{{".
+func ParseTemplate(body string) (parts []TemplatePart, err error) {
+	for body != "" {
+		i := strings.Index(body, "{{")
+		if i < 0 {
+			parts = append(parts, TemplatePart{Literal: body})
+			break
+		}
+		if strings.HasPrefix(body[i:], "{{{{") {
+			parts = append(parts, TemplatePart{Literal: body[:i] + "{{"})
+			body = body[i+4:]
+			continue
+		}
+		if i > 0 {
+			parts = append(parts, TemplatePart{Literal: body[:i]})
+		}
+		j := strings.Index(body[i:], "}}")
+		if j < 0 {
+			return nil, ErrUnclosedPlaceholder
+		}
+		ph, err := parsePlaceholder(body[i+2 : i+j])
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, TemplatePart{Placeholder: &ph})
+		body = body[i+j+2:]
+	}
+	return parts, nil
+}
+
+func parsePlaceholder(expr string) (Placeholder, error) {
+	expr = strings.TrimSpace(expr)
+	kind, arg, _ := strings.Cut(expr, ":")
+	ph := Placeholder{Kind: strings.ToLower(strings.TrimSpace(kind)), Arg: strings.TrimSpace(arg)}
+	switch ph.Kind {
+	case "date", "time", "datetime", "clipboard", "uuid":
+	case "input":
+		if ph.Arg == "" {
+			return Placeholder{}, fmt.Errorf("placeholder {{%s}}: input needs a name", expr)
+		}
+	default:
+		return Placeholder{}, fmt.Errorf("unknown placeholder {{%s}}", expr)
+	}
+	return ph, nil
+}
+
+// EscapeTemplate makes every "{{" in text literal except those opening a
+// valid placeholder, so text that uses braces for its own templating
+// (Helm, Go templates, Jinja, Mustache) can be turned into a template that
+// expands back to itself.
+func EscapeTemplate(text string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(text, "{{")
+		if i < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		b.WriteString(text[:i])
+		rest := text[i+2:]
+		if j := strings.Index(rest, "}}"); j >= 0 && !strings.Contains(rest[:j], "{{") {
+			if _, err := parsePlaceholder(rest[:j]); err == nil {
+				b.WriteString(text[i : i+2+j+2])
+				text = rest[j+2:]
+				continue
+			}
+		}
+		b.WriteString("{{{{")
+		text = rest
+	}
+}
+
+// TemplateInputs returns the distinct {{input:name}} names in body.
+func TemplateInputs(body string) ([]string, error) {
+	parts, err := ParseTemplate(body)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, p := range parts {
+		if ph := p.Placeholder; ph != nil && ph.Kind == "input" && !seen[ph.Arg] {
+			seen[ph.Arg] = true
+			names = append(names, ph.Arg)
+		}
+	}
+	return names, nil
+}
+
+// ExpandTemplate replaces every placeholder in body using env.
+func ExpandTemplate(body string, env TemplateEnv) (string, error) {
+	parts, err := ParseTemplate(body)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now
+	if env.Now != nil {
+		now = env.Now
+	}
+	inputs := make(map[string]string)
+
+	var b strings.Builder
+	for _, p := range parts {
+		ph := p.Placeholder
+		if ph == nil {
+			b.WriteString(p.Literal)
+			continue
+		}
+
+		switch ph.Kind {
+		case "date":
+			layout := "2006-01-02"
+			if ph.Arg != "" {
+				layout = ph.Arg
+			}
+			b.WriteString(now().Format(layout))
+		case "time":
+			b.WriteString(now().Format("15:04"))
+		case "datetime":
+			b.WriteString(now().Format("2006-01-02 15:04"))
+		case "uuid":
+			if env.UUID == nil {
+				return "", errors.New("{{uuid}} not available")
+			}
+			b.WriteString(env.UUID())
+		case "clipboard":
+			if env.Clipboard == nil {
+				return "", errors.New("{{clipboard}} not available")
+			}
+			txt, err := env.Clipboard()
+			if err != nil {
+				return "", fmt.Errorf("{{clipboard}}: %w", err)
+			}
+			b.WriteString(txt)
+		case "input":
+			v, ok := inputs[ph.Arg]
+			if !ok {
+				if env.Input == nil {
+					return "", fmt.Errorf("{{input:%s}} not available", ph.Arg)
+				}
+				v, err = env.Input(ph.Arg)
+				if err != nil {
+					return "", fmt.Errorf("{{input:%s}}: %w", ph.Arg, err)
+				}
+				inputs[ph.Arg] = v
+			}
+			b.WriteString(v)
+		}
+	}
+	return b.String(), nil
+}
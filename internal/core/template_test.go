@@ -0,0 +1,78 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	asked := 0
+	env := TemplateEnv{
+		Now:       func() time.Time { return now },
+		Clipboard: func() (string, error) { return "pod-123", nil },
+		UUID:      func() string { return "11111111-2222-3333-4444-555555555555" },
+		Input: func(name string) (string, error) {
+			asked++
+			return "kube-" + name, nil
+		},
+	}
+
+	got, err := ExpandTemplate("kubectl -n {{input:ns}} logs {{clipboard}} # {{date}} {{time}} {{ date:Jan 2 }} {{uuid}} {{input:ns}} {{{{literal}}", env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "kubectl -n kube-ns logs pod-123 # 2024-05-01 09:30 May 1 11111111-2222-3333-4444-555555555555 kube-ns {{literal}}"
+	if got != want {
+		t.Fatalf("expected\n%q\ngot\n%q", want, got)
+	}
+	if asked != 1 {
+		t.Fatalf("expected input asked once, got %d", asked)
+	}
+}
+
+func TestExpandTemplateErrors(t *testing.T) {
+	if _, err := ExpandTemplate("hello {{name", TemplateEnv{}); !errors.Is(err, ErrUnclosedPlaceholder) {
+		t.Fatalf("expected unclosed error, got %v", err)
+	}
+	if _, err := ExpandTemplate("{{bogus}}", TemplateEnv{}); err == nil {
+		t.Fatalf("expected unknown placeholder error")
+	}
+	if _, err := ExpandTemplate("{{clipboard}}", TemplateEnv{}); err == nil {
+		t.Fatalf("expected error without clipboard provider")
+	}
+	boom := errors.New("boom")
+	_, err := ExpandTemplate("{{input:x}}", TemplateEnv{Input: func(string) (string, error) { return "", boom }})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected input error to be wrapped, got %v", err)
+	}
+}
+
+func TestTemplateInputs(t *testing.T) {
+	names, err := TemplateInputs("{{input:ns}} {{date}} {{input:pod}} {{input:ns}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "ns" || names[1] != "pod" {
+		t.Fatalf("unexpected inputs: %v", names)
+	}
+}
+
+func TestEscapeTemplate(t *testing.T) {
+	for _, text := range []string{
+		"image: {{ .Values.image }}:{{ .Chart.AppVersion }}",
+		"{% if x %}{{ user.name | title }}{% endif %}",
+		"{{{{ four braces }} and {{ unclosed",
+		"{{{triple}}}",
+	} {
+		got, err := ExpandTemplate(EscapeTemplate(text), TemplateEnv{})
+		if err != nil || got != text {
+			t.Errorf("%q: expanded to %q, %v", text, got, err)
+		}
+	}
+
+	if got := EscapeTemplate("{{ .Name }} on {{date}}"); got != "{{{{ .Name }} on {{date}}" {
+		t.Fatalf("known placeholders should stay active, got %q", got)
+	}
+}
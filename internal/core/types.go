@@ -1,5 +1,7 @@
 package core
 
+import "strings"
+
 type ContentType string
 
 const (
@@ -7,4 +9,54 @@ const (
 	ContentTypeURL     ContentType = "url"
 	ContentTypeCommand ContentType = "command"
 	ContentTypeCode    ContentType = "code"
+
+	ContentTypeEmail      ContentType = "email"
+	ContentTypePhone      ContentType = "phone"
+	ContentTypeIP         ContentType = "ip" // address or CIDR
+	ContentTypeUUID       ContentType = "uuid"
+	ContentTypeColor      ContentType = "color" // hex / rgb() / hsl()
+	ContentTypePath       ContentType = "path"
+	ContentTypeJSON       ContentType = "json"
+	ContentTypeYAML       ContentType = "yaml"
+	ContentTypeSQL        ContentType = "sql"
+	ContentTypeMarkdown   ContentType = "markdown"
+	ContentTypeDiff       ContentType = "diff"
+	ContentTypeStackTrace ContentType = "stacktrace"
+	ContentTypeBase64     ContentType = "base64"
+	ContentTypeTimestamp  ContentType = "timestamp"
 )
+
+var contentTypes = []ContentType{
+	ContentTypeText, ContentTypeURL, ContentTypeCommand, ContentTypeCode,
+	ContentTypeEmail, ContentTypePhone, ContentTypeIP, ContentTypeUUID,
+	ContentTypeColor, ContentTypePath, ContentTypeJSON, ContentTypeYAML,
+	ContentTypeSQL, ContentTypeMarkdown, ContentTypeDiff, ContentTypeStackTrace,
+	ContentTypeBase64, ContentTypeTimestamp,
+}
+
+// ContentTypes returns every known content type.
+func ContentTypes() []ContentType {
+	out := make([]ContentType, len(contentTypes))
+	copy(out, contentTypes)
+	return out
+}
+
+// ParseContentType parses a content type name (case-insensitive).
+func ParseContentType(s string) (ContentType, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for _, t := range contentTypes {
+		if string(t) == s {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// ContentTypeNames returns the known types joined by sep, for usage strings.
+func ContentTypeNames(sep string) string {
+	names := make([]string, len(contentTypes))
+	for i, t := range contentTypes {
+		names[i] = string(t)
+	}
+	return strings.Join(names, sep)
+}
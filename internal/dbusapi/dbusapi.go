@@ -0,0 +1,243 @@
+// Package dbusapi exports the clipboard history on the session bus as
+// org.otterclip.History, for desktop shells, extensions and scripts:
+//
+//	busctl --user call org.otterclip.History /org/otterclip/History \
+//		org.otterclip.History Search si "docker" 5
+//
+// The interface is described in org.otterclip.History.xml, generated from
+// this package with go generate.
+package dbusapi
+
+//go:generate go test -run TestIntrospectionXML -update
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/desktop"
+)
+
+const (
+	// Name is the well-known bus name, also the interface name.
+	Name = "org.otterclip.History"
+	Path = dbus.ObjectPath("/org/otterclip/History")
+)
+
+// History is what the bus object serves; *desktop.App implements it.
+type History interface {
+	Search(query string, limit int) ([]core.Item, error)
+	GetItem(id string) (core.Item, error)
+	Copy(id string) error
+	Pin(id string, pinned bool) error
+	SetPaused(paused bool) error
+	Settings() desktop.Settings
+}
+
+// Item is a history item on the bus, signature (ssssbxs). LastSeen is in
+// Unix seconds; Source names the application it was copied from.
+type Item struct {
+	ID       string
+	Type     string
+	Title    string
+	Content  string
+	Pinned   bool
+	LastSeen int64
+	Source   string
+}
+
+func toItem(it core.Item) Item {
+	return Item{
+		ID:       it.ID,
+		Type:     string(it.Type),
+		Title:    it.Title,
+		Content:  it.Content,
+		Pinned:   it.Pinned,
+		LastSeen: it.LastSeenAt.Unix(),
+		Source:   it.Source.String(),
+	}
+}
+
+// Service is the exported object.
+type Service struct {
+	conn  *dbus.Conn
+	props *prop.Properties
+}
+
+// Connect exports h on the session bus. It fails if another instance
+// already owns Name.
+func Connect(h History) (*Service, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("dbus: session bus: %w", err)
+	}
+	s, err := Export(conn, h)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Export serves h on conn and takes Name; Close closes conn.
+func Export(conn *dbus.Conn, h History) (*Service, error) {
+	s := &Service{conn: conn}
+	if err := conn.Export(handler{h}, Path, Name); err != nil {
+		return nil, fmt.Errorf("dbus: export: %w", err)
+	}
+	props, err := prop.Export(conn, Path, prop.Map{Name: {
+		"Paused": {Value: h.Settings().Paused, Emit: prop.EmitTrue},
+	}})
+	if err != nil {
+		return nil, fmt.Errorf("dbus: export: %w", err)
+	}
+	s.props = props
+	node := Introspection()
+	if err := conn.Export(introspect.NewIntrospectable(&node), Path, "org.freedesktop.DBus.Introspectable"); err != nil {
+		return nil, fmt.Errorf("dbus: export: %w", err)
+	}
+
+	reply, err := conn.RequestName(Name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return nil, fmt.Errorf("dbus: request %s: %w", Name, err)
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return nil, fmt.Errorf("dbus: %s is owned by another instance", Name)
+	}
+	return s, nil
+}
+
+// Forward relays app events to the bus: captures become ItemCaptured
+// signals and settings changes update the Paused property. Pass it to
+// desktop.Listen.
+func (s *Service) Forward(_ context.Context, name string, data ...any) {
+	if len(data) == 0 {
+		return
+	}
+	switch name {
+	case desktop.EventCaptured:
+		if it, ok := data[0].(core.Item); ok {
+			s.conn.Emit(Path, Name+".ItemCaptured", toItem(it))
+		}
+	case desktop.EventSettings:
+		if st, ok := data[0].(desktop.Settings); ok {
+			s.props.SetMust(Name, "Paused", st.Paused)
+		}
+	}
+}
+
+// Close releases the name and closes the connection.
+func (s *Service) Close() error {
+	s.conn.ReleaseName(Name)
+	return s.conn.Close()
+}
+
+// maxResults bounds Search so a script can't ask for the whole history
+// in one message.
+const maxResults = 1000
+
+// handler holds the bus methods.
+type handler struct{ h History }
+
+func (h handler) Search(query string, limit int32) ([]Item, *dbus.Error) {
+	if limit <= 0 || limit > maxResults {
+		limit = maxResults
+	}
+	items, err := h.h.Search(query, int(limit))
+	if err != nil {
+		return nil, busError(err)
+	}
+	out := make([]Item, len(items))
+	for i, it := range items {
+		out[i] = toItem(it)
+	}
+	return out, nil
+}
+
+func (h handler) GetItem(id string) (Item, *dbus.Error) {
+	it, err := h.h.GetItem(id)
+	if err != nil {
+		return Item{}, busError(err)
+	}
+	return toItem(it), nil
+}
+
+func (h handler) Copy(id string) *dbus.Error {
+	return busError(h.h.Copy(id))
+}
+
+func (h handler) Pin(id string, pinned bool) *dbus.Error {
+	return busError(h.h.Pin(id, pinned))
+}
+
+func (h handler) Pause(paused bool) *dbus.Error {
+	return busError(h.h.SetPaused(paused))
+}
+
+// ErrNotFound is the D-Bus error name for unknown item IDs.
+const ErrNotFound = Name + ".Error.NotFound"
+
+func busError(err error) *dbus.Error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, desktop.ErrNoItem) {
+		return dbus.NewError(ErrNotFound, []any{err.Error()})
+	}
+	return dbus.MakeFailedError(err)
+}
+
+// argNames names the arguments introspect.Methods derives from handler,
+// in order.
+var argNames = map[string][]string{
+	"Search":  {"query", "limit", "items"},
+	"GetItem": {"id", "item"},
+	"Copy":    {"id"},
+	"Pin":     {"id", "pinned"},
+	"Pause":   {"paused"},
+}
+
+// Introspection describes the exported object.
+func Introspection() introspect.Node {
+	methods := introspect.Methods(handler{})
+	for i, m := range methods {
+		for j := range m.Args {
+			methods[i].Args[j].Name = argNames[m.Name][j]
+		}
+	}
+	item := dbus.SignatureOf(Item{}).String()
+	return introspect.Node{
+		Name: string(Path),
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{
+				Name:    Name,
+				Methods: methods,
+				Signals: []introspect.Signal{
+					{Name: "ItemCaptured", Args: []introspect.Arg{{Name: "item", Type: item}}},
+				},
+				Properties: []introspect.Property{{Name: "Paused", Type: "b", Access: "read"}},
+			},
+		},
+	}
+}
+
+const doctype = `<!DOCTYPE node PUBLIC "-//freedesktop//DTD D-BUS Object Introspection 1.0//EN"
+ "http://www.freedesktop.org/standards/dbus/1.0/introspect.dtd">
+`
+
+// XML returns the introspection document.
+func XML() ([]byte, error) {
+	b, err := xml.MarshalIndent(Introspection(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(doctype), append(b, '\n')...), nil
+}
@@ -0,0 +1,287 @@
+package dbusapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/desktop"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+var update = flag.Bool("update", false, "rewrite "+xmlFile)
+
+const xmlFile = "org.otterclip.History.xml"
+
+func TestIntrospectionXML(t *testing.T) {
+	got, err := XML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(xmlFile, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(xmlFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("%s is stale; run go generate ./internal/dbusapi", xmlFile)
+	}
+	for _, m := range Introspection().Interfaces[2].Methods {
+		for _, a := range m.Args {
+			if a.Name == "" {
+				t.Errorf("%s has an unnamed argument", m.Name)
+			}
+		}
+	}
+}
+
+// fakeHistory is a History over a fixed list. Bus methods run on godbus
+// goroutines, so it locks.
+type fakeHistory struct {
+	mu       sync.Mutex
+	items    []core.Item
+	copied   string
+	settings desktop.Settings
+}
+
+func (f *fakeHistory) Search(query string, limit int) ([]core.Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []core.Item
+	for _, it := range f.items {
+		if strings.Contains(it.Content, query) && len(out) < limit {
+			out = append(out, it)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeHistory) GetItem(id string) (core.Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.get(id)
+}
+
+func (f *fakeHistory) get(id string) (core.Item, error) {
+	for _, it := range f.items {
+		if it.ID == id {
+			return it, nil
+		}
+	}
+	return core.Item{}, fmt.Errorf("%w %q", desktop.ErrNoItem, id)
+}
+
+func (f *fakeHistory) Copy(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	it, err := f.get(id)
+	f.copied = it.Content
+	return err
+}
+
+func (f *fakeHistory) Pin(id string, pinned bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.items {
+		if f.items[i].ID == id {
+			f.items[i].Pinned = pinned
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q", desktop.ErrNoItem, id)
+}
+
+func (f *fakeHistory) SetPaused(paused bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.settings.Paused = paused
+	return nil
+}
+
+func (f *fakeHistory) Settings() desktop.Settings {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.settings
+}
+
+// check reports whether the fake's state satisfies ok.
+func (f *fakeHistory) check(ok func(*fakeHistory) bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return ok(f)
+}
+
+// startBus runs a private session bus for the test, skipping when
+// dbus-daemon isn't installed.
+func startBus(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address=1", "--address=unix:dir="+t.TempDir())
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	addr, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatalf("dbus-daemon: %v", err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func connect(t *testing.T, addr string) *dbus.Conn {
+	t.Helper()
+	conn, err := dbus.Connect(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func TestService(t *testing.T) {
+	addr := startBus(t)
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h := &fakeHistory{items: []core.Item{
+		{ID: "a1", Content: "docker ps", Type: core.ContentTypeText, LastSeenAt: seen, Source: core.Source{AppID: "kitty"}},
+		{ID: "b2", Content: "docker compose up", Type: core.ContentTypeText, LastSeenAt: seen},
+		{ID: "c3", Content: "git status", Type: core.ContentTypeText, LastSeenAt: seen},
+	}}
+	svc, err := Export(connect(t, addr), h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Close()
+	second := connect(t, addr)
+	defer second.Close()
+	if _, err := Export(second, h); err == nil {
+		t.Fatal("expected a second instance to be refused")
+	}
+
+	client := connect(t, addr)
+	defer client.Close()
+	obj := client.Object(Name, Path)
+
+	var items []Item
+	if err := obj.Call(Name+".Search", 0, "docker", int32(1)).Store(&items); err != nil {
+		t.Fatal(err)
+	}
+	want := Item{ID: "a1", Type: string(core.ContentTypeText), Content: "docker ps", LastSeen: seen.Unix(), Source: "kitty"}
+	if len(items) != 1 || items[0] != want {
+		t.Fatalf("Search = %+v, want [%+v]", items, want)
+	}
+
+	var it Item
+	if err := obj.Call(Name+".GetItem", 0, "c3").Store(&it); err != nil || it.Content != "git status" {
+		t.Fatalf("GetItem = %+v (%v)", it, err)
+	}
+	err = obj.Call(Name+".GetItem", 0, "zz").Err
+	if derr, ok := err.(dbus.Error); !ok || derr.Name != ErrNotFound {
+		t.Fatalf("expected %s, got %v", ErrNotFound, err)
+	}
+
+	err = obj.Call(Name+".Copy", 0, "b2").Err
+	if err != nil || !h.check(func(f *fakeHistory) bool { return f.copied == "docker compose up" }) {
+		t.Fatalf("Copy didn't copy (%v)", err)
+	}
+	err = obj.Call(Name+".Pin", 0, "b2", true).Err
+	if err != nil || !h.check(func(f *fakeHistory) bool { return f.items[1].Pinned }) {
+		t.Fatalf("Pin didn't pin (%v)", err)
+	}
+	if err := obj.Call(Name+".Pause", 0, true).Err; err != nil || !h.Settings().Paused {
+		t.Fatalf("Pause didn't pause (%v)", err)
+	}
+	svc.Forward(context.Background(), desktop.EventSettings, h.Settings())
+	if v, err := obj.GetProperty(Name + ".Paused"); err != nil || v.Value() != true {
+		t.Fatalf("Paused = %v (%v)", v, err)
+	}
+
+	if err := client.AddMatchSignal(dbus.WithMatchInterface(Name), dbus.WithMatchMember("ItemCaptured")); err != nil {
+		t.Fatal(err)
+	}
+	signals := make(chan *dbus.Signal, 1)
+	client.Signal(signals)
+	captured, _ := h.GetItem("c3")
+	svc.Forward(context.Background(), desktop.EventCaptured, captured)
+	select {
+	case sig := <-signals:
+		var got Item
+		if err := dbus.Store(sig.Body, &got); err != nil || got.ID != "c3" {
+			t.Fatalf("ItemCaptured carried %+v (%v)", got, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no ItemCaptured signal")
+	}
+
+	var xml string
+	if err := client.Object(Name, Path).Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xml); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(xml, `<method name="Pause">`) {
+		t.Fatalf("introspection lacks Pause:\n%s", xml)
+	}
+}
+
+// idleClipboard never changes.
+type idleClipboard struct{}
+
+func (idleClipboard) Watch(ctx context.Context) (<-chan struct{}, error) {
+	return make(chan struct{}), nil
+}
+func (idleClipboard) ReadText() (string, error)   { return "", nil }
+func (idleClipboard) WriteText(text string) error { return nil }
+
+func newApp(st *memory.Store) *desktop.App {
+	return desktop.New(st, capture.New(st, nil, capture.Config{}), search.New(st), idleClipboard{}, nil)
+}
+
+func TestService_PausedAfterRestart(t *testing.T) {
+	addr := startBus(t)
+	st := memory.New()
+	if err := newApp(st).SaveSettings(desktop.Settings{Paused: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// as in the app: exported before Startup loads the stored settings
+	app := newApp(st)
+	svc, err := Export(connect(t, addr), app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Close()
+	desktop.Listen(app, svc.Forward)
+	if err := desktop.Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer desktop.Shutdown(app)
+
+	client := connect(t, addr)
+	defer client.Close()
+	if v, err := client.Object(Name, Path).GetProperty(Name + ".Paused"); err != nil || v.Value() != true {
+		t.Fatalf("Paused = %v (%v)", v, err)
+	}
+}
@@ -0,0 +1,514 @@
+// Package desktop is the binding layer between the Wails webview and the
+// clipboard services: every exported method of App is callable from the
+// frontend, and new captures are pushed to it as runtime events.
+package desktop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+	"github.com/its-jojoo/otterclip/internal/adapter/paste"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/tray"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/queue"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+	"github.com/its-jojoo/otterclip/internal/usecase/transform"
+)
+
+// Events emitted to the frontend.
+const (
+	// EventCaptured carries the newly captured core.Item.
+	EventCaptured = "clip:captured"
+	// EventChanged means the history changed (capture, pin, delete, ...)
+	// and listings should be reloaded.
+	EventChanged = "history:changed"
+	// EventError carries a background error message (watcher, purge).
+	EventError = "app:error"
+	// EventShown is emitted when the hotkey brings the launcher up, so
+	// the search box can take focus.
+	EventShown = "launcher:shown"
+	// EventSettings carries the Settings after they change, including
+	// from the tray.
+	EventSettings = "settings:changed"
+)
+
+const settingsKey = "desktop.settings"
+
+var errHotkey = errors.New("can't bind hotkey")
+
+// ErrNoItem is returned for IDs that match no item.
+var ErrNoItem = errors.New("no such item")
+
+// Store is the storage the desktop app needs.
+type Store interface {
+	storage.Store
+	storage.TrashStore
+	storage.ItemFinder
+	storage.SettingsStore
+}
+
+// Emitter sends an event to the frontend; runtime.EventsEmit in the app.
+// A nil Emitter drops events.
+type Emitter func(ctx context.Context, name string, data ...any)
+
+// Settings are the preferences the frontend can change. They are applied
+// at once and persisted in the store.
+type Settings struct {
+	// Paused stops capturing until unset.
+	Paused bool `json:"paused"`
+	// IgnorePatterns are extra privacy patterns; matching clips are not
+	// captured.
+	IgnorePatterns []string `json:"ignorePatterns"`
+	// IgnoreRegex treats IgnorePatterns as regular expressions.
+	IgnoreRegex bool `json:"ignoreRegex"`
+	// Hotkey toggles the launcher, e.g. "Super+V"; empty means
+	// DefaultHotkey. It is grabbed from every application, so avoid
+	// chords they rely on, such as the terminals' Ctrl+Shift+V.
+	Hotkey string `json:"hotkey"`
+	// PasteChords overrides the paste keystroke per application, as
+	// "app=chord" pairs, e.g. "emacs=Ctrl+Y"; terminals already get
+	// Ctrl+Shift+V.
+	PasteChords string `json:"pasteChords"`
+}
+
+// TransformInfo describes a text transform for the frontend.
+type TransformInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// App is bound to the Wails runtime. Its methods run on webview goroutines
+// while the watcher captures on its own, so shared state is locked.
+type App struct {
+	store     Store
+	capture   *capture.Service
+	search    *search.Service
+	transform *transform.Service
+	cb        clipboard.System
+	emit      Emitter
+
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	done     chan struct{}
+	settings Settings
+	ignore   *core.PrivacyFilter
+
+	hotkeys *hotkey.Manager
+	bound   *hotkey.Accelerator
+	window  Window
+	visible bool
+
+	paster paste.Paster
+	target core.Source // focused app when the launcher was summoned
+	chords paste.ChordRules
+
+	tray      tray.Tray
+	trayDirty chan struct{}
+
+	backups     *backup.Manager
+	backupEvery time.Duration
+
+	queue       *queue.Service
+	queueSettle time.Duration
+}
+
+// New builds the app. It registers a filter on svc so the ignore patterns
+// from Settings apply to every capture.
+func New(store Store, svc *capture.Service, searchSvc *search.Service, cb clipboard.System, emit Emitter) *App {
+	a := &App{
+		store:     store,
+		capture:   svc,
+		search:    searchSvc,
+		transform: transform.New(store),
+		cb:        cb,
+		emit:      emit,
+		ctx:       context.Background(),
+	}
+	if a.emit == nil {
+		a.emit = func(context.Context, string, ...any) {}
+	}
+	svc.Use(capture.StageFilter, capture.ProcessorFunc("desktop-ignore", a.filterIgnored))
+	return a
+}
+
+// Listen also sends every event to l, e.g. to relay captures over D-Bus.
+// Call it before Startup.
+func Listen(a *App, l Emitter) {
+	emit := a.emit
+	a.emit = func(ctx context.Context, name string, data ...any) {
+		emit(ctx, name, data...)
+		l(ctx, name, data...)
+	}
+}
+
+// Startup loads the settings, binds the hotkey and starts the clipboard
+// watcher. It is a function rather than a method so the frontend can't
+// call it; wire it to the Wails OnStartup hook. The loaded settings are
+// announced with EventSettings, so listeners registered before the
+// settings were known catch up; settings that can't be applied (a hotkey
+// taken by another app) are reported with EventError.
+func Startup(a *App, ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	a.mu.Lock()
+	a.ctx, a.cancel = ctx, cancel
+	a.done = make(chan struct{})
+	a.mu.Unlock()
+
+	s, err := a.loadSettings(ctx)
+	if err != nil {
+		close(a.done)
+		return err
+	}
+	if err := a.apply(s); err != nil {
+		a.emit(ctx, EventError, "settings: "+err.Error())
+	}
+	a.emit(ctx, EventSettings, a.Settings())
+
+	a.mu.Lock()
+	hotkeys, t, dirty := a.hotkeys, a.tray, a.trayDirty
+	backups, every := a.backups, a.backupEvery
+	a.mu.Unlock()
+	go func() {
+		defer close(a.done)
+		if hotkeys != nil {
+			go hotkeys.Run(ctx)
+		}
+		if t != nil {
+			go a.runTray(ctx, t, dirty)
+		}
+		if backups != nil {
+			go backups.Run(ctx, every, func(err error) {
+				a.emit(ctx, EventError, "backup: "+err.Error())
+			})
+		}
+		a.watch(ctx)
+	}()
+	return nil
+}
+
+// Shutdown stops the watcher and waits for it; wire it to OnShutdown.
+func Shutdown(a *App) {
+	a.mu.Lock()
+	cancel, done := a.cancel, a.done
+	a.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	if a.hotkeys != nil {
+		a.hotkeys.Close()
+	}
+	if a.tray != nil {
+		a.tray.Close()
+	}
+}
+
+func (a *App) context() context.Context {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ctx
+}
+
+// List returns the most recent items, pinned or not.
+func (a *App) List(limit int) ([]core.Item, error) {
+	items, err := a.store.ListRecent(a.context(), clampLimit(limit))
+	if items == nil && err == nil {
+		items = []core.Item{}
+	}
+	return items, err
+}
+
+// Search runs a query (with "app:", "type:", "tag:" and "lang:" filters);
+// an empty query lists recent items.
+func (a *App) Search(query string, limit int) ([]core.Item, error) {
+	if strings.TrimSpace(query) == "" {
+		return a.List(limit)
+	}
+	limit = clampLimit(limit)
+	items, err := a.search.Query(a.context(), query, search.Options{ScanLimit: max(1000, limit*4), OutLimit: limit})
+	if items == nil && err == nil {
+		items = []core.Item{}
+	}
+	return items, err
+}
+
+// GetItem returns an item by its full ID.
+func (a *App) GetItem(id string) (core.Item, error) {
+	return a.item(id)
+}
+
+// Pin pins or unpins an item.
+func (a *App) Pin(id string, pinned bool) error {
+	return a.change(a.store.SetPinned(a.context(), id, pinned))
+}
+
+// Delete moves an item to the trash.
+func (a *App) Delete(id string) error {
+	return a.change(a.store.Trash(a.context(), id))
+}
+
+// ClearHistory moves every unpinned item to the trash and returns how
+// many it moved.
+func (a *App) ClearHistory() (int, error) {
+	ctx := a.context()
+	n, err := a.store.Count(ctx)
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	items, err := a.store.ListRecent(ctx, n)
+	if err != nil {
+		return 0, err
+	}
+	cleared := 0
+	for _, it := range items {
+		if it.Pinned {
+			continue
+		}
+		if err = a.store.Trash(ctx, it.ID); err != nil {
+			break
+		}
+		cleared++
+	}
+	if cleared > 0 {
+		a.change(nil)
+	}
+	return cleared, err
+}
+
+// Restore takes an item out of the trash.
+func (a *App) Restore(id string) error {
+	return a.change(a.store.Restore(a.context(), id))
+}
+
+// SetTitle sets an item's title; empty clears it.
+func (a *App) SetTitle(id, title string) error {
+	return a.change(a.store.SetTitle(a.context(), id, title))
+}
+
+// SetNote sets an item's note; empty clears it.
+func (a *App) SetNote(id, note string) error {
+	return a.change(a.store.SetNote(a.context(), id, note))
+}
+
+// Copy puts an item's content on the system clipboard.
+func (a *App) Copy(id string) error {
+	it, err := a.item(id)
+	if err != nil {
+		return err
+	}
+	return a.cb.WriteText(it.Content)
+}
+
+// Transforms lists the available text transforms.
+func (a *App) Transforms() []TransformInfo {
+	all := transform.All()
+	out := make([]TransformInfo, len(all))
+	for i, t := range all {
+		out[i] = TransformInfo{Name: t.Name, Description: t.Description}
+	}
+	return out
+}
+
+// CopyTransformed copies an item's content through a transform and
+// returns the result.
+func (a *App) CopyTransformed(id, name string) (string, error) {
+	it, err := a.item(id)
+	if err != nil {
+		return "", err
+	}
+	return a.transform.Copy(it, name, a.cb)
+}
+
+// Settings returns the current settings.
+func (a *App) Settings() Settings {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.settings
+}
+
+// SetPaused pauses or resumes capturing, keeping the other settings.
+func (a *App) SetPaused(paused bool) error {
+	s := a.Settings()
+	s.Paused = paused
+	return a.SaveSettings(s)
+}
+
+// SaveSettings validates, applies and persists s. If only the hotkey
+// can't be bound, the rest is still saved and the error returned.
+func (a *App) SaveSettings(s Settings) error {
+	applyErr := a.apply(s)
+	if applyErr != nil && !errors.Is(applyErr, errHotkey) {
+		return applyErr
+	}
+	b, err := json.Marshal(a.Settings())
+	if err != nil {
+		return err
+	}
+	if err := a.store.SetSetting(a.context(), settingsKey, string(b)); err != nil {
+		return err
+	}
+	a.emit(a.context(), EventSettings, a.Settings())
+	a.refreshTray()
+	return applyErr
+}
+
+func (a *App) loadSettings(ctx context.Context) (Settings, error) {
+	var s Settings
+	raw, ok, err := a.store.GetSetting(ctx, settingsKey)
+	if err != nil || !ok {
+		return s, err
+	}
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		return s, fmt.Errorf("stored settings: %w", err)
+	}
+	return s, nil
+}
+
+func (a *App) apply(s Settings) error {
+	accel, err := hotkeyOf(s)
+	if err != nil {
+		return err
+	}
+	var ignore *core.PrivacyFilter
+	if len(s.IgnorePatterns) > 0 {
+		pf, err := core.NewPrivacyFilter(s.IgnorePatterns, s.IgnoreRegex)
+		if err != nil {
+			return err
+		}
+		ignore = pf
+	}
+	chords, err := paste.ParseChordRules(s.PasteChords)
+	if err != nil {
+		return err
+	}
+	// bind last: a shortcut taken by another app shouldn't block the rest
+	bindErr := a.bindHotkey(accel)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if bindErr != nil {
+		s.Hotkey = a.settings.Hotkey
+	}
+	a.settings, a.ignore, a.chords = s, ignore, chords
+	if bindErr != nil {
+		return fmt.Errorf("%w %s: %v", errHotkey, accel, bindErr)
+	}
+	return nil
+}
+
+func (a *App) filterIgnored(_ context.Context, it *core.Item) error {
+	a.mu.Lock()
+	ignore := a.ignore
+	a.mu.Unlock()
+	if ignore != nil && ignore.ShouldIgnore(it.Content) {
+		return capture.ErrDrop
+	}
+	return nil
+}
+
+// item looks an item up by its full ID.
+func (a *App) item(id string) (core.Item, error) {
+	if len(id) < core.MinIDPrefix {
+		return core.Item{}, fmt.Errorf("invalid item id %q", id)
+	}
+	items, err := a.store.FindByIDPrefix(a.context(), id, 2)
+	if err != nil {
+		return core.Item{}, err
+	}
+	for _, it := range items {
+		if it.ID == id {
+			return it, nil
+		}
+	}
+	return core.Item{}, fmt.Errorf("%w %q", ErrNoItem, id)
+}
+
+// change tells the frontend the history changed when err is nil.
+func (a *App) change(err error) error {
+	if err == nil {
+		a.emit(a.context(), EventChanged)
+		a.refreshTray()
+	}
+	return err
+}
+
+// watch captures every clipboard change until ctx is done.
+func (a *App) watch(ctx context.Context) {
+	events, err := a.cb.Watch(ctx)
+	if err != nil {
+		a.emit(ctx, EventError, "clipboard watcher: "+err.Error())
+		return
+	}
+
+	sr, _ := a.cb.(clipboard.SourceReader)
+	tr, _ := a.cb.(clipboard.TargetReader)
+
+	// expired (concealed) items must go away even if nothing new is copied
+	purge := time.NewTicker(10 * time.Second)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-purge.C:
+			if err := a.capture.PurgeExpired(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				a.emit(ctx, EventError, "purge: "+err.Error())
+			}
+			continue
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		}
+		if a.Settings().Paused {
+			continue
+		}
+
+		txt, err := a.cb.ReadText()
+		if err != nil {
+			continue
+		}
+		clip := capture.Clip{Text: txt}
+		if sr != nil {
+			clip.Source, _ = sr.ReadSource()
+		}
+		if tr != nil {
+			clip.Targets, _ = tr.ReadTargets()
+		}
+		it, saved, err := a.capture.ProcessClip(ctx, clip)
+		if err != nil {
+			a.emit(ctx, EventError, "capture: "+err.Error())
+			continue
+		}
+		if !saved {
+			continue
+		}
+		// a duplicate merges into the existing row; report that one
+		if stored, err := a.store.FindByFingerprint(ctx, it.Fingerprint); err == nil {
+			it = &stored
+		}
+		a.emit(ctx, EventCaptured, *it)
+		a.emit(ctx, EventChanged)
+		a.refreshTray()
+	}
+}
+
+func clampLimit(n int) int {
+	if n <= 0 {
+		return 50
+	}
+	return min(n, 1000)
+}
@@ -0,0 +1,293 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+// fakeClipboard is a clipboard.System whose changes are driven by copy.
+type fakeClipboard struct {
+	mu     sync.Mutex
+	text   string
+	events chan struct{}
+}
+
+func newFakeClipboard() *fakeClipboard {
+	return &fakeClipboard{events: make(chan struct{})}
+}
+
+func (f *fakeClipboard) Watch(ctx context.Context) (<-chan struct{}, error) {
+	return f.events, nil
+}
+
+func (f *fakeClipboard) ReadText() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.text, nil
+}
+
+func (f *fakeClipboard) WriteText(text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.text = text
+	return nil
+}
+
+// copy simulates another application copying text.
+func (f *fakeClipboard) copy(text string) {
+	f.WriteText(text)
+	f.events <- struct{}{}
+}
+
+type event struct {
+	name string
+	data []any
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []event
+	signal chan struct{}
+}
+
+func (r *recorder) emit(_ context.Context, name string, data ...any) {
+	r.mu.Lock()
+	r.events = append(r.events, event{name, data})
+	r.mu.Unlock()
+	select {
+	case r.signal <- struct{}{}:
+	default:
+	}
+}
+
+// wait blocks until an event with the given name has been emitted.
+func (r *recorder) wait(t *testing.T, name string) event {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		r.mu.Lock()
+		for i, e := range r.events {
+			if e.name == name {
+				r.events = append(r.events[:i], r.events[i+1:]...)
+				r.mu.Unlock()
+				return e
+			}
+		}
+		r.mu.Unlock()
+		select {
+		case <-r.signal:
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+}
+
+func (r *recorder) count(name string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, e := range r.events {
+		if e.name == name {
+			n++
+		}
+	}
+	return n
+}
+
+func setup(t *testing.T) (*App, *memory.Store, *capture.Service, *fakeClipboard, *recorder) {
+	t.Helper()
+	st := memory.New()
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 100})
+	cb := newFakeClipboard()
+	rec := &recorder{signal: make(chan struct{}, 1)}
+	return New(st, capSvc, search.New(st), cb, rec.emit), st, capSvc, cb, rec
+}
+
+func capture1(t *testing.T, capSvc *capture.Service, text string) core.Item {
+	t.Helper()
+	it, saved, err := capSvc.ProcessText(context.Background(), text)
+	if err != nil || !saved {
+		t.Fatalf("expected %q captured (err=%v)", text, err)
+	}
+	return *it
+}
+
+func TestApp_WatcherCapturesAndEmits(t *testing.T) {
+	app, _, _, cb, rec := setup(t)
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	cb.copy("hello from elsewhere")
+	e := rec.wait(t, EventCaptured)
+	it, ok := e.data[0].(core.Item)
+	if !ok || it.Content != "hello from elsewhere" {
+		t.Fatalf("unexpected capture event: %+v", e.data)
+	}
+	rec.wait(t, EventChanged)
+
+	items, err := app.List(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].ID != it.ID {
+		t.Fatalf("expected the captured item listed, got %+v", items)
+	}
+
+	// a repeat merges into the stored item, and the event says so
+	cb.copy("something else")
+	rec.wait(t, EventCaptured)
+	cb.copy("hello from elsewhere")
+	e = rec.wait(t, EventCaptured)
+	if got := e.data[0].(core.Item); got.ID != it.ID {
+		t.Fatalf("expected the surviving id %s, got %s", it.ID, got.ID)
+	}
+}
+
+func TestApp_PausedSkipsCaptures(t *testing.T) {
+	app, st, _, cb, rec := setup(t)
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	if err := app.SaveSettings(Settings{Paused: true}); err != nil {
+		t.Fatal(err)
+	}
+	cb.copy("not while paused")
+	// the watcher takes the next event only once it's done with the last;
+	// empty clips are never captured, paused or not
+	cb.copy("")
+	if err := app.SaveSettings(Settings{}); err != nil {
+		t.Fatal(err)
+	}
+	cb.copy("captured")
+	rec.wait(t, EventCaptured)
+
+	if n, _ := st.Count(context.Background()); n != 1 {
+		t.Fatalf("expected only the unpaused copy stored, got %d", n)
+	}
+}
+
+func TestApp_SettingsPersistAndFilter(t *testing.T) {
+	app, st, capSvc, _, _ := setup(t)
+	ctx := context.Background()
+
+	if err := app.SaveSettings(Settings{IgnorePatterns: []string{"(unclosed"}, IgnoreRegex: true}); err == nil {
+		t.Fatal("expected invalid regex to be rejected")
+	}
+	if err := app.SaveSettings(Settings{IgnorePatterns: []string{"internal-only"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, saved, _ := capSvc.ProcessText(ctx, "this is internal-only"); saved {
+		t.Fatal("expected ignore pattern to drop the clip")
+	}
+	capture1(t, capSvc, "this is fine")
+
+	// a fresh app over the same store picks the settings up at startup
+	again := New(st, capture.New(st, nil, capture.Config{}), search.New(st), newFakeClipboard(), nil)
+	if err := Startup(again, ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(again)
+	if got := again.Settings(); len(got.IgnorePatterns) != 1 || got.IgnorePatterns[0] != "internal-only" {
+		t.Fatalf("expected persisted settings, got %+v", got)
+	}
+}
+
+func TestApp_SearchPinDeleteCopy(t *testing.T) {
+	app, _, capSvc, cb, rec := setup(t)
+	first := capture1(t, capSvc, "git status")
+	capture1(t, capSvc, "docker ps")
+
+	got, err := app.Search("git", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != first.ID {
+		t.Fatalf("expected git item, got %+v", got)
+	}
+	if got, _ := app.Search("nothing like it", 10); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty, non-nil result, got %#v", got)
+	}
+	if got, _ := app.Search("  ", 10); len(got) != 2 {
+		t.Fatalf("expected empty query to list everything, got %d", len(got))
+	}
+
+	if err := app.Pin(first.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SetTitle(first.ID, "status"); err != nil {
+		t.Fatal(err)
+	}
+	list, _ := app.List(10)
+	if !list[0].Pinned && !list[1].Pinned {
+		t.Fatalf("expected an item pinned, got %+v", list)
+	}
+
+	if err := app.Copy(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := cb.ReadText(); text != "git status" {
+		t.Fatalf("expected content copied, got %q", text)
+	}
+	if out, err := app.CopyTransformed(first.ID, "upper"); err != nil || out != "GIT STATUS" {
+		t.Fatalf("expected transformed copy, got %q (%v)", out, err)
+	}
+	if err := app.Copy("nope-not-an-id"); err == nil {
+		t.Fatal("expected unknown id to fail")
+	}
+
+	if err := app.Delete(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := app.List(10); len(list) != 1 {
+		t.Fatalf("expected trashed item hidden, got %d", len(list))
+	}
+	if err := app.Restore(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := rec.count(EventChanged); n != 4 {
+		t.Fatalf("expected 4 change events (pin, title, delete, restore), got %d", n)
+	}
+}
+
+func TestApp_ListenGetItemSetPaused(t *testing.T) {
+	app, _, capSvc, _, rec := setup(t)
+	extra := &recorder{signal: make(chan struct{}, 1)}
+	Listen(app, extra.emit)
+
+	it := capture1(t, capSvc, "kubectl get pods")
+	if got, err := app.GetItem(it.ID); err != nil || got.Content != it.Content {
+		t.Fatalf("GetItem = %+v (%v)", got, err)
+	}
+	if _, err := app.GetItem(it.ID[:len(it.ID)-1] + "x"); !errors.Is(err, ErrNoItem) {
+		t.Fatalf("expected ErrNoItem, got %v", err)
+	}
+
+	if err := app.SaveSettings(Settings{IgnorePatterns: []string{"secret"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.SetPaused(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := app.Settings(); !got.Paused || len(got.IgnorePatterns) != 1 {
+		t.Fatalf("expected paused with the patterns kept, got %+v", got)
+	}
+	// both the frontend and the listener hear about both saves
+	rec.wait(t, EventSettings)
+	rec.wait(t, EventSettings)
+	extra.wait(t, EventSettings)
+	if e := extra.wait(t, EventSettings); !e.data[0].(Settings).Paused {
+		t.Fatalf("listener got %+v", e.data)
+	}
+}
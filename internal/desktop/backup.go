@@ -0,0 +1,15 @@
+package desktop
+
+import (
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+)
+
+// UseBackups snapshots the history with m whenever the newest snapshot is
+// older than every, while the app runs. Call it before Startup.
+func UseBackups(a *App, m *backup.Manager, every time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.backups, a.backupEvery = m, every
+}
@@ -0,0 +1,63 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/backup"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+)
+
+type failingSource struct{}
+
+func (failingSource) Backup(context.Context, string) error { return errors.New("disk full") }
+
+func TestApp_BackupsRunWhileStarted(t *testing.T) {
+	app, _, _, _, rec := setup(t)
+	dir := t.TempDir()
+	st, err := sqlite.Open(filepath.Join(dir, "live.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	m := backup.New(st, backup.Config{Dir: filepath.Join(dir, "backups"), Keep: 3})
+	UseBackups(app, m, 24*time.Hour)
+
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	// nothing to go on yet, so the first snapshot is taken right away
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if snaps, _ := m.List(); len(snaps) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for a snapshot")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := rec.count(EventError); n != 0 {
+		t.Fatalf("unexpected errors: %d", n)
+	}
+}
+
+func TestApp_BackupErrorsReachTheFrontend(t *testing.T) {
+	app, _, _, _, rec := setup(t)
+	UseBackups(app, backup.New(failingSource{}, backup.Config{Dir: t.TempDir()}), time.Hour)
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	e := rec.wait(t, EventError)
+	if msg, _ := e.data[0].(string); !strings.HasPrefix(msg, "backup: ") || !strings.Contains(msg, "disk full") {
+		t.Fatalf("error event = %+v", e.data)
+	}
+}
@@ -0,0 +1,86 @@
+package desktop
+
+import (
+	"context"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+)
+
+// DefaultHotkey summons the launcher unless Settings.Hotkey says otherwise.
+// The hotkey is grabbed system-wide, so it must not be a chord other
+// applications need: Ctrl+Shift+V, for one, is how terminals paste.
+const DefaultHotkey = "Ctrl+Alt+V"
+
+// toggleAction is the hotkey action that shows or hides the launcher; it
+// is also what `otterclip toggle` sends over the command socket.
+const toggleAction = "toggle"
+
+// Window shows and hides the launcher window; runtime.WindowShow and
+// runtime.WindowHide in the app. Quit, runtime.Quit, is optional and
+// offered in the tray menu.
+type Window struct {
+	Show func(ctx context.Context)
+	Hide func(ctx context.Context)
+	Quit func(ctx context.Context)
+}
+
+// UseHotkeys makes the shortcuts delivered by m toggle the launcher window.
+// Call it before Startup, which binds Settings.Hotkey and runs m until
+// Shutdown.
+func UseHotkeys(a *App, m *hotkey.Manager, w Window) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.hotkeys, a.window, a.visible = m, w, true
+}
+
+// Hide hides the launcher, e.g. after copying or on Escape.
+func (a *App) Hide() {
+	a.mu.Lock()
+	hide, ctx := a.window.Hide, a.ctx
+	a.visible = false
+	a.mu.Unlock()
+	if hide != nil {
+		hide(ctx)
+	}
+}
+
+func (a *App) toggle() {
+	a.mu.Lock()
+	w, visible, ctx := a.window, a.visible, a.ctx
+	a.visible = !visible
+	a.mu.Unlock()
+
+	if visible {
+		w.Hide(ctx)
+		return
+	}
+	a.remember()
+	w.Show(ctx)
+	a.emit(ctx, EventShown)
+}
+
+// hotkeyOf parses the launcher shortcut from s, defaulting when unset.
+func hotkeyOf(s Settings) (hotkey.Accelerator, error) {
+	if strings.TrimSpace(s.Hotkey) == "" {
+		return hotkey.ParseAccelerator(DefaultHotkey)
+	}
+	return hotkey.ParseAccelerator(s.Hotkey)
+}
+
+// bindHotkey (re)binds the launcher shortcut when it changed.
+func (a *App) bindHotkey(accel hotkey.Accelerator) error {
+	a.mu.Lock()
+	m, bound := a.hotkeys, a.bound
+	a.mu.Unlock()
+	if m == nil || (bound != nil && *bound == accel) {
+		return nil
+	}
+	if err := m.Bind(toggleAction, accel, a.toggle); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.bound = &accel
+	a.mu.Unlock()
+	return nil
+}
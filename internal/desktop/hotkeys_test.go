@@ -0,0 +1,132 @@
+package desktop
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+)
+
+type fakeHotkeys struct {
+	mu     sync.Mutex
+	bound  map[string]hotkey.Accelerator
+	refuse string // accelerator that is "taken"
+	events chan string
+}
+
+func (f *fakeHotkeys) Register(action string, accel hotkey.Accelerator) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if accel.String() == f.refuse {
+		return errors.New("taken")
+	}
+	f.bound[action] = accel
+	return nil
+}
+
+func (f *fakeHotkeys) Unregister(action string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.bound, action)
+	return nil
+}
+
+func (f *fakeHotkeys) binding(action string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.bound[action].String()
+}
+
+func (f *fakeHotkeys) Events() <-chan string { return f.events }
+func (f *fakeHotkeys) Close() error          { return nil }
+
+type fakeWindow struct {
+	calls chan string
+}
+
+func (w fakeWindow) window() Window {
+	return Window{
+		Show: func(context.Context) { w.calls <- "show" },
+		Hide: func(context.Context) { w.calls <- "hide" },
+	}
+}
+
+func (w fakeWindow) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case c := <-w.calls:
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("window not toggled")
+		return ""
+	}
+}
+
+func TestApp_HotkeyTogglesLauncher(t *testing.T) {
+	app, _, _, _, rec := setup(t)
+	hk := &fakeHotkeys{bound: make(map[string]hotkey.Accelerator), events: make(chan string)}
+	win := fakeWindow{calls: make(chan string, 4)}
+	UseHotkeys(app, hotkey.NewManager(hk), win.window())
+
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+	if got := hk.binding(toggleAction); got != DefaultHotkey {
+		t.Fatalf("expected default hotkey bound, got %q", got)
+	}
+
+	hk.events <- toggleAction
+	if got := win.next(t); got != "hide" {
+		t.Fatalf("expected the visible launcher to hide, got %s", got)
+	}
+	hk.events <- toggleAction
+	if got := win.next(t); got != "show" {
+		t.Fatalf("expected the launcher to show, got %s", got)
+	}
+	rec.wait(t, EventShown)
+
+	app.Hide()
+	if got := win.next(t); got != "hide" {
+		t.Fatalf("expected Hide to hide, got %s", got)
+	}
+	hk.events <- toggleAction
+	if got := win.next(t); got != "show" {
+		t.Fatalf("expected toggle after Hide to show, got %s", got)
+	}
+}
+
+func TestApp_HotkeySettings(t *testing.T) {
+	app, _, _, _, _ := setup(t)
+	hk := &fakeHotkeys{bound: make(map[string]hotkey.Accelerator), events: make(chan string), refuse: "Ctrl+Alt+V"}
+	UseHotkeys(app, hotkey.NewManager(hk), fakeWindow{calls: make(chan string, 4)}.window())
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	if err := app.SaveSettings(Settings{Hotkey: "Hyper+V"}); err == nil {
+		t.Fatal("expected an unparsable hotkey to be rejected")
+	}
+	if err := app.SaveSettings(Settings{Hotkey: "super+space"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := hk.binding(toggleAction); got != "Super+Space" {
+		t.Fatalf("expected rebind, got %q", got)
+	}
+
+	// a taken shortcut keeps the old one but still saves the rest
+	err := app.SaveSettings(Settings{Hotkey: "Ctrl+Alt+V", Paused: true})
+	if !errors.Is(err, errHotkey) {
+		t.Fatalf("expected a bind error, got %v", err)
+	}
+	if got := app.Settings(); !got.Paused || got.Hotkey != "super+space" {
+		t.Fatalf("unexpected settings after failed bind: %+v", got)
+	}
+	if got := hk.binding(toggleAction); got != "Super+Space" {
+		t.Fatalf("expected previous binding kept, got %q", got)
+	}
+}
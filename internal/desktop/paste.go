@@ -0,0 +1,91 @@
+package desktop
+
+import (
+	"github.com/its-jojoo/otterclip/internal/adapter/clipboard"
+	"github.com/its-jojoo/otterclip/internal/adapter/paste"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// UsePaster lets Paste type the chosen item into the window that was
+// focused when the hotkey brought the launcher up. Without one, Paste
+// only copies.
+func UsePaster(a *App, p paste.Paster) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paster = p
+}
+
+// Paste copies an item, hides the launcher and pastes it into the
+// previously focused window, with the chord Settings.PasteChords (or the
+// built-in terminal rules) give for that application. With the paste
+// queue enabled (see UseQueue), the clipboard then moves on to the
+// following queued item.
+func (a *App) Paste(id string) error {
+	it, err := a.item(id)
+	if err != nil {
+		return err
+	}
+	ctx := a.context()
+	q, err := a.queued(ctx)
+	if err != nil {
+		return err
+	}
+	if q != nil {
+		// the watcher sees this write; it isn't a new copy to queue
+		if err := q.Written(ctx, it); err != nil {
+			return err
+		}
+	}
+	if err := a.cb.WriteText(it.Content); err != nil {
+		return err
+	}
+	a.Hide()
+
+	a.mu.Lock()
+	p, target, chords := a.paster, a.target, a.chords
+	m, bound := a.hotkeys, a.bound
+	a.mu.Unlock()
+	if p == nil {
+		return nil
+	}
+	chord := chords.For(target)
+	// Synthesized keys go through our own grab like real ones: pasting
+	// with the launcher's chord would bring the launcher back instead.
+	// Backends send the keys synchronously, so the grab can be restored
+	// as soon as Paste returns.
+	if m != nil && bound != nil && *bound == chord {
+		m.Unbind(toggleAction)
+		defer func() {
+			if err := m.Bind(toggleAction, chord, a.toggle); err != nil {
+				a.emit(ctx, EventError, "hotkey: "+err.Error())
+			}
+		}()
+	}
+	if err := p.Paste(ctx, chord); err != nil {
+		return err
+	}
+	if q != nil {
+		a.advanceQueue(ctx, q, it)
+	}
+	return nil
+}
+
+// remember notes which window to paste into before the launcher shows.
+func (a *App) remember() {
+	a.mu.Lock()
+	p, ctx := a.paster, a.ctx
+	a.mu.Unlock()
+	if p == nil {
+		return
+	}
+	if err := p.Remember(); err != nil {
+		a.emit(ctx, EventError, "paste: "+err.Error())
+	}
+	var target core.Source
+	if sr, ok := a.cb.(clipboard.SourceReader); ok {
+		target, _ = sr.ReadSource()
+	}
+	a.mu.Lock()
+	a.target = target
+	a.mu.Unlock()
+}
@@ -0,0 +1,206 @@
+package desktop
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/hotkey"
+	"github.com/its-jojoo/otterclip/internal/adapter/paste"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+// focusClipboard also reports the focused application.
+type focusClipboard struct {
+	*fakeClipboard
+	mu  sync.Mutex
+	src core.Source
+}
+
+func (f *focusClipboard) ReadSource() (core.Source, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.src, nil
+}
+
+func (f *focusClipboard) focus(src core.Source) {
+	f.mu.Lock()
+	f.src = src
+	f.mu.Unlock()
+}
+
+func TestApp_PasteIntoPreviousWindow(t *testing.T) {
+	st := memory.New()
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 100})
+	cb := &focusClipboard{fakeClipboard: newFakeClipboard()}
+	rec := &recorder{signal: make(chan struct{}, 1)}
+	app := New(st, capSvc, search.New(st), cb, rec.emit)
+
+	hk := &fakeHotkeys{bound: make(map[string]hotkey.Accelerator), events: make(chan string)}
+	win := fakeWindow{calls: make(chan string, 4)}
+	UseHotkeys(app, hotkey.NewManager(hk), win.window())
+	p := &paste.Fake{}
+	UsePaster(app, p)
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	if err := app.SaveSettings(Settings{PasteChords: "emacs"}); err == nil {
+		t.Fatal("expected a malformed paste rule to be rejected")
+	}
+	if err := app.SaveSettings(Settings{PasteChords: "emacs=Ctrl+Y"}); err != nil {
+		t.Fatal(err)
+	}
+	it := capture1(t, capSvc, "make test")
+
+	summon := func(src core.Source) {
+		t.Helper()
+		cb.focus(src)
+		app.Hide()
+		win.next(t)
+		hk.events <- toggleAction
+		if got := win.next(t); got != "show" {
+			t.Fatalf("expected the launcher to show, got %s", got)
+		}
+	}
+
+	summon(core.Source{AppID: "emacs"})
+	if p.Remembered() != 1 {
+		t.Fatalf("expected the focused window remembered, got %d", p.Remembered())
+	}
+	if err := app.Paste(it.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := win.next(t); got != "hide" {
+		t.Fatalf("expected the launcher hidden before pasting, got %s", got)
+	}
+	if text, _ := cb.ReadText(); text != "make test" {
+		t.Fatalf("expected content on the clipboard, got %q", text)
+	}
+
+	summon(core.Source{WindowClass: "kitty"})
+	app.Paste(it.ID)
+	win.next(t)
+	summon(core.Source{WindowClass: "firefox"})
+	app.Paste(it.ID)
+	win.next(t)
+
+	var got []string
+	for _, c := range p.Chords() {
+		got = append(got, c.String())
+	}
+	if want := "Ctrl+Y Ctrl+Shift+V Ctrl+V"; strings.Join(got, " ") != want {
+		t.Fatalf("pasted %v, want %v", got, want)
+	}
+
+	if err := app.Paste("nope-not-an-id"); err == nil {
+		t.Fatal("expected unknown id to fail")
+	}
+	if len(p.Chords()) != 3 {
+		t.Fatal("expected nothing pasted for an unknown id")
+	}
+}
+
+func TestApp_PasteWithoutPasterCopies(t *testing.T) {
+	app, _, capSvc, cb, _ := setup(t)
+	it := capture1(t, capSvc, "just copy")
+	if err := app.Paste(it.ID); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := cb.ReadText(); text != "just copy" {
+		t.Fatalf("expected content copied, got %q", text)
+	}
+}
+
+// grabbingPaster delivers pasted chords to the hotkey grabs, as the X
+// server does with XTest input.
+type grabbingPaster struct {
+	*paste.Fake
+	hk *fakeHotkeys
+}
+
+func (g grabbingPaster) Paste(ctx context.Context, chord hotkey.Accelerator) error {
+	g.hk.mu.Lock()
+	var grabbed []string
+	for action, accel := range g.hk.bound {
+		if accel == chord {
+			grabbed = append(grabbed, action)
+		}
+	}
+	g.hk.mu.Unlock()
+	for _, action := range grabbed {
+		g.hk.events <- action
+	}
+	return g.Fake.Paste(ctx, chord)
+}
+
+func TestApp_PasteDoesNotTriggerOwnHotkey(t *testing.T) {
+	st := memory.New()
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 100})
+	cb := &focusClipboard{fakeClipboard: newFakeClipboard()}
+	rec := &recorder{signal: make(chan struct{}, 1)}
+	app := New(st, capSvc, search.New(st), cb, rec.emit)
+
+	hk := &fakeHotkeys{bound: make(map[string]hotkey.Accelerator), events: make(chan string)}
+	win := fakeWindow{calls: make(chan string, 4)}
+	UseHotkeys(app, hotkey.NewManager(hk), win.window())
+	p := grabbingPaster{Fake: &paste.Fake{}, hk: hk}
+	UsePaster(app, p)
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+	it := capture1(t, capSvc, "make test")
+
+	pasteInto := func(src core.Source) {
+		t.Helper()
+		cb.focus(src)
+		app.Hide()
+		win.next(t)
+		hk.events <- toggleAction
+		if got := win.next(t); got != "show" {
+			t.Fatalf("expected the launcher to show, got %s", got)
+		}
+		if err := app.Paste(it.ID); err != nil {
+			t.Fatal(err)
+		}
+		if got := win.next(t); got != "hide" {
+			t.Fatalf("expected the launcher hidden, got %s", got)
+		}
+		select {
+		case c := <-win.calls:
+			t.Fatalf("pasting into %s toggled the launcher (%s)", src, c)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// the default hotkey is no terminal's paste chord
+	pasteInto(core.Source{WindowClass: "kitty"})
+
+	// a hotkey set to the terminal chord is released while pasting
+	if err := app.SaveSettings(Settings{Hotkey: "Ctrl+Shift+V"}); err != nil {
+		t.Fatal(err)
+	}
+	pasteInto(core.Source{WindowClass: "kitty"})
+	if got := hk.binding(toggleAction); got != "Ctrl+Shift+V" {
+		t.Fatalf("expected the hotkey bound again after pasting, got %q", got)
+	}
+	hk.events <- toggleAction
+	if got := win.next(t); got != "show" {
+		t.Fatalf("expected the hotkey to work after pasting, got %s", got)
+	}
+
+	var got []string
+	for _, c := range p.Chords() {
+		got = append(got, c.String())
+	}
+	if want := "Ctrl+Shift+V Ctrl+Shift+V"; strings.Join(got, " ") != want {
+		t.Fatalf("pasted %v, want %v", got, want)
+	}
+}
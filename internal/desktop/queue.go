@@ -0,0 +1,59 @@
+package desktop
+
+import (
+	"context"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/queue"
+)
+
+// queueSettle is how long a paste waits before the clipboard moves on to
+// the following queued item: the target application asks for the
+// clipboard only once it has handled the keystroke.
+const queueSettle = 300 * time.Millisecond
+
+// UseQueue pushes captures onto q while its mode is fifo or lifo, and
+// makes every Paste advance the clipboard to the following queued item,
+// so plain pastes in the target serve the rest of the queue. Call it
+// before Startup.
+func UseQueue(a *App, q *queue.Service) {
+	a.mu.Lock()
+	a.queue, a.queueSettle = q, queueSettle
+	a.mu.Unlock()
+	a.capture.Use(capture.StageSink, q.Sink())
+}
+
+// queued returns the paste queue when one is in use and enabled.
+func (a *App) queued(ctx context.Context) (*queue.Service, error) {
+	a.mu.Lock()
+	q := a.queue
+	a.mu.Unlock()
+	if q == nil {
+		return nil, nil
+	}
+	m, err := q.Mode(ctx)
+	if err != nil || m == queue.ModeOff {
+		return nil, err
+	}
+	return q, nil
+}
+
+// advanceQueue serves the item after pasted once the paste has settled.
+// Errors are reported with EventError.
+func (a *App) advanceQueue(ctx context.Context, q *queue.Service, pasted core.Item) {
+	a.mu.Lock()
+	settle := a.queueSettle
+	a.mu.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(settle):
+		}
+		if _, err := q.Advance(ctx, pasted); err != nil && !queue.IsEmpty(err) && ctx.Err() == nil {
+			a.emit(ctx, EventError, "paste queue: "+err.Error())
+		}
+	}()
+}
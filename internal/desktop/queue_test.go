@@ -0,0 +1,81 @@
+package desktop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/paste"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/queue"
+)
+
+// clipboardBecomes waits for the clipboard to hold want.
+func clipboardBecomes(t *testing.T, cb *fakeClipboard, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		text, _ := cb.ReadText()
+		if text == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("clipboard = %q, want %q", text, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestApp_PasteAdvancesQueue(t *testing.T) {
+	app, st, _, cb, rec := setup(t)
+	q := queue.New(st, cb)
+	UseQueue(app, q)
+	app.queueSettle = 10 * time.Millisecond
+	UsePaster(app, &paste.Fake{})
+	ctx := context.Background()
+	if err := q.SetMode(ctx, queue.ModeFIFO); err != nil {
+		t.Fatal(err)
+	}
+	if err := Startup(app, ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+
+	// the watcher queues what other applications copy
+	var items []core.Item
+	for _, text := range []string{"name", "street", "city"} {
+		cb.copy(text)
+		items = append(items, rec.wait(t, EventCaptured).data[0].(core.Item))
+	}
+	if list, _ := q.List(ctx); len(list) != 3 {
+		t.Fatalf("expected the copies queued, got %+v", list)
+	}
+
+	pasteNext := func(it core.Item, next string) {
+		t.Helper()
+		if err := app.Paste(it.ID); err != nil {
+			t.Fatal(err)
+		}
+		clipboardBecomes(t, cb, next)
+		// the watcher sees our own writes; they aren't queued again
+		cb.events <- struct{}{}
+	}
+	// pasting the head of the queue serves the item after it
+	pasteNext(items[0], "street")
+	pasteNext(items[1], "city")
+
+	// the queue is used up: the pasted item stays on the clipboard
+	if err := app.Paste(items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if text, _ := cb.ReadText(); text != "name" {
+		t.Fatalf("clipboard = %q, want name", text)
+	}
+	if list, _ := q.List(ctx); len(list) != 0 {
+		t.Fatalf("expected an empty queue, got %+v", list)
+	}
+	if n := rec.count(EventError); n != 0 {
+		t.Fatalf("expected no errors, got %d", n)
+	}
+}
@@ -0,0 +1,163 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/tray"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+const (
+	// trayRecent is how many recent clips the tray menu lists.
+	trayRecent = 10
+	// trayScan bounds the history scanned for the pinned submenu.
+	trayScan   = 1000
+	trayPinned = 20
+	trayLabel  = 48
+)
+
+// UseTray shows the capture state, recent clips and pins in t and keeps
+// them current as the history and settings change. Clicking the icon
+// toggles the launcher in w. Call it before Startup.
+func UseTray(a *App, t tray.Tray, w Window) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tray, a.window, a.visible = t, w, true
+	a.trayDirty = make(chan struct{}, 1)
+}
+
+// refreshTray schedules a menu rebuild; bursts of changes coalesce.
+func (a *App) refreshTray() {
+	a.mu.Lock()
+	dirty := a.trayDirty
+	a.mu.Unlock()
+	if dirty == nil {
+		return
+	}
+	select {
+	case dirty <- struct{}{}:
+	default:
+	}
+}
+
+// runTray keeps t up to date until ctx is done.
+func (a *App) runTray(ctx context.Context, t tray.Tray, dirty <-chan struct{}) {
+	a.updateTray(ctx, t)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-dirty:
+			a.updateTray(ctx, t)
+		case _, ok := <-t.Activated():
+			if !ok {
+				return
+			}
+			a.mu.Lock()
+			show := a.window.Show
+			a.mu.Unlock()
+			if show != nil {
+				a.toggle()
+			}
+		}
+	}
+}
+
+func (a *App) updateTray(ctx context.Context, t tray.Tray) {
+	st, err := a.trayState(ctx)
+	if err == nil {
+		err = t.Set(st)
+	}
+	if err != nil && ctx.Err() == nil {
+		a.emit(ctx, EventError, "tray: "+err.Error())
+	}
+}
+
+func (a *App) trayState(ctx context.Context) (tray.State, error) {
+	s := a.Settings()
+	items, err := a.store.ListRecent(ctx, trayScan)
+	if err != nil {
+		return tray.State{}, err
+	}
+
+	n, err := a.store.Count(ctx)
+	if err != nil {
+		return tray.State{}, err
+	}
+	st := tray.State{Paused: s.Paused, Tooltip: clipCount(n)}
+	if s.Paused {
+		st.Tooltip += ", capturing paused"
+	}
+
+	var pinned []tray.MenuItem
+	for i, it := range items {
+		if i < trayRecent {
+			st.Menu = append(st.Menu, a.clipEntry(it))
+		}
+		if it.Pinned && len(pinned) < trayPinned {
+			pinned = append(pinned, a.clipEntry(it))
+		}
+	}
+	if len(items) == 0 {
+		st.Menu = append(st.Menu, tray.MenuItem{Label: "No clips yet", Disabled: true})
+	}
+	st.Menu = append(st.Menu,
+		tray.MenuItem{Separator: true},
+		tray.MenuItem{Label: "Pinned", Children: pinned, Disabled: len(pinned) == 0},
+		tray.MenuItem{Separator: true},
+	)
+
+	a.mu.Lock()
+	w := a.window
+	a.mu.Unlock()
+	if w.Show != nil {
+		st.Menu = append(st.Menu, tray.MenuItem{Label: "Show launcher", OnClick: a.toggle})
+	}
+	st.Menu = append(st.Menu,
+		tray.MenuItem{Label: "Pause capturing", Toggle: true, Checked: s.Paused, OnClick: a.togglePaused},
+		tray.MenuItem{Label: "Clear history", Disabled: len(items) == 0, OnClick: func() {
+			if _, err := a.ClearHistory(); err != nil {
+				a.emit(a.context(), EventError, "clear history: "+err.Error())
+			}
+		}},
+	)
+	if w.Quit != nil {
+		st.Menu = append(st.Menu,
+			tray.MenuItem{Separator: true},
+			tray.MenuItem{Label: "Quit OtterClip", OnClick: func() { w.Quit(a.context()) }},
+		)
+	}
+	return st, nil
+}
+
+// clipEntry is a menu entry that copies it.
+func (a *App) clipEntry(it core.Item) tray.MenuItem {
+	label := it.Title
+	if label == "" {
+		label = strings.Join(strings.Fields(it.Content), " ")
+	}
+	if r := []rune(label); len(r) > trayLabel {
+		label = string(r[:trayLabel-1]) + "…"
+	}
+	id := it.ID
+	return tray.MenuItem{Label: label, OnClick: func() {
+		if err := a.Copy(id); err != nil {
+			a.emit(a.context(), EventError, "copy: "+err.Error())
+		}
+	}}
+}
+
+func (a *App) togglePaused() {
+	if err := a.SetPaused(!a.Settings().Paused); err != nil {
+		a.emit(a.context(), EventError, "settings: "+err.Error())
+	}
+}
+
+func clipCount(n int) string {
+	if n == 1 {
+		return "1 clip"
+	}
+	return fmt.Sprintf("%d clips", n)
+}
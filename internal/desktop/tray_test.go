@@ -0,0 +1,119 @@
+package desktop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/tray"
+)
+
+type fakeTray struct {
+	states    chan tray.State
+	activated chan struct{}
+}
+
+func newFakeTray() *fakeTray {
+	return &fakeTray{states: make(chan tray.State, 16), activated: make(chan struct{})}
+}
+
+func (f *fakeTray) Set(st tray.State) error {
+	f.states <- st
+	return nil
+}
+
+func (f *fakeTray) Activated() <-chan struct{} { return f.activated }
+func (f *fakeTray) Close() error               { return nil }
+
+// wait returns the first state satisfying ok.
+func (f *fakeTray) wait(t *testing.T, ok func(tray.State) bool) tray.State {
+	t.Helper()
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case st := <-f.states:
+			if ok(st) {
+				return st
+			}
+		case <-deadline:
+			t.Fatal("tray not updated")
+			return tray.State{}
+		}
+	}
+}
+
+func find(items []tray.MenuItem, label string) (tray.MenuItem, bool) {
+	for _, it := range items {
+		if it.Label == label {
+			return it, true
+		}
+	}
+	return tray.MenuItem{}, false
+}
+
+func TestApp_TrayFollowsHistory(t *testing.T) {
+	app, _, capSvc, cb, rec := setup(t)
+	ft := newFakeTray()
+	win := fakeWindow{calls: make(chan string, 4)}
+	UseTray(app, ft, win.window())
+
+	for i := range 12 {
+		capture1(t, capSvc, "clip "+string(rune('a'+i)))
+	}
+	pinned := capture1(t, capSvc, "keep me")
+	if err := Startup(app, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer Shutdown(app)
+	// startup announces the loaded settings
+	if e := rec.wait(t, EventSettings); e.data[0].(Settings).Paused {
+		t.Fatalf("expected the stored settings announced, got %+v", e.data)
+	}
+
+	st := ft.wait(t, func(tray.State) bool { return true })
+	if st.Menu[0].Label != "keep me" || st.Menu[9].Label != "clip d" || st.Menu[10].Separator != true {
+		t.Fatalf("expected the 10 most recent clips first, got %+v", st.Menu[:11])
+	}
+	if st.Tooltip != "13 clips" || st.Paused {
+		t.Fatalf("unexpected status %q paused=%v", st.Tooltip, st.Paused)
+	}
+
+	app.Pin(pinned.ID, true)
+	st = ft.wait(t, func(st tray.State) bool {
+		p, _ := find(st.Menu, "Pinned")
+		return len(p.Children) == 1
+	})
+
+	// choosing a clip copies it
+	st.Menu[1].OnClick()
+	if text, _ := cb.ReadText(); text != "clip l" {
+		t.Fatalf("expected the clip copied, got %q", text)
+	}
+
+	pause, _ := find(st.Menu, "Pause capturing")
+	pause.OnClick()
+	st = ft.wait(t, func(st tray.State) bool { return st.Paused })
+	if pause, _ := find(st.Menu, "Pause capturing"); !pause.Checked {
+		t.Fatal("expected the pause entry checked")
+	}
+	if e := rec.wait(t, EventSettings); !e.data[0].(Settings).Paused {
+		t.Fatalf("expected the frontend told about the pause, got %+v", e.data)
+	}
+
+	wipe, _ := find(st.Menu, "Clear history")
+	wipe.OnClick()
+	st = ft.wait(t, func(st tray.State) bool { return st.Tooltip == "1 clip, capturing paused" })
+	if st.Menu[0].Label != "keep me" {
+		t.Fatalf("expected only the pinned clip left, got %+v", st.Menu[0])
+	}
+
+	// the window starts out shown, so the icon hides it first
+	ft.activated <- struct{}{}
+	if got := win.next(t); got != "hide" {
+		t.Fatalf("expected the icon to hide the launcher, got %s", got)
+	}
+	ft.activated <- struct{}{}
+	if got := win.next(t); got != "show" {
+		t.Fatalf("expected the icon to show the launcher, got %s", got)
+	}
+}
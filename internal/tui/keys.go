@@ -0,0 +1,128 @@
+package tui
+
+import "unicode/utf8"
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyCtrl         // r holds the letter, 'a'..'z'
+	keyEnter
+	keyEsc
+	keyBackspace
+	keyTab
+	keyBacktab
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyHome
+	keyEnd
+	keyPgUp
+	keyPgDn
+	keyDelete
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+func ctrl(r rune) key { return key{kind: keyCtrl, r: r} }
+
+// parseKeys decodes raw terminal input. It understands the common xterm
+// CSI/SS3 sequences; anything else is dropped. An ESC followed by a
+// non-sequence byte (Alt+key) is reported as Esc followed by that key.
+func parseKeys(b []byte) []key {
+	var out []key
+	for len(b) > 0 {
+		c := b[0]
+		switch {
+		case c == 0x1b:
+			if len(b) > 1 && (b[1] == '[' || b[1] == 'O') {
+				k, n, ok := parseSeq(b[2:])
+				b = b[2+n:]
+				if ok {
+					out = append(out, k)
+				}
+				continue
+			}
+			out = append(out, key{kind: keyEsc})
+			b = b[1:]
+		case c == '\r' || c == '\n':
+			out = append(out, key{kind: keyEnter})
+			b = b[1:]
+		case c == '\t':
+			out = append(out, key{kind: keyTab})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			out = append(out, key{kind: keyBackspace})
+			b = b[1:]
+		case c >= 0x01 && c <= 0x1a:
+			out = append(out, ctrl(rune('a'+c-1)))
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			r, n := utf8.DecodeRune(b)
+			if r != utf8.RuneError {
+				out = append(out, key{kind: keyRune, r: r})
+			}
+			b = b[n:]
+		}
+	}
+	return out
+}
+
+// parseSeq decodes the rest of a CSI/SS3 sequence: optional numeric
+// parameters followed by a final byte. It returns how many bytes it used.
+func parseSeq(b []byte) (key, int, bool) {
+	param := 0
+	for i, c := range b {
+		switch {
+		case c >= '0' && c <= '9':
+			param = param*10 + int(c-'0')
+			continue
+		case c == ';':
+			// modifiers ("1;5A") are ignored
+			param = 0
+			continue
+		}
+		k, ok := seqKey(c, param)
+		return k, i + 1, ok
+	}
+	return key{}, len(b), false
+}
+
+func seqKey(final byte, param int) (key, bool) {
+	switch final {
+	case 'A':
+		return key{kind: keyUp}, true
+	case 'B':
+		return key{kind: keyDown}, true
+	case 'C':
+		return key{kind: keyRight}, true
+	case 'D':
+		return key{kind: keyLeft}, true
+	case 'H':
+		return key{kind: keyHome}, true
+	case 'F':
+		return key{kind: keyEnd}, true
+	case 'Z':
+		return key{kind: keyBacktab}, true
+	case '~':
+		switch param {
+		case 1, 7:
+			return key{kind: keyHome}, true
+		case 3:
+			return key{kind: keyDelete}, true
+		case 4, 8:
+			return key{kind: keyEnd}, true
+		case 5:
+			return key{kind: keyPgUp}, true
+		case 6:
+			return key{kind: keyPgDn}, true
+		}
+	}
+	return key{}, false
+}
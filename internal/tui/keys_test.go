@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []key
+	}{
+		{"aé", []key{{kind: keyRune, r: 'a'}, {kind: keyRune, r: 'é'}}},
+		{"\r\x7f\t", []key{{kind: keyEnter}, {kind: keyBackspace}, {kind: keyTab}}},
+		{"\x14\x03", []key{ctrl('t'), ctrl('c')}},
+		{"\x1b[A\x1bOB\x1b[Z", []key{{kind: keyUp}, {kind: keyDown}, {kind: keyBacktab}}},
+		{"\x1b[3~\x1b[5~\x1b[6~", []key{{kind: keyDelete}, {kind: keyPgUp}, {kind: keyPgDn}}},
+		{"\x1b[1;5A", []key{{kind: keyUp}}},
+		{"\x1b", []key{{kind: keyEsc}}},
+		{"\x1bx", []key{{kind: keyEsc}, {kind: keyRune, r: 'x'}}},
+		{"\x1b[99~q", []key{{kind: keyRune, r: 'q'}}},
+	}
+	for _, c := range cases {
+		if got := parseKeys([]byte(c.in)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parseKeys(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+// tick is how often the screen is checked for resizes.
+const tick = 250 * time.Millisecond
+
+// Run takes over the terminal until the user quits and returns the item
+// picked with enter, if any. New captures (from a watcher in another
+// process, say) show up within cfg.Refresh.
+func Run(ctx context.Context, in, out *os.File, store Store, svc *search.Service, copy Copier, cfg Config) (*core.Item, error) {
+	inFd, outFd := int(in.Fd()), int(out.Fd())
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return nil, errors.New("tui needs a terminal")
+	}
+
+	m := newModel(store, svc, copy, cfg)
+	if err := m.refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return nil, err
+	}
+	defer term.Restore(inFd, state)
+	io.WriteString(out, "\x1b[?1049h\x1b[?25l") // alternate screen, hide cursor
+	defer io.WriteString(out, "\x1b[?25h\x1b[?1049l")
+
+	input := make(chan []byte)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				close(input)
+				return
+			}
+			input <- append([]byte(nil), buf[:n]...)
+		}
+	}()
+
+	var last string
+	var lastW, lastH int
+	draw := func() {
+		w, h, err := term.GetSize(outFd)
+		if err != nil {
+			w, h = 80, 24
+		}
+		frame := "\x1b[H" + strings.Join(m.view(w, h, time.Now()), "\r\n")
+		if w != lastW || h != lastH {
+			frame = "\x1b[2J" + frame
+			lastW, lastH = w, h
+		} else if frame == last {
+			return
+		}
+		last = frame
+		io.WriteString(out, frame)
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	refreshed := time.Now()
+
+	for !m.done {
+		draw()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case b, ok := <-input:
+			if !ok {
+				return nil, errors.New("terminal input closed")
+			}
+			for _, k := range parseKeys(b) {
+				if err := m.handle(ctx, k); err != nil {
+					m.status = "error: " + err.Error()
+				}
+				if m.done {
+					break
+				}
+			}
+		case now := <-ticker.C:
+			if now.Sub(refreshed) < m.cfg.Refresh {
+				continue
+			}
+			refreshed = now
+			if err := m.refresh(ctx); err != nil {
+				m.status = "error: " + err.Error()
+			}
+		}
+	}
+	return m.picked, nil
+}
+
+// OSC52 copies through the terminal itself with the OSC 52 escape
+// sequence, which reaches the local clipboard even over SSH when the
+// terminal emulator supports it.
+func OSC52(w io.Writer) Copier {
+	return func(text string) error {
+		_, err := fmt.Fprintf(w, "\x1b]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+		return err
+	}
+}
@@ -0,0 +1,258 @@
+// Package tui is a full-screen terminal browser for the clipboard history:
+// incremental search, a preview pane and keys to copy, pin and trash items.
+// It only needs a terminal, so it works over SSH.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+// Store is the storage the TUI browses.
+type Store interface {
+	ListRecent(ctx context.Context, limit int) ([]core.Item, error)
+	SetPinned(ctx context.Context, id string, pinned bool) error
+	Trash(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
+}
+
+// Config tunes the TUI. Zero values pick defaults.
+type Config struct {
+	Limit   int           // items shown (default 200)
+	Scan    int           // recent items searched (default 1000)
+	Refresh time.Duration // how often to look for new captures (default 1s)
+}
+
+// Copier puts text on a clipboard.
+type Copier func(text string) error
+
+const helpLine = "enter copy+quit · ^y copy · ^t pin · del/^d trash · ^z undo · tab type · esc quit"
+
+// model is the TUI state. Every key is handled synchronously against the
+// store, so the screen always shows what's stored.
+type model struct {
+	store  Store
+	search *search.Service
+	copy   Copier
+	cfg    Config
+
+	items  []core.Item
+	types  []core.ContentType // filters offered by tab; "" is all
+	typ    int
+	query  []rune
+	cursor int
+	top    int // first visible list row
+	scroll int // preview scroll offset
+
+	status  string
+	trashed []string // ids, for undo
+	done    bool
+	picked  *core.Item // set when enter copied an item
+}
+
+func newModel(store Store, svc *search.Service, copy Copier, cfg Config) *model {
+	if cfg.Limit <= 0 {
+		cfg.Limit = 200
+	}
+	if cfg.Scan <= 0 {
+		cfg.Scan = 1000
+	}
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = time.Second
+	}
+	return &model{store: store, search: svc, copy: copy, cfg: cfg, types: []core.ContentType{""}}
+}
+
+func (m *model) selected() (core.Item, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return core.Item{}, false
+	}
+	return m.items[m.cursor], true
+}
+
+func (m *model) filter() core.ContentType { return m.types[m.typ] }
+
+// refresh re-runs the current query, keeping the selection on the same
+// item when it's still listed.
+func (m *model) refresh(ctx context.Context) error { return m.load(ctx, true) }
+
+// load runs the current query; unless keep is set the selection moves to
+// the best match.
+func (m *model) load(ctx context.Context, keep bool) error {
+	recent, err := m.store.ListRecent(ctx, m.cfg.Scan)
+	if err != nil {
+		return err
+	}
+	m.setTypes(recent)
+
+	items := recent[:min(len(recent), m.cfg.Limit)]
+	q := string(m.query)
+	if strings.TrimSpace(q) != "" || m.filter() != "" {
+		items, err = m.search.Query(ctx, q, search.Options{ScanLimit: m.cfg.Scan, OutLimit: m.cfg.Limit, Type: m.filter()})
+		if err != nil {
+			return err
+		}
+	}
+
+	prev, hadPrev := m.selected()
+	m.items = items
+	if !keep {
+		m.cursor = 0
+	}
+	m.cursor = min(m.cursor, max(len(items)-1, 0))
+	if keep && hadPrev {
+		if i := slices.IndexFunc(items, func(it core.Item) bool { return it.ID == prev.ID }); i >= 0 {
+			m.cursor = i
+		}
+	}
+	if cur, ok := m.selected(); !ok || !hadPrev || cur.ID != prev.ID || cur.Content != prev.Content {
+		m.scroll = 0
+	}
+	return nil
+}
+
+// setTypes offers a filter for every type present in the history, in the
+// usual type order, keeping the current filter selected.
+func (m *model) setTypes(items []core.Item) {
+	cur := m.filter()
+	present := make(map[core.ContentType]bool)
+	for _, it := range items {
+		present[it.Type] = true
+	}
+	types := []core.ContentType{""}
+	for _, t := range core.ContentTypes() {
+		if present[t] || t == cur {
+			types = append(types, t)
+		}
+	}
+	m.types = types
+	m.typ = max(slices.Index(types, cur), 0)
+}
+
+// handle applies one key press.
+func (m *model) handle(ctx context.Context, k key) error {
+	m.status = ""
+	requery, keep := false, false
+
+	switch {
+	case k.kind == keyRune:
+		m.query = append(m.query, k.r)
+		requery = true
+	case k.kind == keyBackspace:
+		if len(m.query) > 0 {
+			m.query = m.query[:len(m.query)-1]
+			requery = true
+		}
+	case k == ctrl('w'):
+		q := strings.TrimRight(string(m.query), " ")
+		if i := strings.LastIndexByte(q, ' '); i >= 0 {
+			q = q[:i+1]
+		} else {
+			q = ""
+		}
+		m.query = []rune(q)
+		requery = true
+	case k == ctrl('u'):
+		m.query = nil
+		requery = true
+
+	case k.kind == keyUp, k == ctrl('p'), k == ctrl('k'):
+		m.move(-1)
+	case k.kind == keyDown, k == ctrl('n'):
+		m.move(1)
+	case k.kind == keyHome:
+		m.move(-len(m.items))
+	case k.kind == keyEnd:
+		m.move(len(m.items))
+	case k.kind == keyPgUp:
+		m.scroll = max(m.scroll-10, 0)
+	case k.kind == keyPgDn:
+		m.scroll += 10
+
+	case k.kind == keyTab:
+		m.typ = (m.typ + 1) % len(m.types)
+		requery = true
+	case k.kind == keyBacktab:
+		m.typ = (m.typ + len(m.types) - 1) % len(m.types)
+		requery = true
+
+	case k.kind == keyEnter, k == ctrl('y'):
+		it, ok := m.selected()
+		if !ok {
+			return nil
+		}
+		if err := m.copy(it.Content); err != nil {
+			return fmt.Errorf("copy: %w", err)
+		}
+		if k.kind == keyEnter {
+			m.picked, m.done = &it, true
+			return nil
+		}
+		m.status = "copied " + it.ShortID()
+
+	case k == ctrl('t'):
+		it, ok := m.selected()
+		if !ok {
+			return nil
+		}
+		if err := m.store.SetPinned(ctx, it.ID, !it.Pinned); err != nil {
+			return err
+		}
+		m.status = "pinned " + it.ShortID()
+		if it.Pinned {
+			m.status = "unpinned " + it.ShortID()
+		}
+		requery, keep = true, true
+
+	case k.kind == keyDelete, k == ctrl('d'):
+		it, ok := m.selected()
+		if !ok {
+			return nil
+		}
+		if it.Pinned {
+			m.status = "refusing to trash a pinned item (^t unpins)"
+			return nil
+		}
+		if err := m.store.Trash(ctx, it.ID); err != nil {
+			return err
+		}
+		m.trashed = append(m.trashed, it.ID)
+		m.status = "trashed " + it.ShortID() + " (^z undoes)"
+		requery, keep = true, true
+
+	case k == ctrl('z'):
+		if len(m.trashed) == 0 {
+			m.status = "nothing to undo"
+			return nil
+		}
+		id := m.trashed[len(m.trashed)-1]
+		m.trashed = m.trashed[:len(m.trashed)-1]
+		if err := m.store.Restore(ctx, id); err != nil {
+			return err
+		}
+		m.status = "restored " + core.Item{ID: id}.ShortID()
+		requery, keep = true, true
+
+	case k.kind == keyEsc, k == ctrl('c'), k == ctrl('g'):
+		m.done = true
+	}
+
+	if requery {
+		return m.load(ctx, keep)
+	}
+	return nil
+}
+
+func (m *model) move(d int) {
+	if len(m.items) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+d, 0), len(m.items)-1)
+	m.scroll = 0
+}
@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
+)
+
+func setup(t *testing.T, texts ...string) (*model, *memory.Store, *string) {
+	t.Helper()
+	ctx := context.Background()
+	st := memory.New()
+	now := time.Now()
+	st.SetClock(func() time.Time { return now })
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 100})
+	for _, txt := range texts {
+		now = now.Add(time.Second)
+		if _, saved, err := capSvc.ProcessText(ctx, txt); err != nil || !saved {
+			t.Fatalf("expected %q captured (err=%v)", txt, err)
+		}
+	}
+
+	var copied string
+	m := newModel(st, search.New(st), func(text string) error {
+		copied = text
+		return nil
+	}, Config{})
+	if err := m.refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return m, st, &copied
+}
+
+func press(t *testing.T, m *model, keys ...key) {
+	t.Helper()
+	for _, k := range keys {
+		if err := m.handle(context.Background(), k); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func typeText(t *testing.T, m *model, s string) {
+	t.Helper()
+	for _, r := range s {
+		press(t, m, key{kind: keyRune, r: r})
+	}
+}
+
+func contents(m *model) []string {
+	var out []string
+	for _, it := range m.items {
+		out = append(out, it.Content)
+	}
+	return out
+}
+
+func TestModel_IncrementalSearch(t *testing.T) {
+	m, _, _ := setup(t, "git status", "docker ps", "git push")
+	if got := contents(m); len(got) != 3 || got[0] != "git push" {
+		t.Fatalf("expected newest first, got %v", got)
+	}
+
+	typeText(t, m, "git")
+	if got := contents(m); len(got) != 2 {
+		t.Fatalf("expected 2 git items, got %v", got)
+	}
+	typeText(t, m, " pu")
+	if got := contents(m); len(got) != 1 || got[0] != "git push" {
+		t.Fatalf("expected git push, got %v", got)
+	}
+
+	press(t, m, ctrl('u'))
+	if len(m.items) != 3 || len(m.query) != 0 {
+		t.Fatalf("expected query cleared, got %q %v", string(m.query), contents(m))
+	}
+}
+
+func TestModel_TypeFilter(t *testing.T) {
+	m, _, _ := setup(t, "hello", "https://example.com", "note to self")
+
+	press(t, m, key{kind: keyTab})
+	if m.filter() != "text" || len(m.items) != 2 {
+		t.Fatalf("expected text filter, got %q %v", m.filter(), contents(m))
+	}
+	press(t, m, key{kind: keyTab})
+	if m.filter() != "url" || len(m.items) != 1 {
+		t.Fatalf("expected url filter, got %q %v", m.filter(), contents(m))
+	}
+	press(t, m, key{kind: keyTab})
+	if m.filter() != "" || len(m.items) != 3 {
+		t.Fatalf("expected all again, got %q %v", m.filter(), contents(m))
+	}
+}
+
+func TestModel_CopyPinTrashUndo(t *testing.T) {
+	m, st, copied := setup(t, "first", "second", "third")
+	ctx := context.Background()
+
+	press(t, m, key{kind: keyDown}, ctrl('y'))
+	if *copied != "second" || m.done {
+		t.Fatalf("expected second copied without quitting, got %q done=%v", *copied, m.done)
+	}
+
+	press(t, m, ctrl('t'))
+	if it, _ := m.selected(); it.Content != "second" || !it.Pinned {
+		t.Fatalf("expected second pinned and still selected, got %+v", it)
+	}
+	press(t, m, key{kind: keyDelete})
+	if n, _ := st.Count(ctx); n != 3 {
+		t.Fatalf("pinned item must not be trashed, count=%d", n)
+	}
+
+	press(t, m, ctrl('t'), key{kind: keyDelete})
+	if n, _ := st.Count(ctx); n != 2 || len(m.items) != 2 {
+		t.Fatalf("expected item trashed, count=%d items=%v", n, contents(m))
+	}
+	press(t, m, ctrl('z'))
+	if n, _ := st.Count(ctx); n != 3 || len(m.items) != 3 {
+		t.Fatalf("expected item restored, count=%d items=%v", n, contents(m))
+	}
+
+	press(t, m, key{kind: keyHome}, key{kind: keyEnter})
+	if !m.done || m.picked == nil || *copied != "third" {
+		t.Fatalf("expected enter to copy third and quit, got %q done=%v", *copied, m.done)
+	}
+}
+
+func TestModel_RefreshKeepsSelection(t *testing.T) {
+	m, st, _ := setup(t, "one", "two")
+	press(t, m, key{kind: keyDown})
+
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 100})
+	if _, _, err := capSvc.ProcessText(context.Background(), "three"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.items) != 3 {
+		t.Fatalf("expected new capture listed, got %v", contents(m))
+	}
+	if it, _ := m.selected(); it.Content != "one" {
+		t.Fatalf("expected selection to stay on one, got %q", it.Content)
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m, _, _ := setup(t, "plain", "line one\nline two\x1b[31m")
+	lines := m.view(80, 10, time.Now())
+	if len(lines) != 10 {
+		t.Fatalf("expected 10 lines, got %d", len(lines))
+	}
+	screen := strings.Join(lines, "\n")
+	for _, want := range []string{"[all] 2", "line one", "line two[31m", "plain", "enter copy+quit"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("screen missing %q:\n%s", want, screen)
+		}
+	}
+	if strings.Contains(screen, "\x1b[31m") {
+		t.Fatal("clip escape sequences must not reach the terminal")
+	}
+}
@@ -0,0 +1,226 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+const (
+	reverse = "\x1b[7m"
+	dim     = "\x1b[2m"
+	bold    = "\x1b[1m"
+	reset   = "\x1b[0m"
+)
+
+// minSplitWidth is the narrowest terminal that gets a preview pane.
+const minSplitWidth = 60
+
+// view renders the screen as exactly h lines of width w.
+func (m *model) view(w, h int, now time.Time) []string {
+	if w < 10 || h < 4 {
+		return []string{fit("otterclip: terminal too small", w)}
+	}
+
+	lines := make([]string, 0, h)
+	lines = append(lines, m.promptLine(w))
+
+	body := h - 2
+	listW, prevW := w, 0
+	if w >= minSplitWidth {
+		listW = w * 2 / 5
+		prevW = w - listW - 1
+	}
+	list := m.listLines(listW, body)
+	var prev []string
+	if prevW > 0 {
+		prev = m.previewLines(prevW, body, now)
+	}
+	for i := 0; i < body; i++ {
+		line := list[i]
+		if prevW > 0 {
+			line += dim + "│" + reset + prev[i]
+		}
+		lines = append(lines, line)
+	}
+
+	status := m.status
+	if status == "" {
+		status = helpLine
+	}
+	lines = append(lines, dim+fit(status, w)+reset)
+	return lines
+}
+
+func (m *model) promptLine(w int) string {
+	filter := "all"
+	if t := m.filter(); t != "" {
+		filter = string(t)
+	}
+	right := fmt.Sprintf(" [%s] %d", filter, len(m.items))
+	left := "> " + string(m.query)
+	return bold + fit(left, w-width(right)) + reset + right
+}
+
+func (m *model) listLines(w, h int) []string {
+	// keep the cursor on screen
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+h {
+		m.top = m.cursor - h + 1
+	}
+	m.top = max(min(m.top, len(m.items)-h), 0)
+
+	out := make([]string, h)
+	for i := range out {
+		n := m.top + i
+		if n >= len(m.items) {
+			out[i] = strings.Repeat(" ", w)
+			if n == 0 && i == 0 {
+				out[i] = fit(" (no items)", w)
+			}
+			continue
+		}
+		it := m.items[n]
+		pin := " "
+		if it.Pinned {
+			pin = "★"
+		}
+		text := oneLine(it.Content)
+		if it.Title != "" {
+			text = it.Title + " — " + text
+		}
+		row := fit(fmt.Sprintf("%s %s %-8s %s", pin, it.ShortID(), typeLabel(it), text), w)
+		if n == m.cursor {
+			row = reverse + row + reset
+		}
+		out[i] = row
+	}
+	return out
+}
+
+func (m *model) previewLines(w, h int, now time.Time) []string {
+	it, ok := m.selected()
+	var lines []string
+	if ok {
+		lines = previewOf(it, w-1, now)
+	}
+	m.scroll = max(min(m.scroll, len(lines)-h), 0)
+	if m.scroll < len(lines) {
+		lines = lines[m.scroll:]
+	}
+
+	out := make([]string, h)
+	for i := range out {
+		s := ""
+		if i < len(lines) {
+			s = lines[i]
+		}
+		out[i] = " " + fit(s, w-1)
+	}
+	return out
+}
+
+// previewOf is an item's details followed by its wrapped content.
+func previewOf(it core.Item, w int, now time.Time) []string {
+	head := []string{it.ID}
+	if it.Title != "" {
+		head = append(head, "title: "+it.Title)
+	}
+	info := "type: " + typeLabel(it)
+	if it.Pinned {
+		info += " · pinned"
+	}
+	head = append(head, info)
+	if !it.Source.IsZero() {
+		head = append(head, "from: "+it.Source.String())
+	}
+	head = append(head, "seen: "+ago(now.Sub(it.LastSeenAt))+" · first: "+ago(now.Sub(it.CreatedAt)))
+	if len(it.Tags) > 0 {
+		head = append(head, "tags: #"+strings.Join(it.Tags, " #"))
+	}
+	if it.Note != "" {
+		head = append(head, "note: "+it.Note)
+	}
+
+	var out []string
+	for _, l := range head {
+		out = append(out, wrap(l, w)...)
+	}
+	out = append(out, strings.Repeat("─", w))
+	for _, l := range strings.Split(it.Content, "\n") {
+		out = append(out, wrap(l, w)...)
+	}
+	return out
+}
+
+func typeLabel(it core.Item) string {
+	if lang := it.Meta[core.MetaLanguage]; lang != "" {
+		return string(it.Type) + ":" + lang
+	}
+	return string(it.Type)
+}
+
+func ago(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// clean makes text safe to print: tabs become spaces and other control
+// characters (escape sequences in clips included) are dropped.
+func clean(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(clean(strings.ReplaceAll(s, "\n", " "))), " ")
+}
+
+// width counts runes; wide characters are not accounted for.
+func width(s string) int { return len([]rune(s)) }
+
+// fit pads or truncates s to exactly w columns.
+func fit(s string, w int) string {
+	if w <= 0 {
+		return ""
+	}
+	r := []rune(clean(s))
+	if len(r) > w {
+		return string(r[:w-1]) + "…"
+	}
+	return string(r) + strings.Repeat(" ", w-len(r))
+}
+
+// wrap breaks s into lines of at most w columns.
+func wrap(s string, w int) []string {
+	r := []rune(clean(s))
+	if len(r) == 0 || w <= 0 {
+		return []string{""}
+	}
+	var out []string
+	for len(r) > w {
+		out = append(out, string(r[:w]))
+		r = r[w:]
+	}
+	return append(out, string(r))
+}
@@ -2,29 +2,72 @@ package capture
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/its-jojoo/otterclip/internal/adapter/storage"
 	"github.com/its-jojoo/otterclip/internal/core"
 )
 
+// DefaultMaxItems is the history cap when Config.MaxItems is unset.
+const DefaultMaxItems = 5000
+
 type Config struct {
+	// MaxItems caps the history: each capture deletes (or trashes, see
+	// TrashEvicted) the oldest unpinned items beyond it.
 	MaxItems           int
 	DedupeConsecutive  bool
 	PrivacyIgnoreEmpty bool
+
+	// AppRules are applied to clips whose source is known.
+	AppRules core.AppRules
+
+	// TagRules auto-tag items before they are stored.
+	TagRules core.TagRules
+
+	// ConcealedTTL controls clips flagged as concealed by the source app
+	// (see core.IsConcealed). Zero skips them entirely; a positive value
+	// keeps them for that long before PurgeExpired removes them.
+	ConcealedTTL time.Duration
+
+	// TrashEvicted moves items evicted by MaxItems to the trash instead of
+	// deleting them, when the store supports it (storage.TrashStore).
+	TrashEvicted bool
+
+	// TrashTTL permanently deletes trashed items after this long (checked
+	// by PurgeExpired). Zero keeps them until the trash is emptied.
+	TrashTTL time.Duration
+
+	// OnError receives errors from processors registered with Use, and
+	// from counting captures (as processor "counters"). They never abort
+	// a capture; nil discards them.
+	OnError func(processor string, err error)
+}
+
+// Clip is a raw clipboard capture plus whatever the adapter could tell
+// about where it came from.
+type Clip struct {
+	Text   string
+	Source core.Source
+
+	// Targets are the formats (MIME types / pasteboard types) offered by
+	// the clipboard owner, used to spot password manager hints.
+	Targets []string
 }
 
 type Service struct {
 	store   storage.Store
 	privacy *core.PrivacyFilter
 	cfg     Config
+	procs   [numStages][]Processor
 
 	lastFingerprint string
 }
 
 func New(store storage.Store, privacy *core.PrivacyFilter, cfg Config) *Service {
 	if cfg.MaxItems <= 0 {
-		cfg.MaxItems = 5000
+		cfg.MaxItems = DefaultMaxItems
 	}
 	if !cfg.DedupeConsecutive {
 		cfg.DedupeConsecutive = true
@@ -36,33 +79,100 @@ func New(store storage.Store, privacy *core.PrivacyFilter, cfg Config) *Service
 }
 
 func (s *Service) ProcessText(ctx context.Context, raw string) (*core.Item, bool, error) {
-	normalized := core.Normalize(raw)
+	return s.ProcessClip(ctx, Clip{Text: raw})
+}
+
+func (s *Service) ProcessClip(ctx context.Context, clip Clip) (*core.Item, bool, error) {
+	rule, hasRule := s.cfg.AppRules.Match(clip.Source)
+	if hasRule && rule.Ignore {
+		s.count(ctx, core.CountIgnoredApp)
+		return nil, false, nil
+	}
+	concealed := core.IsConcealed(clip.Targets)
+	if concealed && s.cfg.ConcealedTTL <= 0 {
+		s.count(ctx, core.CountIgnoredConcealed)
+		return nil, false, nil
+	}
+
+	normalized := core.Normalize(clip.Text)
 	if s.cfg.PrivacyIgnoreEmpty && normalized == "" {
 		return nil, false, nil
 	}
 	if s.privacy != nil && s.privacy.ShouldIgnore(normalized) {
+		s.count(ctx, core.CountIgnoredPrivacy)
 		return nil, false, nil
 	}
 
-	fp := core.Fingerprint(normalized)
+	now := s.store.Now()
+	item := core.Item{
+		Content:    normalized,
+		Source:     clip.Source,
+		CreatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if !s.runStage(ctx, StageFilter, &item) || !s.runStage(ctx, StageTransform, &item) {
+		s.count(ctx, core.CountFiltered)
+		return nil, false, nil
+	}
+	// transformers may reintroduce whitespace
+	item.Content = core.Normalize(item.Content)
+	if item.Content == "" {
+		return nil, false, nil
+	}
+
+	fp := core.Fingerprint(item.Content)
 	if s.cfg.DedupeConsecutive && fp != "" && fp == s.lastFingerprint {
+		s.count(ctx, core.CountDeduped)
+		s.sighting(ctx, fp)
 		return nil, false, nil
 	}
 
-	now := s.store.Now()
-	item := core.Item{
-		ID:          uuid.NewString(),
-		Content:     normalized,
-		Type:        core.DetectType(normalized),
-		Fingerprint: fp,
-		CreatedAt:   now,
-		LastSeenAt:  now,
+	item.ID = uuid.NewString()
+	item.Fingerprint = fp
+	// detect on the raw text when we can: line structure (YAML, diffs,
+	// stack traces) is lost by normalization
+	detectOn := item.Content
+	if item.Content == normalized {
+		detectOn = clip.Text
+	}
+	item.Type = core.DetectType(detectOn)
+	if hasRule && rule.Type != "" {
+		item.Type = rule.Type
+	}
+	if lang := core.ItemLanguage(item.Type, detectOn); lang != "" {
+		if item.Meta == nil {
+			item.Meta = make(map[string]string)
+		}
+		item.Meta[core.MetaLanguage] = string(lang)
+	}
+	item.Tags = s.cfg.TagRules.Apply(item)
+	if concealed {
+		item.ExpiresAt = now.Add(s.cfg.ConcealedTTL)
+	}
+
+	content := item.Content
+	if !s.runStage(ctx, StageEnrich, &item) {
+		s.count(ctx, core.CountFiltered)
+		return nil, false, nil
 	}
+	// enrichers annotate; content and identity are settled by now
+	item.Content, item.Fingerprint = content, fp
 
 	// Save
+	counter := core.CountStored
+	if finder, ok := s.store.(storage.ItemFinder); ok {
+		if _, err := finder.FindByFingerprint(ctx, fp); err == nil {
+			counter = core.CountDeduped
+		}
+	}
 	if err := s.store.Put(ctx, item, storage.PutInsert); err != nil {
 		return nil, false, err
 	}
+	s.count(ctx, counter)
+	if counter == core.CountDeduped {
+		s.sighting(ctx, fp)
+	}
 
 	s.lastFingerprint = fp
 
@@ -70,10 +180,40 @@ func (s *Service) ProcessText(ctx context.Context, raw string) (*core.Item, bool
 	if err := s.enforceRetention(ctx); err != nil {
 		return nil, false, err
 	}
+	if err := s.PurgeExpired(ctx); err != nil {
+		return nil, false, err
+	}
+
+	s.runStage(ctx, StageSink, &item)
 
 	return &item, true, nil
 }
 
+// count bumps a capture counter (core.CountStored and friends) when the
+// store keeps them.
+func (s *Service) count(ctx context.Context, name string) {
+	cs, ok := s.store.(storage.CounterStore)
+	if !ok {
+		return
+	}
+	if err := cs.AddCount(ctx, name, s.store.Now(), 1); err != nil && s.cfg.OnError != nil {
+		s.cfg.OnError("counters", err)
+	}
+}
+
+// sighting counts a repeat capture of the item holding fp. A consecutive
+// repeat of an item deleted in between has nothing left to count.
+func (s *Service) sighting(ctx context.Context, fp string) {
+	ss, ok := s.store.(storage.SightingStore)
+	if !ok {
+		return
+	}
+	err := ss.AddSighting(ctx, fp)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) && s.cfg.OnError != nil {
+		s.cfg.OnError("counters", err)
+	}
+}
+
 func (s *Service) enforceRetention(ctx context.Context) error {
 	items, err := s.store.ListRecent(ctx, s.cfg.MaxItems+200) // small window to find eviction candidates
 	if err != nil {
@@ -86,13 +226,20 @@ func (s *Service) enforceRetention(ctx context.Context) error {
 	// items are newest->oldest in our memory store; eviction should target oldest non-pinned
 	over := len(items) - s.cfg.MaxItems
 
+	trash, canTrash := s.store.(storage.TrashStore)
+
 	for i := len(items) - 1; i >= 0 && over > 0; i-- {
 		it := items[i]
 		if it.Pinned {
 			continue
 		}
 		if it.ID != "" {
-			if err := s.store.Delete(ctx, it.ID); err != nil {
+			remove := s.store.Delete
+			// expiring items are secrets; never keep them around in the trash
+			if canTrash && s.cfg.TrashEvicted && it.ExpiresAt.IsZero() {
+				remove = trash.Trash
+			}
+			if err := remove(ctx, it.ID); err != nil {
 				// ignore not found in case store changed
 				continue
 			}
@@ -101,3 +248,40 @@ func (s *Service) enforceRetention(ctx context.Context) error {
 	}
 	return nil
 }
+
+// PurgeExpired deletes items whose ExpiresAt has passed, including ones
+// sitting in the trash, and trashed items older than TrashTTL. It runs
+// after every capture; watchers should also call it periodically so TTLs
+// hold while the clipboard is idle.
+func (s *Service) PurgeExpired(ctx context.Context) error {
+	items, err := s.store.ListRecent(ctx, s.cfg.MaxItems+200)
+	if err != nil {
+		return err
+	}
+	trash, canTrash := s.store.(storage.TrashStore)
+	if canTrash {
+		trashed, err := trash.ListTrash(ctx, s.cfg.MaxItems+200)
+		if err != nil {
+			return err
+		}
+		items = append(items, trashed...)
+	}
+
+	now := s.store.Now()
+	for _, it := range items {
+		if it.Pinned || !it.Expired(now) {
+			continue
+		}
+		if err := s.store.Delete(ctx, it.ID); err != nil {
+			// ignore not found in case store changed
+			continue
+		}
+	}
+
+	if canTrash && s.cfg.TrashTTL > 0 {
+		if _, err := trash.PurgeTrash(ctx, now.Add(-s.cfg.TrashTTL)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,10 +2,14 @@ package capture
 
 import (
 	"context"
+	"reflect"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
 	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/search"
 )
 
 func TestProcessText_IgnoresEmpty(t *testing.T) {
@@ -101,3 +105,330 @@ func TestRetention_EvictsOldestNonPinned(t *testing.T) {
 		t.Fatalf("expected pinned 'one' to remain")
 	}
 }
+
+func TestRetention_TrashEvicted(t *testing.T) {
+	st := memory.New()
+	now := time.Now()
+	st.SetClock(func() time.Time { return now })
+	svc := New(st, nil, Config{MaxItems: 1, TrashEvicted: true, TrashTTL: time.Hour})
+	ctx := context.Background()
+
+	for _, txt := range []string{"one", "two"} {
+		if _, saved, err := svc.ProcessText(ctx, txt); err != nil || !saved {
+			t.Fatalf("expected %q saved (err=%v)", txt, err)
+		}
+	}
+
+	trashed, err := st.ListTrash(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashed) != 1 || trashed[0].Content != "one" {
+		t.Fatalf("expected evicted item in trash, got %+v", trashed)
+	}
+	if n, _ := st.Count(ctx); n != 1 {
+		t.Fatalf("expected trashed item hidden from count, got %d", n)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := svc.PurgeExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if trashed, _ := st.ListTrash(ctx, 10); len(trashed) != 0 {
+		t.Fatalf("expected trash purged after TTL, got %+v", trashed)
+	}
+}
+
+func TestProcessClip_AppRules(t *testing.T) {
+	st := memory.New()
+	rules, err := core.ParseAppRules("keepassxc=ignore,kitty=command")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := New(st, nil, Config{MaxItems: 10, AppRules: rules})
+	ctx := context.Background()
+
+	_, saved, err := svc.ProcessClip(ctx, Clip{Text: "hunter2", Source: core.Source{WindowClass: "KeePassXC"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved {
+		t.Fatalf("expected clip from keepassxc to be ignored")
+	}
+
+	it, saved, err := svc.ProcessClip(ctx, Clip{Text: "ls -la", Source: core.Source{Process: "kitty"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved || it.Type != core.ContentTypeCommand {
+		t.Fatalf("expected kitty clip saved as command, got %+v", it)
+	}
+	if it.Source.Process != "kitty" {
+		t.Fatalf("expected source to be recorded, got %+v", it.Source)
+	}
+}
+
+func TestProcessClip_ConcealedSkipped(t *testing.T) {
+	st := memory.New()
+	svc := New(st, nil, Config{MaxItems: 10})
+
+	_, saved, err := svc.ProcessClip(context.Background(), Clip{
+		Text:    "hunter2",
+		Targets: []string{"UTF8_STRING", "x-kde-passwordManagerHint"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved {
+		t.Fatalf("expected concealed clip to be skipped")
+	}
+}
+
+func TestProcessClip_ConcealedTTL(t *testing.T) {
+	st := memory.New()
+	now := time.Now()
+	st.SetClock(func() time.Time { return now })
+	svc := New(st, nil, Config{MaxItems: 10, ConcealedTTL: 30 * time.Second})
+	ctx := context.Background()
+
+	it, saved, err := svc.ProcessClip(ctx, Clip{
+		Text:    "hunter2",
+		Targets: []string{"org.nspasteboard.ConcealedType"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved || it.ExpiresAt.IsZero() {
+		t.Fatalf("expected concealed clip saved with expiry, got %+v", it)
+	}
+
+	now = now.Add(time.Minute)
+	if err := svc.PurgeExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := st.Count(ctx); n != 0 {
+		t.Fatalf("expected expired clip purged, count=%d", n)
+	}
+}
+
+func TestProcessClip_ConcealedCopyKeepsPermanentItem(t *testing.T) {
+	st := memory.New()
+	now := time.Now()
+	st.SetClock(func() time.Time { return now })
+	svc := New(st, nil, Config{MaxItems: 10, ConcealedTTL: 30 * time.Second})
+	ctx := context.Background()
+
+	it, _, err := svc.ProcessText(ctx, "shared wifi password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetPinned(ctx, it.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetTitle(ctx, it.ID, "wifi"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := svc.ProcessText(ctx, "something else"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same secret copied again from a password manager
+	now = now.Add(time.Second)
+	if _, _, err := svc.ProcessClip(ctx, Clip{
+		Text:    "shared wifi password",
+		Targets: []string{"x-kde-passwordManagerHint"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Minute)
+	if err := svc.PurgeExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got, err := st.FindByFingerprint(ctx, it.Fingerprint)
+	if err != nil {
+		t.Fatalf("permanent item purged: %v", err)
+	}
+	if !got.ExpiresAt.IsZero() || !got.Pinned || got.Title != "wifi" {
+		t.Fatalf("expected item to stay permanent and pinned, got %+v", got)
+	}
+}
+
+func TestPurgeExpired_KeepsPinned(t *testing.T) {
+	st := memory.New()
+	now := time.Now()
+	st.SetClock(func() time.Time { return now })
+	svc := New(st, nil, Config{MaxItems: 10, ConcealedTTL: 30 * time.Second})
+	ctx := context.Background()
+
+	it, _, err := svc.ProcessClip(ctx, Clip{Text: "hunter2", Targets: []string{"org.nspasteboard.ConcealedType"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SetPinned(ctx, it.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Minute)
+	if err := svc.PurgeExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := st.Count(ctx); n != 1 {
+		t.Fatalf("expected pinned expiring item kept, count=%d", n)
+	}
+}
+
+func TestProcessText_DetectsOnRawText(t *testing.T) {
+	st := memory.New()
+	svc := New(st, nil, Config{MaxItems: 10})
+
+	it, saved, err := svc.ProcessText(context.Background(), "apiVersion: v1\nkind: Pod\nmetadata:\n  name: otter\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved || it.Type != core.ContentTypeYAML {
+		t.Fatalf("expected yaml, got %+v", it)
+	}
+}
+
+func TestProcessText_CodeLanguage(t *testing.T) {
+	st := memory.New()
+	svc := New(st, nil, Config{MaxItems: 10})
+
+	it, saved, err := svc.ProcessText(context.Background(), "def greet(name):\n    return f\"hello {name}\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved || it.Type != core.ContentTypeCode {
+		t.Fatalf("expected code, got %+v", it)
+	}
+	if it.Meta[core.MetaLanguage] != string(core.LangPython) {
+		t.Fatalf("expected python language, got %v", it.Meta)
+	}
+}
+
+func TestProcessText_ScriptAndQueryLanguages(t *testing.T) {
+	st := memory.New()
+	svc := New(st, nil, Config{MaxItems: 10})
+	ctx := context.Background()
+
+	for _, text := range []string{
+		"SELECT id, name FROM users WHERE id = 1",
+		"#!/bin/bash\nset -e\nfor f in *.txt; do\n  echo \"$f\"\ndone\n",
+		"kubectl get pods -n kube-system | grep dns",
+		"just some prose",
+	} {
+		if _, saved, err := svc.ProcessText(ctx, text); err != nil || !saved {
+			t.Fatalf("ProcessText(%q) = %v, %v", text, saved, err)
+		}
+	}
+
+	find := search.New(st)
+	for query, want := range map[string][]string{
+		"lang:sql":   {"SELECT id, name FROM users WHERE id = 1"},
+		"lang:shell": {`#!/bin/bash set -e for f in *.txt; do echo "$f" done`, "kubectl get pods -n kube-system | grep dns"},
+	} {
+		got, err := find.Query(ctx, query, search.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var contents []string
+		for _, it := range got {
+			contents = append(contents, it.Content)
+		}
+		slices.Sort(contents)
+		if !reflect.DeepEqual(contents, want) {
+			t.Fatalf("%s found %q, want %q", query, contents, want)
+		}
+	}
+}
+
+func TestProcessText_TagRules(t *testing.T) {
+	st := memory.New()
+	rules, err := core.ParseTagRules(`jira=re:\b[A-Z]+-\d+\b;links=type:url`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := New(st, nil, Config{MaxItems: 10, TagRules: rules})
+
+	it, saved, err := svc.ProcessText(context.Background(), "https://jira.example.com/browse/OPS-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved || len(it.Tags) != 2 || it.Tags[0] != "jira" || it.Tags[1] != "links" {
+		t.Fatalf("expected jira+links tags, got %+v", it)
+	}
+
+	tags, err := st.ListTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("expected tags stored, got %+v", tags)
+	}
+}
+
+// sightingStore records AddSighting calls.
+type sightingStore struct {
+	*memory.Store
+	sightings map[string]int
+}
+
+func (s *sightingStore) AddSighting(_ context.Context, fp string) error {
+	s.sightings[fp]++
+	return nil
+}
+
+func TestProcessClip_Counts(t *testing.T) {
+	st := &sightingStore{Store: memory.New(), sightings: make(map[string]int)}
+	pf, err := core.NewPrivacyFilter([]string{"token="}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := core.ParseAppRules("keepassxc=ignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := New(st, pf, Config{MaxItems: 10, AppRules: rules})
+	ctx := context.Background()
+
+	for _, c := range []Clip{
+		{Text: "one"},
+		{Text: "one"}, // consecutive repeat
+		{Text: "two"},
+		{Text: "one"}, // already in history
+		{Text: "token=abc"},
+		{Text: "hunter2", Source: core.Source{WindowClass: "KeePassXC"}},
+		{Text: "hunter3", Targets: []string{"x-kde-passwordManagerHint"}},
+		{Text: " \n "},
+	} {
+		if _, _, err := svc.ProcessClip(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]int{
+		core.CountStored:           2,
+		core.CountDeduped:          2,
+		core.CountIgnoredPrivacy:   1,
+		core.CountIgnoredApp:       1,
+		core.CountIgnoredConcealed: 1,
+		core.CountFiltered:         0,
+	}
+	for name, n := range want {
+		counts, err := st.Counts(ctx, name, time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := 0
+		for _, c := range counts {
+			got += c.N
+		}
+		if got != n {
+			t.Errorf("%s = %d, want %d", name, got, n)
+		}
+	}
+	// repeats are sightings, consecutive or not
+	if want := map[string]int{core.Fingerprint("one"): 2}; !reflect.DeepEqual(st.sightings, want) {
+		t.Errorf("sightings = %v, want %v", st.sightings, want)
+	}
+}
@@ -0,0 +1,115 @@
+package capture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// Stage decides where a processor runs in the capture pipeline:
+//
+//	normalize → privacy → [filters] → [transformers] → fingerprint/dedupe
+//	→ detect → [enrichers] → store → retention → [sinks]
+type Stage int
+
+const (
+	// StageFilter processors see normalized content and usually drop it.
+	StageFilter Stage = iota
+	// StageTransform processors rewrite Content before it is fingerprinted.
+	StageTransform
+	// StageEnrich processors annotate a fully built item (Type, Meta)
+	// right before it is stored.
+	StageEnrich
+	// StageSink processors observe items after they are stored. Changes
+	// they make to the item are discarded and they cannot drop it.
+	StageSink
+
+	numStages
+)
+
+func (st Stage) String() string {
+	switch st {
+	case StageFilter:
+		return "filter"
+	case StageTransform:
+		return "transform"
+	case StageEnrich:
+		return "enrich"
+	case StageSink:
+		return "sink"
+	default:
+		return fmt.Sprintf("stage(%d)", int(st))
+	}
+}
+
+// ErrDrop is returned by a processor to discard the clip.
+var ErrDrop = errors.New("capture: drop item")
+
+// Processor is one step of the capture pipeline. It may modify the item in
+// place, return ErrDrop to discard it, or return any other error, in which
+// case its changes are rolled back, the error is reported through
+// Config.OnError and the pipeline continues without it.
+type Processor interface {
+	Name() string
+	Process(ctx context.Context, it *core.Item) error
+}
+
+// ProcessorFunc adapts a function to the Processor interface.
+func ProcessorFunc(name string, fn func(ctx context.Context, it *core.Item) error) Processor {
+	return funcProcessor{name: name, fn: fn}
+}
+
+type funcProcessor struct {
+	name string
+	fn   func(ctx context.Context, it *core.Item) error
+}
+
+func (p funcProcessor) Name() string { return p.name }
+
+func (p funcProcessor) Process(ctx context.Context, it *core.Item) error { return p.fn(ctx, it) }
+
+// Use appends processors to a stage. Processors run in registration order.
+func (s *Service) Use(stage Stage, procs ...Processor) {
+	if stage < 0 || stage >= numStages {
+		panic("capture: unknown stage " + stage.String())
+	}
+	s.procs[stage] = append(s.procs[stage], procs...)
+}
+
+// runStage runs every processor of a stage against it and reports whether
+// the item should be kept.
+func (s *Service) runStage(ctx context.Context, stage Stage, it *core.Item) bool {
+	for _, p := range s.procs[stage] {
+		work := *it
+		work.Meta = maps.Clone(it.Meta)
+
+		err := safeProcess(ctx, p, &work)
+		switch {
+		case err == nil:
+			if stage != StageSink {
+				*it = work
+			}
+		case errors.Is(err, ErrDrop):
+			if stage != StageSink {
+				return false
+			}
+		default:
+			if s.cfg.OnError != nil {
+				s.cfg.OnError(p.Name(), err)
+			}
+		}
+	}
+	return true
+}
+
+func safeProcess(ctx context.Context, p Processor, it *core.Item) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return p.Process(ctx, it)
+}
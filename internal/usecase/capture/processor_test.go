@@ -0,0 +1,109 @@
+package capture
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestPipeline_FilterDrops(t *testing.T) {
+	st := memory.New()
+	svc := New(st, nil, Config{MaxItems: 10})
+	svc.Use(StageFilter, ProcessorFunc("no-internal", func(ctx context.Context, it *core.Item) error {
+		if strings.Contains(it.Content, "internal.corp") {
+			return ErrDrop
+		}
+		return nil
+	}))
+
+	_, saved, err := svc.ProcessText(context.Background(), "https://wiki.internal.corp/page")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved {
+		t.Fatalf("expected filter to drop clip")
+	}
+	if n, _ := st.Count(context.Background()); n != 0 {
+		t.Fatalf("expected nothing stored, count=%d", n)
+	}
+}
+
+func TestPipeline_TransformAndEnrich(t *testing.T) {
+	st := memory.New()
+	svc := New(st, nil, Config{MaxItems: 10})
+	svc.Use(StageTransform, StripQueryParams("utm_*", "fbclid"))
+	svc.Use(StageEnrich, MatchMeta("jira", regexp.MustCompile(`\b[A-Z][A-Z0-9]+-\d+\b`)))
+
+	it, saved, err := svc.ProcessText(context.Background(), "https://jira.example.com/browse/OPS-12?utm_source=mail&fbclid=x&focus=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved {
+		t.Fatalf("expected saved")
+	}
+	if it.Content != "https://jira.example.com/browse/OPS-12?focus=1" {
+		t.Fatalf("expected tracking params stripped, got %q", it.Content)
+	}
+	if it.Fingerprint != core.Fingerprint(it.Content) {
+		t.Fatalf("expected fingerprint of transformed content")
+	}
+	if it.Meta["jira"] != "OPS-12" {
+		t.Fatalf("expected jira annotation, got %v", it.Meta)
+	}
+
+	items, _ := st.ListRecent(context.Background(), 10)
+	if len(items) != 1 || items[0].Meta["jira"] != "OPS-12" {
+		t.Fatalf("expected annotation stored, got %+v", items)
+	}
+}
+
+func TestPipeline_ErrorsAreIsolated(t *testing.T) {
+	st := memory.New()
+	var reported []string
+	svc := New(st, nil, Config{
+		MaxItems: 10,
+		OnError:  func(name string, err error) { reported = append(reported, name) },
+	})
+
+	svc.Use(StageEnrich,
+		ProcessorFunc("broken", func(ctx context.Context, it *core.Item) error {
+			it.Meta = map[string]string{"half": "done"}
+			return errors.New("boom")
+		}),
+		ProcessorFunc("panicky", func(ctx context.Context, it *core.Item) error {
+			panic("oops")
+		}),
+		ProcessorFunc("ok", func(ctx context.Context, it *core.Item) error {
+			it.Meta = map[string]string{"ok": "1"}
+			return nil
+		}),
+	)
+
+	var sunk []string
+	svc.Use(StageSink, ProcessorFunc("sink", func(ctx context.Context, it *core.Item) error {
+		sunk = append(sunk, it.Content)
+		return ErrDrop // ignored for sinks
+	}))
+
+	it, saved, err := svc.ProcessText(context.Background(), "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved {
+		t.Fatalf("expected saved despite failing processors")
+	}
+	if it.Meta["half"] != "" || it.Meta["ok"] != "1" {
+		t.Fatalf("expected failed processor changes rolled back, got %v", it.Meta)
+	}
+	if len(reported) != 2 || reported[0] != "broken" || reported[1] != "panicky" {
+		t.Fatalf("expected both failures reported, got %v", reported)
+	}
+	if len(sunk) != 1 || sunk[0] != "hello" {
+		t.Fatalf("expected sink to observe saved item, got %v", sunk)
+	}
+}
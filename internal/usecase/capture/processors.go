@@ -0,0 +1,67 @@
+package capture
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// StripQueryParams returns a transformer that removes the given query
+// parameters (e.g. utm_source, fbclid) from URL clips. Names ending in "*"
+// match by prefix.
+func StripQueryParams(params ...string) Processor {
+	return ProcessorFunc("strip-query-params", func(ctx context.Context, it *core.Item) error {
+		_ = ctx
+		u, err := url.Parse(it.Content)
+		if err != nil || u.Scheme == "" || u.Host == "" || u.RawQuery == "" {
+			return nil
+		}
+
+		q := u.Query()
+		changed := false
+		for key := range q {
+			for _, p := range params {
+				if p == key || (strings.HasSuffix(p, "*") && strings.HasPrefix(key, strings.TrimSuffix(p, "*"))) {
+					q.Del(key)
+					changed = true
+					break
+				}
+			}
+		}
+		if changed {
+			u.RawQuery = q.Encode()
+			it.Content = u.String()
+		}
+		return nil
+	})
+}
+
+// MatchMeta returns an enricher that stores every match of re in
+// it.Meta[key], comma-separated (e.g. Jira keys: `\b[A-Z][A-Z0-9]+-\d+\b`).
+func MatchMeta(key string, re *regexp.Regexp) Processor {
+	return ProcessorFunc("match-meta:"+key, func(ctx context.Context, it *core.Item) error {
+		_ = ctx
+		matches := re.FindAllString(it.Content, -1)
+		if len(matches) == 0 {
+			return nil
+		}
+
+		seen := make(map[string]bool, len(matches))
+		uniq := matches[:0]
+		for _, m := range matches {
+			if !seen[m] {
+				seen[m] = true
+				uniq = append(uniq, m)
+			}
+		}
+
+		if it.Meta == nil {
+			it.Meta = make(map[string]string)
+		}
+		it.Meta[key] = strings.Join(uniq, ",")
+		return nil
+	})
+}
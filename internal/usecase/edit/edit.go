@@ -0,0 +1,111 @@
+package edit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+var ErrUnchanged = errors.New("content unchanged")
+
+// ErrTooLong is returned for content over core.MaxContentLen bytes, the
+// most capture keeps.
+var ErrTooLong = fmt.Errorf("content longer than %d bytes", core.MaxContentLen)
+
+type Store interface {
+	storage.Store
+	storage.RevisionStore
+}
+
+// Service edits stored items. Every edit goes through PutMerge, which keeps
+// the replaced content as a revision, so edits and reverts can be undone.
+type Service struct {
+	store Store
+}
+
+func New(store Store) *Service {
+	return &Service{store: store}
+}
+
+// Edit replaces an item's content. Content is kept as written (minus the
+// trailing newline editors add) but, like captured content, may be at most
+// core.MaxContentLen bytes; the fingerprint, type and language are
+// recomputed.
+func (s *Service) Edit(ctx context.Context, it core.Item, content string) (core.Item, error) {
+	content = strings.TrimRight(content, "\r\n")
+	if strings.TrimSpace(content) == "" {
+		return it, errors.New("empty content")
+	}
+	if content == it.Content {
+		return it, ErrUnchanged
+	}
+	if len(content) > core.MaxContentLen {
+		return it, ErrTooLong
+	}
+
+	it.Content = content
+	it.Fingerprint = core.Fingerprint(core.Normalize(content))
+	it.Type = core.DetectType(content)
+	it.Meta = maps.Clone(it.Meta)
+	delete(it.Meta, core.MetaLanguage)
+	if lang := core.ItemLanguage(it.Type, content); lang != "" {
+		if it.Meta == nil {
+			it.Meta = make(map[string]string)
+		}
+		it.Meta[core.MetaLanguage] = string(lang)
+	}
+
+	if err := s.store.Put(ctx, it, storage.PutMerge); err != nil {
+		return core.Item{}, err
+	}
+	return it, nil
+}
+
+// Revisions returns the item's previous contents, oldest first.
+func (s *Service) Revisions(ctx context.Context, itemID string) ([]core.Revision, error) {
+	return s.store.ListRevisions(ctx, itemID)
+}
+
+// Revert makes revision rev the current content. The content it replaces
+// becomes a new revision.
+func (s *Service) Revert(ctx context.Context, it core.Item, rev int) (core.Item, error) {
+	content, err := s.contentAt(ctx, it, rev)
+	if err != nil {
+		return core.Item{}, err
+	}
+	return s.Edit(ctx, it, content)
+}
+
+// Diff compares two revisions of an item; rev 0 is the current content.
+func (s *Service) Diff(ctx context.Context, it core.Item, from, to int) ([]core.DiffLine, error) {
+	a, err := s.contentAt(ctx, it, from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.contentAt(ctx, it, to)
+	if err != nil {
+		return nil, err
+	}
+	return core.DiffLines(a, b), nil
+}
+
+func (s *Service) contentAt(ctx context.Context, it core.Item, rev int) (string, error) {
+	if rev == 0 {
+		return it.Content, nil
+	}
+	revs, err := s.store.ListRevisions(ctx, it.ID)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range revs {
+		if r.Rev == rev {
+			return r.Content, nil
+		}
+	}
+	return "", fmt.Errorf("item has no revision %d", rev)
+}
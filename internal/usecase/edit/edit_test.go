@@ -0,0 +1,128 @@
+package edit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+)
+
+func setup(t *testing.T, texts ...string) (*Service, *memory.Store, []core.Item) {
+	t.Helper()
+	st := memory.New()
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 10})
+	var items []core.Item
+	for _, txt := range texts {
+		it, saved, err := capSvc.ProcessText(context.Background(), txt)
+		if err != nil || !saved {
+			t.Fatalf("expected %q captured (err=%v)", txt, err)
+		}
+		items = append(items, *it)
+	}
+	return New(st), st, items
+}
+
+func TestEditKeepsRevisions(t *testing.T) {
+	svc, st, items := setup(t, "helo wrold")
+	ctx := context.Background()
+
+	it, err := svc.Edit(ctx, items[0], "hello wrold\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	it, err = svc.Edit(ctx, it, "hello world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Fingerprint != core.Fingerprint("hello world") {
+		t.Fatalf("expected fingerprint recomputed")
+	}
+
+	recent, _ := st.ListRecent(ctx, 10)
+	if len(recent) != 1 || recent[0].Content != "hello world" {
+		t.Fatalf("expected edited item stored, got %+v", recent)
+	}
+
+	revs, err := svc.Revisions(ctx, it.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Content != "helo wrold" || revs[1].Content != "hello wrold" {
+		t.Fatalf("unexpected revisions: %+v", revs)
+	}
+
+	if _, err := svc.Edit(ctx, it, "hello world"); !errors.Is(err, ErrUnchanged) {
+		t.Fatalf("expected ErrUnchanged, got %v", err)
+	}
+}
+
+func TestEditDetectsType(t *testing.T) {
+	svc, _, items := setup(t, "see example dot com")
+	it, err := svc.Edit(context.Background(), items[0], "https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Type != core.ContentTypeURL {
+		t.Fatalf("expected url type, got %s", it.Type)
+	}
+}
+
+func TestEditRejectsDuplicate(t *testing.T) {
+	svc, _, items := setup(t, "first", "second")
+	if _, err := svc.Edit(context.Background(), items[1], "first"); !errors.Is(err, storage.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate, got %v", err)
+	}
+}
+
+func TestEditRejectsTooLong(t *testing.T) {
+	svc, st, items := setup(t, "short")
+	long := strings.Repeat("x", core.MaxContentLen+1)
+	if _, err := svc.Edit(context.Background(), items[0], long); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("expected ErrTooLong, got %v", err)
+	}
+	if _, err := svc.Edit(context.Background(), items[0], long[1:]+"\n"); err != nil {
+		t.Fatalf("content of exactly MaxContentLen bytes should be accepted: %v", err)
+	}
+	if revs, _ := st.ListRevisions(context.Background(), items[0].ID); len(revs) != 1 {
+		t.Fatalf("expected one revision, got %d", len(revs))
+	}
+}
+
+func TestDiffAndRevert(t *testing.T) {
+	svc, _, items := setup(t, "line one")
+	ctx := context.Background()
+
+	it, err := svc.Edit(ctx, items[0], "line one\nline two")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := svc.Diff(ctx, it, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := core.FormatDiff(d); got != " line one\n+line two\n" {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+
+	it, err = svc.Revert(ctx, it, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Content != "line one" {
+		t.Fatalf("expected reverted content, got %q", it.Content)
+	}
+	revs, _ := svc.Revisions(ctx, it.ID)
+	if len(revs) != 2 || revs[1].Content != "line one\nline two" {
+		t.Fatalf("expected revert to be undoable, got %+v", revs)
+	}
+
+	if _, err := svc.Revert(ctx, it, 9); err == nil {
+		t.Fatalf("expected error for missing revision")
+	}
+}
@@ -0,0 +1,298 @@
+// Package importer merges history read by importfmt into the store.
+// Content is normalized and fingerprinted exactly like captures, so
+// imported clips dedupe against the existing history and each other.
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/its-jojoo/otterclip/internal/adapter/importfmt"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+type Store interface {
+	storage.Store
+	storage.ItemFinder
+}
+
+type Outcome int
+
+const (
+	Added Outcome = iota
+	Merged
+	Skipped
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Added:
+		return "add"
+	case Merged:
+		return "merge"
+	default:
+		return "skip"
+	}
+}
+
+// Entry reports what happened to one record.
+type Entry struct {
+	Outcome Outcome
+	// Preview is the start of the record's first line.
+	Preview string
+	// Reason explains a merge (what changed) or a skip.
+	Reason string
+}
+
+type Report struct {
+	Added, Merged, Skipped int
+	// Items is how many items the history holds afterwards.
+	Items int
+	// OverCap is how far Items exceeds Options.MaxItems: the next
+	// captures delete that many of the oldest unpinned items.
+	OverCap int
+}
+
+type Options struct {
+	// DryRun reports what would happen without writing anything.
+	DryRun bool
+	// OnEntry, when set, receives an entry per record as it is handled.
+	OnEntry func(Entry)
+	// MaxItems is the history cap captures enforce (capture.Config), for
+	// Report.OverCap; zero skips the check.
+	MaxItems int
+}
+
+type Service struct {
+	store Store
+}
+
+func New(store Store) *Service {
+	return &Service{store: store}
+}
+
+// Import reads r with f and merges its records into the store:
+//   - new content is added, keeping the source's timestamps, pin, title,
+//     note and tags (and, from OtterClip exports, ID, type and meta);
+//   - content already in history is merged: pins and tags are added, an
+//     empty title or note is filled and a later last-seen time wins;
+//   - entries without importable text are skipped.
+//
+// Records without timestamps are stamped "now", a millisecond apart in
+// file order, so newest-first sources keep their order.
+func (s *Service) Import(ctx context.Context, f importfmt.Format, r io.Reader, opts Options) (Report, error) {
+	var (
+		rep  Report
+		now  = s.store.Now()
+		n    int
+		seen = make(map[string]bool)
+	)
+	before, err := s.store.Count(ctx)
+	if err != nil {
+		return rep, err
+	}
+	err = f.Read(r, func(rec importfmt.Record) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		e, err := s.record(ctx, rec, now.Add(-time.Duration(n)*time.Millisecond), seen, opts.DryRun)
+		if err != nil {
+			return err
+		}
+		n++
+		switch e.Outcome {
+		case Added:
+			rep.Added++
+		case Merged:
+			rep.Merged++
+		default:
+			rep.Skipped++
+		}
+		if opts.OnEntry != nil {
+			opts.OnEntry(e)
+		}
+		return nil
+	})
+
+	rep.Items = before + rep.Added
+	if !opts.DryRun {
+		if n, cerr := s.store.Count(ctx); cerr == nil {
+			rep.Items = n
+		}
+	}
+	if opts.MaxItems > 0 {
+		rep.OverCap = max(rep.Items-opts.MaxItems, 0)
+	}
+	return rep, err
+}
+
+func (s *Service) record(ctx context.Context, rec importfmt.Record, stamp time.Time, seen map[string]bool, dryRun bool) (Entry, error) {
+	content := core.Normalize(rec.Content)
+	e := Entry{Preview: preview(content), Reason: rec.Skip}
+	if rec.Skip != "" {
+		e.Outcome = Skipped
+		return e, nil
+	}
+	if content == "" {
+		e.Outcome, e.Reason = Skipped, "empty"
+		return e, nil
+	}
+	fp := core.Fingerprint(content)
+
+	// a dry run writes nothing, so repeats within the file have to be
+	// remembered here
+	repeat := seen[fp]
+	if dryRun && repeat {
+		e.Outcome, e.Reason = Merged, "repeats an earlier entry"
+		return e, nil
+	}
+	seen[fp] = true
+
+	existing, err := s.store.FindByFingerprint(ctx, fp)
+	switch {
+	case err == nil:
+		e.Outcome = Merged
+		e.Reason, err = s.merge(ctx, existing, rec, dryRun)
+		if repeat {
+			e.Reason = strings.Replace(e.Reason, "already in history", "repeats an earlier entry", 1)
+		}
+		return e, err
+	case !errors.Is(err, storage.ErrNotFound):
+		return e, err
+	}
+
+	e.Outcome = Added
+	if dryRun {
+		return e, nil
+	}
+	if rec.ID != "" {
+		// OtterClip's own exports hold content as stored, edits included;
+		// others are normalized like captures
+		content = rec.Content
+	}
+	it := newItem(rec, content, fp, stamp)
+	if rec.ID != "" {
+		// keep exported IDs unless another item already has one
+		taken, err := s.store.FindByIDPrefix(ctx, rec.ID, 1)
+		if err != nil {
+			return e, err
+		}
+		if len(taken) == 0 || taken[0].ID != rec.ID {
+			it.ID = rec.ID
+		}
+	}
+	return e, s.store.Put(ctx, it, storage.PutInsert)
+}
+
+func newItem(rec importfmt.Record, content, fp string, stamp time.Time) core.Item {
+	created, seen := rec.CreatedAt, rec.LastSeenAt
+	switch {
+	case created.IsZero() && seen.IsZero():
+		created, seen = stamp, stamp
+	case created.IsZero():
+		created = seen
+	case seen.IsZero():
+		seen = created
+	}
+
+	it := core.Item{
+		ID:          uuid.NewString(),
+		Content:     content,
+		Fingerprint: fp,
+		CreatedAt:   created,
+		LastSeenAt:  seen,
+		Pinned:      rec.Pinned,
+		Title:       rec.Title,
+		Note:        rec.Note,
+		Source:      rec.Source,
+		ExpiresAt:   rec.ExpiresAt,
+		Meta:        rec.Meta,
+		Tags:        rec.Tags,
+	}
+	typ, ok := core.ParseContentType(rec.Type)
+	if !ok {
+		// like capture, detect on the raw text: normalization loses line
+		// structure
+		typ = core.DetectType(rec.Content)
+	}
+	it.Type = typ
+	if it.Meta == nil {
+		if lang := core.ItemLanguage(typ, rec.Content); lang != "" {
+			it.Meta = map[string]string{core.MetaLanguage: string(lang)}
+		}
+	}
+	return it
+}
+
+// merge folds rec into the stored item and describes what changed.
+func (s *Service) merge(ctx context.Context, it core.Item, rec importfmt.Record, dryRun bool) (string, error) {
+	var changed []string
+	put := false
+	if rec.LastSeenAt.After(it.LastSeenAt) {
+		it.LastSeenAt = rec.LastSeenAt
+		changed, put = append(changed, "last seen"), true
+	}
+	if it.Title == "" && rec.Title != "" {
+		it.Title = rec.Title
+		changed, put = append(changed, "title"), true
+	}
+	if it.Note == "" && rec.Note != "" {
+		it.Note = rec.Note
+		changed, put = append(changed, "note"), true
+	}
+	pin := rec.Pinned && !it.Pinned
+	if pin {
+		changed = append(changed, "pinned")
+	}
+	var tags []string
+	for _, t := range rec.Tags {
+		if !slices.Contains(it.Tags, t) && !slices.Contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	ts, canTag := s.store.(storage.TagStore)
+	if len(tags) > 0 && canTag {
+		changed = append(changed, "tags")
+	}
+
+	reason := "already in history"
+	if len(changed) > 0 {
+		reason += "; " + strings.Join(changed, ", ")
+	}
+	if dryRun {
+		return reason, nil
+	}
+
+	if put {
+		if err := s.store.Put(ctx, it, storage.PutMerge); err != nil {
+			return reason, err
+		}
+	}
+	if pin {
+		if err := s.store.SetPinned(ctx, it.ID, true); err != nil {
+			return reason, err
+		}
+	}
+	if len(tags) > 0 && canTag {
+		if err := ts.AddTags(ctx, it.ID, tags...); err != nil {
+			return reason, err
+		}
+	}
+	return reason, nil
+}
+
+const previewLen = 60
+
+func preview(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	if r := []rune(s); len(r) > previewLen {
+		return string(r[:previewLen-1]) + "…"
+	}
+	return s
+}
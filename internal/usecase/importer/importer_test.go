@@ -0,0 +1,194 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/importfmt"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// records is a format yielding fixed records.
+func records(recs ...importfmt.Record) importfmt.Format {
+	return importfmt.Format{Name: "test", Read: func(_ io.Reader, yield func(importfmt.Record) error) error {
+		for _, r := range recs {
+			if err := yield(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
+
+func seed(t *testing.T, st *memory.Store, content string, mod func(*core.Item)) core.Item {
+	t.Helper()
+	now := st.Now()
+	it := core.Item{
+		ID:          "seed-" + content,
+		Content:     content,
+		Type:        core.ContentTypeText,
+		Fingerprint: core.Fingerprint(core.Normalize(content)),
+		CreatedAt:   now.Add(-time.Hour),
+		LastSeenAt:  now.Add(-time.Hour),
+	}
+	if mod != nil {
+		mod(&it)
+	}
+	if err := st.Put(context.Background(), it, storage.PutInsert); err != nil {
+		t.Fatal(err)
+	}
+	return it
+}
+
+func TestImport_AddMergeSkip(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	st.SetClock(func() time.Time { return now })
+	seed(t, st, "existing", nil)
+
+	later := now.Add(-time.Minute)
+	f := records(
+		importfmt.Record{Content: "  fresh\n", Pinned: true, Title: "T", Tags: []string{"x"}},
+		importfmt.Record{Content: "existing", Pinned: true, Note: "kept", LastSeenAt: later},
+		importfmt.Record{Content: "fresh"},
+		importfmt.Record{Content: " \n "},
+		importfmt.Record{Skip: "image"},
+		importfmt.Record{Content: "https://example.com"},
+	)
+	var entries []Entry
+	rep, err := New(st).Import(ctx, f, nil, Options{OnEntry: func(e Entry) { entries = append(entries, e) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep != (Report{Added: 2, Merged: 2, Skipped: 2, Items: 3}) {
+		t.Fatalf("report = %+v", rep)
+	}
+	if e := entries[1]; e.Outcome != Merged || e.Reason != "already in history; last seen, note, pinned" {
+		t.Fatalf("merge entry = %+v", e)
+	}
+	if e := entries[2]; !strings.HasPrefix(e.Reason, "repeats an earlier entry") {
+		t.Fatalf("repeat entry = %+v", e)
+	}
+
+	fresh, err := st.FindByFingerprint(ctx, core.Fingerprint("fresh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.Content != "fresh" || !fresh.Pinned || fresh.Title != "T" || len(fresh.Tags) != 1 {
+		t.Fatalf("added = %+v", fresh)
+	}
+	if !fresh.CreatedAt.Equal(now) {
+		t.Fatalf("undated record stamped %v, want %v", fresh.CreatedAt, now)
+	}
+
+	url, err := st.FindByFingerprint(ctx, core.Fingerprint("https://example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url.Type != core.ContentTypeURL {
+		t.Fatalf("type = %s", url.Type)
+	}
+	// later records are stamped earlier, keeping newest-first order
+	if !url.LastSeenAt.Before(fresh.LastSeenAt) {
+		t.Fatalf("order lost: %v vs %v", url.LastSeenAt, fresh.LastSeenAt)
+	}
+
+	ex, err := st.FindByFingerprint(ctx, core.Fingerprint("existing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex.ID != "seed-existing" || !ex.Pinned || ex.Note != "kept" || !ex.LastSeenAt.Equal(later) {
+		t.Fatalf("merged = %+v", ex)
+	}
+	if n, _ := st.Count(ctx); n != 3 {
+		t.Fatalf("count = %d, want 3", n)
+	}
+}
+
+func TestImport_MergeKeepsNewerAndExistingFields(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	orig := seed(t, st, "same", func(it *core.Item) { it.Title = "mine"; it.Pinned = true })
+
+	f := records(importfmt.Record{Content: "same", Title: "theirs", LastSeenAt: orig.LastSeenAt.Add(-time.Hour)})
+	var reason string
+	rep, err := New(st).Import(ctx, f, nil, Options{OnEntry: func(e Entry) { reason = e.Reason }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Merged != 1 || reason != "already in history" {
+		t.Fatalf("report = %+v, reason %q", rep, reason)
+	}
+	got, _ := st.FindByFingerprint(ctx, orig.Fingerprint)
+	if got.Title != "mine" || !got.LastSeenAt.Equal(orig.LastSeenAt) || !got.Pinned {
+		t.Fatalf("item = %+v", got)
+	}
+}
+
+func TestImport_DryRunWritesNothing(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	seed(t, st, "old", nil)
+
+	f := records(
+		importfmt.Record{Content: "new"},
+		importfmt.Record{Content: "new"},
+		importfmt.Record{Content: "old", Pinned: true},
+		importfmt.Record{Skip: "password"},
+	)
+	rep, err := New(st).Import(ctx, f, nil, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep != (Report{Added: 1, Merged: 2, Skipped: 1, Items: 2}) {
+		t.Fatalf("report = %+v", rep)
+	}
+	if n, _ := st.Count(ctx); n != 1 {
+		t.Fatalf("count = %d, dry run wrote items", n)
+	}
+	if it, _ := st.FindByFingerprint(ctx, core.Fingerprint("old")); it.Pinned {
+		t.Fatal("dry run pinned an item")
+	}
+}
+
+func TestImport_ReportsOverCap(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	seed(t, st, "old", nil)
+	f := records(importfmt.Record{Content: "a"}, importfmt.Record{Content: "b"}, importfmt.Record{Content: "old"})
+
+	for _, dry := range []bool{true, false} {
+		rep, err := New(st).Import(ctx, f, nil, Options{DryRun: dry, MaxItems: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rep.Items != 3 || rep.OverCap != 1 {
+			t.Fatalf("dry run %v: report = %+v", dry, rep)
+		}
+	}
+	if rep, _ := New(st).Import(ctx, f, nil, Options{}); rep.Items != 3 || rep.OverCap != 0 {
+		t.Fatalf("without a cap: report = %+v", rep)
+	}
+}
+
+func TestImport_ReadError(t *testing.T) {
+	boom := errors.New("boom")
+	f := importfmt.Format{Read: func(_ io.Reader, yield func(importfmt.Record) error) error {
+		if err := yield(importfmt.Record{Content: "a"}); err != nil {
+			return err
+		}
+		return boom
+	}}
+	st := memory.New()
+	rep, err := New(st).Import(context.Background(), f, nil, Options{})
+	if !errors.Is(err, boom) || rep.Added != 1 {
+		t.Fatalf("rep = %+v, err = %v", rep, err)
+	}
+}
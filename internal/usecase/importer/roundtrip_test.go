@@ -0,0 +1,118 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/exportfmt"
+	"github.com/its-jojoo/otterclip/internal/adapter/importfmt"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/sqlite"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+func TestRoundTrip_NDJSON(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, 3, 4, 5, 6, 7, 891234567, time.UTC)
+	want := []core.Item{
+		{
+			ID: "0b5e1c1e-aaaa-4bbb-8ccc-000000000001", Content: "func main() {\n\tfmt.Println(\"hi\")\n}",
+			Type: core.ContentTypeCode, CreatedAt: at.Add(-time.Hour), LastSeenAt: at,
+			Pinned: true, Title: "hello", Note: "a note\non two lines",
+			Source: core.Source{AppID: "code", WindowClass: "Code", Process: "code"},
+			Meta:   map[string]string{core.MetaLanguage: "go", "jira": "OPS-12"},
+			Tags:   []string{"go", "snippets"},
+		},
+		{
+			ID: "0b5e1c1e-aaaa-4bbb-8ccc-000000000002", Content: "https://example.com/?q=<a&b>",
+			Type: core.ContentTypeURL, CreatedAt: at.Add(-2 * time.Hour), LastSeenAt: at.Add(-time.Minute),
+			Source: core.Source{WindowClass: "firefox"},
+		},
+		{
+			ID: "0b5e1c1e-aaaa-4bbb-8ccc-000000000003", Content: "s3cret ünïcode ✓",
+			Type: core.ContentTypeText, CreatedAt: at.Add(-3 * time.Hour), LastSeenAt: at.Add(-2 * time.Minute),
+			ExpiresAt: at.Add(time.Hour),
+		},
+	}
+
+	stores := []struct {
+		name string
+		open func(t *testing.T) roundTripStore
+		// precision is what the store keeps of timestamps
+		precision time.Duration
+	}{
+		{"memory", func(*testing.T) roundTripStore { return memory.New() }, 0},
+		{"sqlite", openSQLite, time.Millisecond},
+	}
+	for _, sc := range stores {
+		t.Run(sc.name, func(t *testing.T) {
+			src := sc.open(t)
+			for _, it := range want {
+				it.Fingerprint = core.Fingerprint(core.Normalize(it.Content))
+				if err := src.Put(ctx, it, storage.PutInsert); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var buf bytes.Buffer
+			ndjson, _ := exportfmt.Lookup("ndjson")
+			w := ndjson.New(&buf, exportfmt.Options{})
+			if err := src.WalkRecent(ctx, 0, w.Write); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			dst := sc.open(t)
+			f, _ := importfmt.Lookup("otterclip")
+			rep, err := New(dst).Import(ctx, f, &buf, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rep.Added != len(want) {
+				t.Fatalf("report = %+v", rep)
+			}
+
+			for _, it := range want {
+				it.Fingerprint = core.Fingerprint(core.Normalize(it.Content))
+				got, err := dst.FindByFingerprint(ctx, it.Fingerprint)
+				if err != nil {
+					t.Fatalf("%s: %v", it.ID, err)
+				}
+				// times come back in UTC, compare instants
+				for _, ts := range []struct{ got, want *time.Time }{
+					{&got.CreatedAt, &it.CreatedAt}, {&got.LastSeenAt, &it.LastSeenAt}, {&got.ExpiresAt, &it.ExpiresAt},
+				} {
+					if !ts.got.Equal(ts.want.Truncate(sc.precision)) {
+						t.Errorf("%s: time %v, want %v", it.ID, *ts.got, *ts.want)
+					}
+					*ts.got = *ts.want
+				}
+				if !reflect.DeepEqual(got, it) {
+					t.Errorf("round trip changed item:\n got %+v\nwant %+v", got, it)
+				}
+			}
+		})
+	}
+}
+
+type roundTripStore interface {
+	Store
+	WalkRecent(ctx context.Context, limit int, fn func(core.Item) error) error
+}
+
+func openSQLite(t *testing.T) roundTripStore {
+	t.Helper()
+	st, err := sqlite.Open(filepath.Join(t.TempDir(), "x.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
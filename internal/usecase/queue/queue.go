@@ -0,0 +1,179 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+)
+
+// Mode decides which queued item Next serves.
+type Mode string
+
+const (
+	ModeOff  Mode = "off"
+	ModeFIFO Mode = "fifo" // paste in the order copied
+	ModeLIFO Mode = "lifo" // paste most recent first (stack)
+)
+
+func ParseMode(s string) (Mode, bool) {
+	switch Mode(s) {
+	case ModeOff, ModeFIFO, ModeLIFO:
+		return Mode(s), true
+	}
+	return "", false
+}
+
+const (
+	settingMode        = "queue.mode"
+	settingLastWritten = "queue.last_written"
+)
+
+type Store interface {
+	storage.QueueStore
+	storage.SettingsStore
+}
+
+// Writer puts text on the clipboard (see clipboard.Writer).
+type Writer interface {
+	WriteText(text string) error
+}
+
+// Service is the paste queue: while enabled, every captured item is pushed
+// and Next moves the clipboard to the following one. State lives in the
+// store, so the queue survives restarts and is shared between the watcher
+// and the CLI until cleared.
+type Service struct {
+	store Store
+	w     Writer
+}
+
+func New(store Store, w Writer) *Service {
+	return &Service{store: store, w: w}
+}
+
+func (s *Service) Mode(ctx context.Context) (Mode, error) {
+	v, ok, err := s.store.GetSetting(ctx, settingMode)
+	if err != nil || !ok {
+		return ModeOff, err
+	}
+	if m, ok := ParseMode(v); ok {
+		return m, nil
+	}
+	return ModeOff, nil
+}
+
+// SetMode enables the queue (fifo/lifo) or disables capture into it (off).
+// Disabling keeps queued items until Clear.
+func (s *Service) SetMode(ctx context.Context, m Mode) error {
+	if _, ok := ParseMode(string(m)); !ok {
+		return fmt.Errorf("invalid queue mode %q (expected fifo|lifo|off)", m)
+	}
+	return s.store.SetSetting(ctx, settingMode, string(m))
+}
+
+// Push adds an item unless the queue is off or the item is the one Next
+// just put on the clipboard (the watcher sees our own write as a copy).
+func (s *Service) Push(ctx context.Context, it core.Item) (bool, error) {
+	m, err := s.Mode(ctx)
+	if err != nil || m == ModeOff {
+		return false, err
+	}
+
+	last, _, err := s.store.GetSetting(ctx, settingLastWritten)
+	if err != nil {
+		return false, err
+	}
+	if last != "" && last == it.Fingerprint {
+		return false, s.store.SetSetting(ctx, settingLastWritten, "")
+	}
+
+	if err := s.store.QueuePush(ctx, it.Fingerprint); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Next pops the following item according to the mode and puts it on the
+// clipboard. It returns storage.ErrQueueEmpty when there is nothing left.
+func (s *Service) Next(ctx context.Context) (core.Item, error) {
+	m, err := s.Mode(ctx)
+	if err != nil {
+		return core.Item{}, err
+	}
+
+	it, err := s.store.QueuePop(ctx, m == ModeLIFO)
+	if err != nil {
+		return core.Item{}, err
+	}
+	return it, s.serve(ctx, it)
+}
+
+// Advance is Next after pasted was pasted by hand: when pasted is the
+// item Next would serve, it is dropped first so it isn't served twice.
+func (s *Service) Advance(ctx context.Context, pasted core.Item) (core.Item, error) {
+	m, err := s.Mode(ctx)
+	if err != nil {
+		return core.Item{}, err
+	}
+
+	it, err := s.store.QueuePop(ctx, m == ModeLIFO)
+	if err == nil && it.Fingerprint == pasted.Fingerprint {
+		it, err = s.store.QueuePop(ctx, m == ModeLIFO)
+	}
+	if err != nil {
+		return core.Item{}, err
+	}
+	return it, s.serve(ctx, it)
+}
+
+// Written tells Push that it is about to be put on the clipboard by us,
+// like Next does, so capturing that write doesn't queue it again.
+func (s *Service) Written(ctx context.Context, it core.Item) error {
+	return s.store.SetSetting(ctx, settingLastWritten, it.Fingerprint)
+}
+
+// serve puts it on the clipboard.
+func (s *Service) serve(ctx context.Context, it core.Item) error {
+	if err := s.Written(ctx, it); err != nil {
+		return err
+	}
+	return s.w.WriteText(it.Content)
+}
+
+// List returns queued items in the order Next will serve them.
+func (s *Service) List(ctx context.Context) ([]core.Item, error) {
+	items, err := s.store.QueueList(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.Mode(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m == ModeLIFO {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return items, nil
+}
+
+func (s *Service) Clear(ctx context.Context) error {
+	return s.store.QueueClear(ctx)
+}
+
+// Sink returns a capture processor that pushes every saved item.
+func (s *Service) Sink() capture.Processor {
+	return capture.ProcessorFunc("paste-queue", func(ctx context.Context, it *core.Item) error {
+		_, err := s.Push(ctx, *it)
+		return err
+	})
+}
+
+func IsEmpty(err error) bool {
+	return errors.Is(err, storage.ErrQueueEmpty)
+}
@@ -0,0 +1,142 @@
+package queue
+
+import (
+	"context"
+	"testing"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/usecase/capture"
+)
+
+type fakeClipboard struct {
+	text string
+}
+
+func (f *fakeClipboard) WriteText(text string) error {
+	f.text = text
+	return nil
+}
+
+func setup(t *testing.T, mode Mode) (*Service, *capture.Service, *fakeClipboard) {
+	t.Helper()
+	st := memory.New()
+	cb := &fakeClipboard{}
+	q := New(st, cb)
+	if err := q.SetMode(context.Background(), mode); err != nil {
+		t.Fatal(err)
+	}
+	capSvc := capture.New(st, nil, capture.Config{MaxItems: 10})
+	capSvc.Use(capture.StageSink, q.Sink())
+	return q, capSvc, cb
+}
+
+func copyAll(t *testing.T, capSvc *capture.Service, texts ...string) {
+	t.Helper()
+	for _, txt := range texts {
+		if _, saved, err := capSvc.ProcessText(context.Background(), txt); err != nil || !saved {
+			t.Fatalf("expected %q captured (err=%v)", txt, err)
+		}
+	}
+}
+
+func TestQueueFIFO(t *testing.T) {
+	q, capSvc, cb := setup(t, ModeFIFO)
+	ctx := context.Background()
+	copyAll(t, capSvc, "name", "street", "city")
+
+	list, err := q.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 3 || list[0].Content != "name" {
+		t.Fatalf("unexpected queue: %+v", list)
+	}
+
+	for _, want := range []string{"name", "street", "city"} {
+		it, err := q.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if it.Content != want || cb.text != want {
+			t.Fatalf("expected %q on clipboard, got item=%q clipboard=%q", want, it.Content, cb.text)
+		}
+		// the watcher sees our own write; it must not be queued again
+		if _, _, err := capSvc.ProcessText(ctx, cb.text); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := q.Next(ctx); !IsEmpty(err) {
+		t.Fatalf("expected empty queue, got %v", err)
+	}
+}
+
+func TestQueueLIFO(t *testing.T) {
+	q, capSvc, _ := setup(t, ModeLIFO)
+	ctx := context.Background()
+	copyAll(t, capSvc, "one", "two", "three")
+
+	list, _ := q.List(ctx)
+	if len(list) != 3 || list[0].Content != "three" {
+		t.Fatalf("expected stack order, got %+v", list)
+	}
+	it, err := q.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Content != "three" {
+		t.Fatalf("expected last copied first, got %q", it.Content)
+	}
+}
+
+func TestQueueOffAndClear(t *testing.T) {
+	q, capSvc, _ := setup(t, ModeFIFO)
+	ctx := context.Background()
+	copyAll(t, capSvc, "kept")
+
+	if err := q.SetMode(ctx, ModeOff); err != nil {
+		t.Fatal(err)
+	}
+	copyAll(t, capSvc, "not queued")
+
+	list, _ := q.List(ctx)
+	if len(list) != 1 || list[0].Content != "kept" {
+		t.Fatalf("expected queue kept while off, got %+v", list)
+	}
+
+	if err := q.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := q.List(ctx); len(list) != 0 {
+		t.Fatalf("expected cleared queue, got %+v", list)
+	}
+	if err := q.SetMode(ctx, "sideways"); err == nil {
+		t.Fatalf("expected invalid mode error")
+	}
+}
+
+func TestQueueAdvance(t *testing.T) {
+	q, capSvc, cb := setup(t, ModeFIFO)
+	ctx := context.Background()
+	copyAll(t, capSvc, "name", "street", "city")
+	list, _ := q.List(ctx)
+
+	// pasting the head by hand doesn't serve it again
+	it, err := q.Advance(ctx, list[0])
+	if err != nil || it.Content != "street" || cb.text != "street" {
+		t.Fatalf("expected street served, got %q (%v), clipboard %q", it.Content, err, cb.text)
+	}
+	// any other paste just moves on
+	if it, err := q.Advance(ctx, list[0]); err != nil || it.Content != "city" {
+		t.Fatalf("expected city served, got %q (%v)", it.Content, err)
+	}
+
+	// a write announced with Written isn't queued when captured
+	if err := q.Written(ctx, list[0]); err != nil {
+		t.Fatal(err)
+	}
+	copyAll(t, capSvc, "other", "name")
+	if list, _ := q.List(ctx); len(list) != 1 || list[0].Content != "other" {
+		t.Fatalf("expected only the new copy queued, got %+v", list)
+	}
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// query is a parsed search string: free text plus optional key:value filters.
+//
+//	docker app:kitty   -> text "docker", source matching "kitty"
+//	lang:go type:code  -> Go code items
+//	tag:work tag:infra -> items tagged with both
+type query struct {
+	text string
+	app  string
+	lang string
+	typ  string
+	tags []string
+}
+
+func parseQuery(q string) query {
+	var out query
+	terms := make([]string, 0, 4)
+
+	for _, f := range strings.Fields(q) {
+		key, val, ok := strings.Cut(f, ":")
+		if ok && val != "" {
+			switch strings.ToLower(key) {
+			case "app":
+				out.app = val
+				continue
+			case "lang":
+				out.lang = strings.ToLower(val)
+				continue
+			case "type":
+				out.typ = strings.ToLower(val)
+				continue
+			case "tag":
+				out.tags = append(out.tags, core.NormalizeTag(val))
+				continue
+			}
+		}
+		terms = append(terms, f)
+	}
+
+	out.text = strings.ToLower(strings.Join(terms, " "))
+	return out
+}
+
+func (q query) empty() bool {
+	return q.text == "" && !q.hasFilters()
+}
+
+func (q query) hasFilters() bool {
+	return q.app != "" || q.lang != "" || q.typ != "" || len(q.tags) > 0
+}
+
+func (q query) accept(it core.Item) bool {
+	if q.app != "" && !it.Source.Matches(q.app) {
+		return false
+	}
+	if q.lang != "" && strings.ToLower(it.Meta[core.MetaLanguage]) != q.lang {
+		return false
+	}
+	if q.typ != "" && string(it.Type) != q.typ {
+		return false
+	}
+	for _, t := range q.tags {
+		if !slices.Contains(it.Tags, t) {
+			return false
+		}
+	}
+	return true
+}
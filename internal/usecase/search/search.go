@@ -17,6 +17,18 @@ type Options struct {
 	ScanLimit int
 	OutLimit  int
 	Now       time.Time // optional, for tests
+
+	// Source restricts results to items copied from a matching app
+	// (same as an "app:" filter in the query).
+	Source string
+
+	// Tags restricts results to items carrying all of these tags (same as
+	// "tag:" filters in the query).
+	Tags []string
+
+	// Type restricts results to one content type (same as a "type:"
+	// filter in the query).
+	Type core.ContentType
 }
 
 type Service struct {
@@ -44,8 +56,17 @@ func (s *Service) Query(ctx context.Context, q string, opt Options) ([]core.Item
 		return nil, err
 	}
 
-	q = strings.ToLower(strings.TrimSpace(q))
-	if q == "" {
+	pq := parseQuery(q)
+	if opt.Source != "" {
+		pq.app = opt.Source
+	}
+	if opt.Type != "" {
+		pq.typ = string(opt.Type)
+	}
+	for _, t := range opt.Tags {
+		pq.tags = append(pq.tags, core.NormalizeTag(t))
+	}
+	if pq.empty() {
 		return nil, nil
 	}
 
@@ -57,13 +78,19 @@ func (s *Service) Query(ctx context.Context, q string, opt Options) ([]core.Item
 	scoredItems := make([]scored, 0, len(items))
 
 	for _, it := range items {
-		content := strings.ToLower(it.Content)
-
-		matchScore := scoreMatch(content, q)
-		if matchScore == 0 {
+		if !pq.accept(it) {
 			continue
 		}
 
+		// Filter-only queries match everything that passes the filters.
+		matchScore := 1
+		if pq.text != "" {
+			matchScore = scoreItem(it, pq.text)
+			if matchScore == 0 {
+				continue
+			}
+		}
+
 		score := matchScore
 
 		// pinned boost
@@ -105,6 +132,25 @@ func (s *Service) Query(ctx context.Context, q string, opt Options) ([]core.Item
 	return out, nil
 }
 
+// titleBand lifts title matches above any content or note match, even
+// with the largest recency boost.
+const titleBand = 4000
+
+// scoreItem scores the best-matching field. Titles are names the user gave
+// an item, so a title match ranks above every content or note match.
+func scoreItem(it core.Item, q string) int {
+	if it.Title != "" {
+		if score := scoreMatch(strings.ToLower(it.Title), q); score > 0 {
+			return titleBand + score
+		}
+	}
+	score := scoreMatch(strings.ToLower(it.Content), q)
+	if it.Note != "" {
+		score = max(score, scoreMatch(strings.ToLower(it.Note), q))
+	}
+	return score
+}
+
 func scoreMatch(s, q string) int {
 	// Basic match:
 	// - exact match strongest
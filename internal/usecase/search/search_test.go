@@ -39,3 +39,128 @@ func TestQuery_PinnedBoost(t *testing.T) {
 		t.Fatalf("expected pinned item first, got %s", got[0].ID)
 	}
 }
+
+func TestQuery_TitleWeightedHighest(t *testing.T) {
+	now := time.Now()
+	items := []core.Item{
+		{ID: "1", Content: "deploy notes for friday", LastSeenAt: now},
+		{ID: "2", Content: "kubectl rollout restart deploy/api", Title: "deploy api", LastSeenAt: now.Add(-time.Hour)},
+		{ID: "3", Content: "make release", Note: "deploy helper", LastSeenAt: now.Add(-2 * time.Hour)},
+	}
+
+	svc := New(fakeStore{items: items})
+	got, err := svc.Query(context.Background(), "deploy", Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected title, content and note matches, got %+v", got)
+	}
+	if got[0].ID != "2" {
+		t.Fatalf("expected title match first, got %s", got[0].ID)
+	}
+}
+
+func TestQuery_TitleOutranksContentPrefix(t *testing.T) {
+	now := time.Now()
+	items := []core.Item{
+		{ID: "1", Content: "staging db password rotation", LastSeenAt: now},
+		{ID: "2", Content: "psql -h 10.0.0.5 -U app", Title: "connect to staging", LastSeenAt: now.Add(-30 * 24 * time.Hour)},
+	}
+
+	svc := New(fakeStore{items: items})
+	got, err := svc.Query(context.Background(), "staging", Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != "2" {
+		t.Fatalf("expected the titled item above the recent content prefix match, got %+v", got)
+	}
+}
+
+func TestQuery_AppFilter(t *testing.T) {
+	now := time.Now()
+	items := []core.Item{
+		{ID: "1", Content: "ls -la", LastSeenAt: now, Source: core.Source{Process: "kitty"}},
+		{ID: "2", Content: "ls is a command", LastSeenAt: now, Source: core.Source{AppID: "org.mozilla.firefox"}},
+		{ID: "3", Content: "git status", LastSeenAt: now.Add(-time.Minute), Source: core.Source{WindowClass: "kitty"}},
+	}
+
+	svc := New(fakeStore{items: items})
+
+	got, err := svc.Query(context.Background(), "ls app:kitty", Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("expected only kitty ls item, got %+v", got)
+	}
+
+	got, err = svc.Query(context.Background(), "", Options{Now: now, Source: "kitty"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 kitty items for filter-only query, got %d", len(got))
+	}
+}
+
+func TestQuery_LangFilter(t *testing.T) {
+	now := time.Now()
+	items := []core.Item{
+		{ID: "1", Content: "func main() {}", Type: core.ContentTypeCode, LastSeenAt: now, Meta: map[string]string{core.MetaLanguage: "go"}},
+		{ID: "2", Content: "def main(): pass", Type: core.ContentTypeCode, LastSeenAt: now, Meta: map[string]string{core.MetaLanguage: "python"}},
+		{ID: "3", Content: "main street", Type: core.ContentTypeText, LastSeenAt: now},
+	}
+
+	svc := New(fakeStore{items: items})
+	got, err := svc.Query(context.Background(), "main lang:go", Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("expected only go item, got %+v", got)
+	}
+
+	got, err = svc.Query(context.Background(), "main type:code", Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 code items, got %d", len(got))
+	}
+
+	got, err = svc.Query(context.Background(), "", Options{Now: now, Type: core.ContentTypeText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "3" {
+		t.Fatalf("expected only the text item, got %+v", got)
+	}
+}
+
+func TestQuery_TagFilter(t *testing.T) {
+	now := time.Now()
+	items := []core.Item{
+		{ID: "1", Content: "deploy staging", LastSeenAt: now, Tags: []string{"infra", "work"}},
+		{ID: "2", Content: "deploy blog", LastSeenAt: now, Tags: []string{"personal"}},
+		{ID: "3", Content: "deploy prod", LastSeenAt: now, Tags: []string{"work"}},
+	}
+
+	svc := New(fakeStore{items: items})
+	got, err := svc.Query(context.Background(), "deploy tag:work tag:Infra", Options{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != "1" {
+		t.Fatalf("expected only item 1, got %+v", got)
+	}
+
+	got, err = svc.Query(context.Background(), "", Options{Now: now, Tags: []string{"work"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 work items, got %d", len(got))
+	}
+}
@@ -0,0 +1,87 @@
+package snippet
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+var ErrInvalidName = errors.New("snippet name must be non-empty and contain no spaces")
+
+// Writer puts text on the clipboard (see clipboard.Writer).
+type Writer interface {
+	WriteText(text string) error
+}
+
+type Service struct {
+	store storage.SnippetStore
+}
+
+func New(store storage.SnippetStore) *Service {
+	return &Service{store: store}
+}
+
+// Create saves body under name, replacing any snippet with that name. The
+// body is checked for malformed placeholders up front.
+func (s *Service) Create(ctx context.Context, name, body string) (core.Snippet, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || strings.ContainsFunc(name, unicode.IsSpace) {
+		return core.Snippet{}, ErrInvalidName
+	}
+	if strings.TrimSpace(body) == "" {
+		return core.Snippet{}, errors.New("empty snippet body")
+	}
+	if _, err := core.ParseTemplate(body); err != nil {
+		return core.Snippet{}, err
+	}
+
+	sn := core.Snippet{Name: name, Body: body}
+	if err := s.store.PutSnippet(ctx, sn); err != nil {
+		return core.Snippet{}, err
+	}
+	return s.store.GetSnippet(ctx, name)
+}
+
+// FromItem turns a history item into a snippet. Braces that do not form a
+// known placeholder are kept as literal text (see core.EscapeTemplate).
+func (s *Service) FromItem(ctx context.Context, name string, it core.Item) (core.Snippet, error) {
+	return s.Create(ctx, name, core.EscapeTemplate(it.Content))
+}
+
+// Inputs lists the {{input:...}} names a snippet will ask for.
+func (s *Service) Inputs(ctx context.Context, name string) ([]string, error) {
+	sn, err := s.store.GetSnippet(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return core.TemplateInputs(sn.Body)
+}
+
+// Expand renders a snippet. UUID defaults to random v4 ids.
+func (s *Service) Expand(ctx context.Context, name string, env core.TemplateEnv) (string, error) {
+	sn, err := s.store.GetSnippet(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if env.UUID == nil {
+		env.UUID = uuid.NewString
+	}
+	return core.ExpandTemplate(sn.Body, env)
+}
+
+// ExpandAndCopy renders a snippet and puts the result on the clipboard.
+func (s *Service) ExpandAndCopy(ctx context.Context, name string, env core.TemplateEnv, w Writer) (string, error) {
+	out, err := s.Expand(ctx, name, env)
+	if err != nil {
+		return "", err
+	}
+	if err := w.WriteText(out); err != nil {
+		return out, err
+	}
+	return out, nil
+}
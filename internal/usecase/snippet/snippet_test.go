@@ -0,0 +1,106 @@
+package snippet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+type fakeClipboard struct {
+	text string
+}
+
+func (f *fakeClipboard) ReadText() (string, error) { return f.text, nil }
+
+func (f *fakeClipboard) WriteText(text string) error {
+	f.text = text
+	return nil
+}
+
+func TestFromItemAndExpandAndCopy(t *testing.T) {
+	st := memory.New()
+	svc := New(st)
+	ctx := context.Background()
+
+	it := core.Item{Content: "kubectl -n {{input:namespace}} logs {{clipboard}} --since={{date}}"}
+	if _, err := svc.FromItem(ctx, "klogs", it); err != nil {
+		t.Fatal(err)
+	}
+
+	inputs, err := svc.Inputs(ctx, "klogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) != 1 || inputs[0] != "namespace" {
+		t.Fatalf("unexpected inputs: %v", inputs)
+	}
+
+	cb := &fakeClipboard{text: "api-7d9f"}
+	env := core.TemplateEnv{
+		Now:       func() time.Time { return time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC) },
+		Clipboard: cb.ReadText,
+		Input:     func(string) (string, error) { return "prod", nil },
+	}
+	out, err := svc.ExpandAndCopy(ctx, "klogs", env, cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "kubectl -n prod logs api-7d9f --since=2024-05-01"
+	if out != want || cb.text != want {
+		t.Fatalf("expected %q copied, got out=%q clipboard=%q", want, out, cb.text)
+	}
+
+	// snippets are not history
+	if n, _ := st.Count(ctx); n != 0 {
+		t.Fatalf("expected no history items, got %d", n)
+	}
+}
+
+func TestFromItemKeepsForeignTemplates(t *testing.T) {
+	svc := New(memory.New())
+	ctx := context.Background()
+
+	helm := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: {{ include \"app.fullname\" . }}\nspec:\n  replicas: {{ .Values.replicaCount }}\n"
+	if _, err := svc.FromItem(ctx, "deploy", core.Item{Content: helm}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := svc.Expand(ctx, "deploy", core.TemplateEnv{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != helm {
+		t.Fatalf("expected the Helm template back unchanged, got\n%s", out)
+	}
+}
+
+func TestCreateValidates(t *testing.T) {
+	svc := New(memory.New())
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "two words", "x"); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf("expected ErrInvalidName, got %v", err)
+	}
+	if _, err := svc.Create(ctx, "bad", "hello {{nope}}"); err == nil {
+		t.Fatalf("expected malformed template to be rejected")
+	}
+}
+
+func TestExpandDefaultsUUID(t *testing.T) {
+	svc := New(memory.New())
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "id", "{{uuid}}"); err != nil {
+		t.Fatal(err)
+	}
+	out, err := svc.Expand(ctx, "id", core.TemplateEnv{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 36 {
+		t.Fatalf("expected a uuid, got %q", out)
+	}
+}
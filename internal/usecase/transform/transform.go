@@ -0,0 +1,124 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+// ErrUnchanged is returned by Save when the result is the original item's
+// own content.
+var ErrUnchanged = errors.New("result is the same as the original")
+
+// Writer puts text on the clipboard (see clipboard.Writer).
+type Writer interface {
+	WriteText(text string) error
+}
+
+type Service struct {
+	store storage.Store
+}
+
+func New(store storage.Store) *Service {
+	return &Service{store: store}
+}
+
+// Apply runs the named transform on the item's content.
+func (s *Service) Apply(it core.Item, name string) (string, error) {
+	_, out, err := apply(it, name)
+	return out, err
+}
+
+func apply(it core.Item, name string) (Transform, string, error) {
+	t, ok := Lookup(name)
+	if !ok {
+		return t, "", fmt.Errorf("unknown transform %q (expected %s)", name, strings.Join(Names(), "|"))
+	}
+	out, err := t.Apply(it.Content)
+	if err != nil {
+		return t, "", fmt.Errorf("%s: %w", t.Name, err)
+	}
+	return t, out, nil
+}
+
+// Copy applies a transform and puts the result on the clipboard.
+func (s *Service) Copy(it core.Item, name string, w Writer) (string, error) {
+	out, err := s.Apply(it, name)
+	if err != nil {
+		return "", err
+	}
+	return out, w.WriteText(out)
+}
+
+// Save applies a transform and stores the result as a new item linked to
+// the original through Meta. The content is kept verbatim so multi-line
+// output (pretty JSON, sorted lines) survives; dedupe still works on the
+// normalized fingerprint. When the result is already in history, Save
+// returns that item (restored if it was trashed) rather than overwriting
+// it with the derived one.
+func (s *Service) Save(ctx context.Context, it core.Item, name string) (core.Item, error) {
+	t, out, err := apply(it, name)
+	if err != nil {
+		return core.Item{}, err
+	}
+	if strings.TrimSpace(out) == "" {
+		return core.Item{}, fmt.Errorf("%s: empty result", t.Name)
+	}
+	fp := core.Fingerprint(core.Normalize(out))
+	if fp == it.Fingerprint {
+		return core.Item{}, ErrUnchanged
+	}
+	if finder, ok := s.store.(storage.ItemFinder); ok {
+		existing, err := finder.FindByFingerprint(ctx, fp)
+		switch {
+		case err == nil && existing.ID == it.ID:
+			return core.Item{}, ErrUnchanged
+		case err == nil:
+			return s.restore(ctx, existing)
+		case !errors.Is(err, storage.ErrNotFound):
+			return core.Item{}, err
+		}
+	}
+
+	now := s.store.Now()
+	derived := core.Item{
+		ID:          uuid.NewString(),
+		Content:     out,
+		Type:        core.DetectType(out),
+		Fingerprint: fp,
+		CreatedAt:   now,
+		LastSeenAt:  now,
+		Meta: map[string]string{
+			core.MetaDerivedFrom: it.ID,
+			core.MetaTransform:   t.Name,
+		},
+	}
+	if lang := core.ItemLanguage(derived.Type, out); lang != "" {
+		derived.Meta[core.MetaLanguage] = string(lang)
+	}
+
+	if err := s.store.Put(ctx, derived, storage.PutInsert); err != nil {
+		return core.Item{}, err
+	}
+	return derived, nil
+}
+
+// restore takes an item out of the trash, if it is there and the store has
+// one.
+func (s *Service) restore(ctx context.Context, it core.Item) (core.Item, error) {
+	ts, ok := s.store.(storage.TrashStore)
+	if !ok || it.DeletedAt.IsZero() {
+		return it, nil
+	}
+	if err := ts.Restore(ctx, it.ID); err != nil {
+		return core.Item{}, err
+	}
+	it.DeletedAt = time.Time{}
+	return it, nil
+}
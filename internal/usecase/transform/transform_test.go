@@ -0,0 +1,111 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/its-jojoo/otterclip/internal/adapter/storage"
+	"github.com/its-jojoo/otterclip/internal/adapter/storage/memory"
+	"github.com/its-jojoo/otterclip/internal/core"
+)
+
+type fakeClipboard struct {
+	text string
+}
+
+func (f *fakeClipboard) WriteText(text string) error {
+	f.text = text
+	return nil
+}
+
+func TestCopy(t *testing.T) {
+	svc := New(memory.New())
+	cb := &fakeClipboard{}
+
+	out, err := svc.Copy(core.Item{Content: "aGVsbG8="}, "base64-decode", cb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "hello" || cb.text != "hello" {
+		t.Fatalf("expected decoded text on clipboard, got %q / %q", out, cb.text)
+	}
+
+	if _, err := svc.Copy(core.Item{Content: "x"}, "rot13", cb); err == nil {
+		t.Fatalf("expected unknown transform error")
+	}
+}
+
+func TestSaveLinksToOriginal(t *testing.T) {
+	st := memory.New()
+	svc := New(st)
+	ctx := context.Background()
+
+	orig := core.Item{ID: "orig-1", Content: `{"name":"otter","tags":["a","b"]}`, Type: core.ContentTypeJSON}
+	it, err := svc.Save(ctx, orig, "json-pretty")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if it.Meta[core.MetaDerivedFrom] != "orig-1" || it.Meta[core.MetaTransform] != "json-pretty" {
+		t.Fatalf("expected link to original, got %v", it.Meta)
+	}
+	if it.Type != core.ContentTypeJSON {
+		t.Fatalf("expected json type, got %s", it.Type)
+	}
+	if it.Fingerprint != core.Fingerprint(core.Normalize(it.Content)) {
+		t.Fatalf("expected fingerprint of normalized content")
+	}
+
+	recent, err := st.ListRecent(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recent) != 1 || recent[0].Content != it.Content {
+		t.Fatalf("expected saved item with verbatim content, got %+v", recent)
+	}
+}
+
+func TestSaveReturnsExistingItem(t *testing.T) {
+	st := memory.New()
+	svc := New(st)
+	ctx := context.Background()
+
+	put := func(id, content string) core.Item {
+		t.Helper()
+		it := core.Item{ID: id, Content: content, Type: core.ContentTypeText, Fingerprint: core.Fingerprint(content),
+			LastSeenAt: time.Now(), Source: core.Source{AppID: "firefox"}}
+		if err := st.Put(ctx, it, storage.PutInsert); err != nil {
+			t.Fatal(err)
+		}
+		return it
+	}
+	upper := put("upper", "HELLO OTTER")
+	lower := put("lower", "hello otter")
+	if err := st.Trash(ctx, upper.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.Save(ctx, lower, "upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != upper.ID || !got.DeletedAt.IsZero() || got.Meta[core.MetaDerivedFrom] != "" {
+		t.Fatalf("expected the existing item back, restored, got %+v", got)
+	}
+	stored, err := st.FindByFingerprint(ctx, upper.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Source.AppID != "firefox" || stored.Meta[core.MetaDerivedFrom] != "" || !stored.DeletedAt.IsZero() {
+		t.Fatalf("existing item was overwritten or left in the trash: %+v", stored)
+	}
+	if n, _ := st.Count(ctx); n != 2 {
+		t.Fatalf("expected 2 items, got %d", n)
+	}
+
+	if _, err := svc.Save(ctx, lower, "lower"); !errors.Is(err, ErrUnchanged) {
+		t.Fatalf("expected ErrUnchanged, got %v", err)
+	}
+}
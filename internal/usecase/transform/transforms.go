@@ -0,0 +1,324 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Func rewrites text. Transforms are pure; errors mean the input does not
+// fit (e.g. invalid JSON).
+type Func func(s string) (string, error)
+
+type Transform struct {
+	Name        string
+	Description string
+	Apply       Func
+}
+
+var registry = []Transform{
+	{"trim", "trim surrounding whitespace on every line", trim},
+	{"upper", "UPPER CASE", pure(strings.ToUpper)},
+	{"lower", "lower case", pure(strings.ToLower)},
+	{"title", "Title Case", pure(titleCase)},
+	{"camel", "camelCase", pure(func(s string) string { return joinWords(s, "", camel) })},
+	{"pascal", "PascalCase", pure(func(s string) string { return joinWords(s, "", capitalized) })},
+	{"snake", "snake_case", pure(func(s string) string { return joinWords(s, "_", lower) })},
+	{"kebab", "kebab-case", pure(func(s string) string { return joinWords(s, "-", lower) })},
+	{"json-pretty", "indent JSON", jsonPretty},
+	{"json-minify", "compact JSON", jsonMinify},
+	{"yaml-pretty", "YAML (or JSON) as block YAML", yamlPretty},
+	{"yaml-minify", "YAML (or JSON) as flow YAML", yamlMinify},
+	{"base64-encode", "base64 encode", pure(func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) })},
+	{"base64-decode", "base64 decode (standard or URL alphabet)", base64Decode},
+	{"url-encode", "percent-encode for a query value", pure(url.QueryEscape)},
+	{"url-decode", "decode percent-encoding", url.QueryUnescape},
+	{"html-encode", "escape HTML entities", pure(html.EscapeString)},
+	{"html-decode", "unescape HTML entities", pure(html.UnescapeString)},
+	{"sort-lines", "sort lines", sortLines},
+	{"uniq-lines", "drop repeated lines, keeping the first", uniqLines},
+	{"strip-ansi", "remove ANSI escape sequences", pure(stripANSI)},
+	{"slugify", "url-friendly slug", pure(func(s string) string { return joinWords(asciiFold(s), "-", lower) })},
+	{"jwt-decode", "decode JWT header and claims (no verification)", jwtDecode},
+}
+
+// All returns the built-in transforms in display order.
+func All() []Transform {
+	return slices.Clone(registry)
+}
+
+func Lookup(name string) (Transform, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, t := range registry {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Transform{}, false
+}
+
+func Names() []string {
+	out := make([]string, len(registry))
+	for i, t := range registry {
+		out[i] = t.Name
+	}
+	return out
+}
+
+func pure(f func(string) string) Func {
+	return func(s string) (string, error) { return f(s), nil }
+}
+
+func trim(s string) (string, error) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// words splits identifiers and prose into words: separators, case changes
+// ("parseHTTPHeader" -> parse HTTP Header) and letter/digit boundaries.
+func words(s string) []string {
+	var out []string
+	rs := []rune(s)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end > start {
+			out = append(out, string(rs[start:end]))
+		}
+		start = -1
+	}
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := rs[i-1]
+		switch {
+		case unicode.IsLower(prev) && unicode.IsUpper(r):
+			flush(i)
+			start = i
+		case unicode.IsUpper(prev) && unicode.IsUpper(r) && i+1 < len(rs) && unicode.IsLower(rs[i+1]):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(rs))
+	return out
+}
+
+func joinWords(s, sep string, f func(i int, w string) string) string {
+	ws := words(s)
+	for i, w := range ws {
+		ws[i] = f(i, w)
+	}
+	return strings.Join(ws, sep)
+}
+
+func lower(_ int, w string) string { return strings.ToLower(w) }
+
+func capitalized(_ int, w string) string {
+	rs := []rune(strings.ToLower(w))
+	rs[0] = unicode.ToUpper(rs[0])
+	return string(rs)
+}
+
+func camel(i int, w string) string {
+	if i == 0 {
+		return lower(i, w)
+	}
+	return capitalized(i, w)
+}
+
+func titleCase(s string) string {
+	var b strings.Builder
+	prevLetter := false
+	for _, r := range s {
+		if unicode.IsLetter(r) && !prevLetter {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+		prevLetter = unicode.IsLetter(r) || r == '\''
+	}
+	return b.String()
+}
+
+var foldMap = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ý': "y", 'ÿ': "y", 'ß': "ss",
+}
+
+// asciiFold maps common accented Latin letters to ASCII and drops other
+// non-ASCII letters.
+func asciiFold(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r < unicode.MaxASCII:
+			b.WriteRune(r)
+		case foldMap[r] != "":
+			b.WriteString(foldMap[r])
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+func jsonPretty(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(strings.TrimSpace(s)), "", "  "); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func jsonMinify(s string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(strings.TrimSpace(s))); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func parseYAML(s string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if doc.Kind == 0 {
+		return nil, errors.New("invalid YAML: empty document")
+	}
+	return &doc, nil
+}
+
+// setStyle switches collections between block and flow style and drops
+// quoting the encoder does not need (JSON input quotes every key).
+func setStyle(n *yaml.Node, flow bool) {
+	switch n.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		if flow {
+			n.Style |= yaml.FlowStyle
+		} else {
+			n.Style &^= yaml.FlowStyle
+		}
+	case yaml.ScalarNode:
+		n.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+	}
+	for _, c := range n.Content {
+		setStyle(c, flow)
+	}
+}
+
+func encodeYAML(s string, flow bool) (string, error) {
+	doc, err := parseYAML(s)
+	if err != nil {
+		return "", err
+	}
+	setStyle(doc, flow)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func yamlPretty(s string) (string, error) { return encodeYAML(s, false) }
+
+func yamlMinify(s string) (string, error) { return encodeYAML(s, true) }
+
+func base64Decode(s string) (string, error) {
+	s = strings.Join(strings.Fields(s), "")
+	for _, enc := range []*base64.Encoding{
+		base64.StdEncoding, base64.URLEncoding,
+		base64.RawStdEncoding, base64.RawURLEncoding,
+	} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return string(b), nil
+		}
+	}
+	return "", errors.New("invalid base64")
+}
+
+// errSingleLine rejects line transforms on content without line breaks;
+// captured clips are stored on a single line.
+var errSingleLine = errors.New("content is a single line")
+
+func sortLines(s string) (string, error) {
+	if !strings.Contains(s, "\n") {
+		return "", errSingleLine
+	}
+	lines := strings.Split(s, "\n")
+	slices.Sort(lines)
+	return strings.Join(lines, "\n"), nil
+}
+
+func uniqLines(s string) (string, error) {
+	if !strings.Contains(s, "\n") {
+		return "", errSingleLine
+	}
+	lines := strings.Split(s, "\n")
+	seen := make(map[string]bool, len(lines))
+	out := lines[:0]
+	for _, l := range lines {
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+		out = append(out, l)
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+// CSI sequences (colors, cursor moves) and OSC sequences (titles, links).
+var reANSI = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(\x07|\x1b\\)`)
+
+func stripANSI(s string) string {
+	return reANSI.ReplaceAllString(s, "")
+}
+
+func jwtDecode(s string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(s), ".")
+	if len(parts) != 3 {
+		return "", errors.New("invalid JWT: expected header.payload.signature")
+	}
+
+	var out []string
+	for i, name := range []string{"header", "payload"} {
+		raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[i], "="))
+		if err != nil {
+			return "", fmt.Errorf("invalid JWT %s: %w", name, err)
+		}
+		pretty, err := jsonPretty(string(raw))
+		if err != nil {
+			return "", fmt.Errorf("invalid JWT %s: %w", name, err)
+		}
+		out = append(out, pretty)
+	}
+	return strings.Join(out, "\n"), nil
+}
@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransforms(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"trim", "  a  \n   b ", "a\nb"},
+		{"upper", "hello", "HELLO"},
+		{"title", "the quick BROWN fox", "The Quick Brown Fox"},
+		{"camel", "parse HTTP header", "parseHttpHeader"},
+		{"pascal", "user_id", "UserId"},
+		{"snake", "parseHTTPHeader", "parse_http_header"},
+		{"kebab", "Some Title 2", "some-title-2"},
+		{"json-pretty", `{"a":1,"b":[true]}`, "{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}"},
+		{"json-minify", "{\n  \"a\": 1\n}", `{"a":1}`},
+		{"yaml-pretty", `{"a": 1, "b": [x, y]}`, "a: 1\nb:\n  - x\n  - y"},
+		{"yaml-minify", "a: 1\nb:\n  - x\n  - y\n", "{a: 1, b: [x, y]}"},
+		{"base64-encode", "hi there", "aGkgdGhlcmU="},
+		{"base64-decode", "aGkgdGhlcmU", "hi there"},
+		{"url-encode", "a b&c", "a+b%26c"},
+		{"url-decode", "a%20b%26c", "a b&c"},
+		{"html-encode", `<a href="x">`, "&lt;a href=&#34;x&#34;&gt;"},
+		{"html-decode", "&lt;b&gt; &amp;", "<b> &"},
+		{"sort-lines", "b\nc\na", "a\nb\nc"},
+		{"uniq-lines", "a\nb\na\nc\nb", "a\nb\nc"},
+		{"strip-ansi", "\x1b[1;31merror\x1b[0m: boom", "error: boom"},
+		{"slugify", "Héllo, Wörld! It's 2024", "hello-world-it-s-2024"},
+		{"jwt-decode", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxMjMifQ.sig", "{\n  \"alg\": \"HS256\"\n}\n{\n  \"sub\": \"123\"\n}"},
+	}
+	for _, c := range cases {
+		tr, ok := Lookup(c.name)
+		if !ok {
+			t.Fatalf("transform %q not registered", c.name)
+		}
+		got, err := tr.Apply(c.in)
+		if err != nil {
+			t.Fatalf("%s(%q): %v", c.name, c.in, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s(%q) = %q, want %q", c.name, c.in, got, c.want)
+		}
+	}
+}
+
+func TestTransformErrors(t *testing.T) {
+	for name, in := range map[string]string{
+		"json-pretty":   "{nope",
+		"yaml-pretty":   "a: [1, 2",
+		"base64-decode": "not*base64",
+		"url-decode":    "%zz",
+		"jwt-decode":    "a.b",
+		"sort-lines":    "b a c",
+		"uniq-lines":    "a a",
+	} {
+		tr, _ := Lookup(name)
+		if _, err := tr.Apply(in); err == nil {
+			t.Fatalf("expected %s to reject %q", name, in)
+		}
+	}
+}
+
+func TestLookupIsCaseInsensitive(t *testing.T) {
+	if _, ok := Lookup(" JSON-Pretty "); !ok {
+		t.Fatalf("expected lookup to ignore case and spaces")
+	}
+	if len(Names()) != len(All()) || !strings.Contains(strings.Join(Names(), ","), "jwt-decode") {
+		t.Fatalf("unexpected names: %v", Names())
+	}
+}
+
+func TestYAMLKeepsAmbiguousStringsQuoted(t *testing.T) {
+	tr, _ := Lookup("yaml-pretty")
+	got, err := tr.Apply(`{"port": "8080", "enabled": "true", "n": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "port: \"8080\"\nenabled: \"true\"\nn: 1"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
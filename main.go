@@ -1,28 +1,45 @@
 package main
 
 import (
+	"context"
 	"embed"
+	"os"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/its-jojoo/otterclip/internal/desktop"
 )
 
 //go:embed all:ui/dist
 var assets embed.FS
 
 func main() {
-	app := NewApp()
+	app, store, err := newApp()
+	if err != nil {
+		println("Error:", err.Error())
+		os.Exit(1)
+	}
+	defer store.Close()
 
-	err := wails.Run(&options.App{
-		Title:     "OtterClip",
-		Width:     720,
-		Height:    240,
+	err = wails.Run(&options.App{
+		Title:  "OtterClip",
+		Width:  720,
+		Height: 420,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 15, G: 23, B: 42, A: 1},
-		OnStartup:        app.startup,
+		OnStartup: func(ctx context.Context) {
+			if err := desktop.Startup(app, ctx); err != nil {
+				runtime.LogErrorf(ctx, "startup: %v", err)
+			}
+		},
+		OnShutdown: func(ctx context.Context) {
+			desktop.Shutdown(app)
+		},
 		Bind: []interface{}{
 			app,
 		},
@@ -1,25 +1,185 @@
-import {useState} from 'react';
-import logo from './assets/images/logo-universal.png';
+import {KeyboardEvent, useCallback, useEffect, useRef, useState} from 'react';
 import './App.css';
-import {Greet} from "../wailsjs/go/main/App";
+import {Copy, Delete, Hide, List, Paste, Pin, Restore, SaveSettings, Search, Settings} from "../wailsjs/go/desktop/App";
+import {core, desktop} from "../wailsjs/go/models";
+import {EventsOn} from "../wailsjs/runtime/runtime";
+
+const LIMIT = 100;
+
+function preview(s: string, max: number) {
+    const line = s.replace(/\s+/g, ' ').trim();
+    return line.length > max ? line.slice(0, max - 1) + '…' : line;
+}
+
+function typeLabel(it: core.Item) {
+    const lang = it.meta?.lang;
+    return lang ? `${it.type}:${lang}` : it.type;
+}
 
 function App() {
-    const [resultText, setResultText] = useState("Please enter your name below 👇");
-    const [name, setName] = useState('');
-    const updateName = (e: any) => setName(e.target.value);
-    const updateResultText = (result: string) => setResultText(result);
+    const [query, setQuery] = useState('');
+    const [items, setItems] = useState<core.Item[]>([]);
+    const [cursor, setCursor] = useState(0);
+    const [status, setStatus] = useState('');
+    const [settings, setSettings] = useState<desktop.Settings | null>(null);
+    const [showSettings, setShowSettings] = useState(false);
+    const trashed = useRef<string[]>([]);
+    const input = useRef<HTMLInputElement>(null);
+
+    const load = useCallback(() => {
+        const run = query.trim() === '' ? List(LIMIT) : Search(query, LIMIT);
+        run.then(found => {
+            setItems(found);
+            setCursor(c => Math.min(c, Math.max(found.length - 1, 0)));
+        }).catch(err => setStatus(String(err)));
+    }, [query]);
+
+    useEffect(() => {
+        setCursor(0);
+        load();
+    }, [load]);
+
+    useEffect(() => {
+        const offChanged = EventsOn('history:changed', load);
+        const offError = EventsOn('app:error', (msg: string) => setStatus(msg));
+        const offShown = EventsOn('launcher:shown', () => input.current?.select());
+        // e.g. paused from the tray
+        const offSettings = EventsOn('settings:changed', (s: any) => setSettings(desktop.Settings.createFrom(s)));
+        return () => {
+            offChanged();
+            offError();
+            offShown();
+            offSettings();
+        };
+    }, [load]);
+
+    useEffect(() => {
+        Settings().then(setSettings);
+        input.current?.focus();
+    }, []);
+
+    const selected = items[cursor];
 
-    function greet() {
-        Greet(name).then(updateResultText);
+    function act(p: Promise<void>, done: string) {
+        p.then(() => setStatus(done)).catch(err => setStatus(String(err)));
+    }
+
+    function onKey(e: KeyboardEvent) {
+        if (e.target !== input.current) {
+            return; // typing in the settings form
+        }
+        const mod = e.ctrlKey || e.metaKey;
+        if (e.key === 'ArrowDown') {
+            setCursor(c => Math.min(c + 1, items.length - 1));
+        } else if (e.key === 'ArrowUp') {
+            setCursor(c => Math.max(c - 1, 0));
+        } else if (e.key === 'Enter' && e.shiftKey && selected) {
+            act(Copy(selected.id).then(Hide), 'copied');
+        } else if (e.key === 'Enter' && selected) {
+            act(Paste(selected.id), 'pasted');
+        } else if (mod && e.key === 'p' && selected) {
+            act(Pin(selected.id, !selected.pinned), selected.pinned ? 'unpinned' : 'pinned');
+        } else if (e.key === 'Delete' && selected) {
+            if (selected.pinned) {
+                setStatus('unpin before deleting');
+            } else {
+                trashed.current.push(selected.id);
+                act(Delete(selected.id), 'moved to trash (ctrl+z undoes)');
+            }
+        } else if (mod && e.key === 'z' && trashed.current.length > 0) {
+            act(Restore(trashed.current.pop()!), 'restored');
+        } else if (e.key === 'Escape') {
+            if (query === '') {
+                Hide();
+            } else {
+                setQuery('');
+            }
+        } else {
+            return;
+        }
+        e.preventDefault();
+    }
+
+    function saveSettings(s: desktop.Settings) {
+        SaveSettings(s)
+            .then(() => {
+                setSettings(s);
+                setStatus('settings saved');
+            })
+            .catch(err => {
+                // the rest may have been saved even if the hotkey wasn't
+                Settings().then(setSettings);
+                setStatus(String(err));
+            });
     }
 
     return (
-        <div id="App">
-            <img src={logo} id="logo" alt="logo"/>
-            <div id="result" className="result">{resultText}</div>
-            <div id="input" className="input-box">
-                <input id="name" className="input" onChange={updateName} autoComplete="off" name="input" type="text"/>
-                <button className="btn" onClick={greet}>Greet</button>
+        <div id="App" onKeyDown={onKey}>
+            <div className="bar">
+                <input ref={input} className="query" value={query} placeholder="Search clipboard… (app: type: tag: lang:)"
+                       onChange={e => setQuery(e.target.value)} autoComplete="off" spellCheck={false}/>
+                <button className="gear" title="Settings" onClick={() => setShowSettings(s => !s)}>⚙</button>
+            </div>
+
+            {showSettings && settings && (
+                <div className="settings">
+                    <label>
+                        <input type="checkbox" checked={settings.paused}
+                               onChange={e => saveSettings({...settings, paused: e.target.checked})}/>
+                        Pause capturing
+                    </label>
+                    <label>
+                        Ignore clips containing (one per line)
+                        <textarea defaultValue={(settings.ignorePatterns ?? []).join('\n')}
+                                  onBlur={e => saveSettings({
+                                      ...settings,
+                                      ignorePatterns: e.target.value.split('\n').map(s => s.trim()).filter(Boolean),
+                                  })}/>
+                    </label>
+                    <label>
+                        Launcher hotkey (taken from every app, so avoid Ctrl+Shift+V and other terminal keys)
+                        <input className="hotkey" defaultValue={settings.hotkey} placeholder="Ctrl+Alt+V"
+                               onBlur={e => saveSettings({...settings, hotkey: e.target.value.trim()})}/>
+                    </label>
+                    <label>
+                        Paste keys per app (app=keys, one per line; terminals use Ctrl+Shift+V)
+                        <textarea defaultValue={settings.pasteChords} placeholder="emacs=Ctrl+Y"
+                                  onBlur={e => saveSettings({...settings, pasteChords: e.target.value.trim()})}/>
+                    </label>
+                    <label>
+                        <input type="checkbox" checked={settings.ignoreRegex}
+                               onChange={e => saveSettings({...settings, ignoreRegex: e.target.checked})}/>
+                        Patterns are regular expressions
+                    </label>
+                </div>
+            )}
+
+            <div className="main">
+                <ul className="list">
+                    {items.length === 0 && <li className="empty">Nothing here yet</li>}
+                    {items.map((it, i) => (
+                        <li key={it.id} className={i === cursor ? 'selected' : ''}
+                            onClick={() => {
+                                setCursor(i);
+                                input.current?.focus();
+                            }} onDoubleClick={() => act(Copy(it.id), 'copied')}>
+                            <span className="pin">{it.pinned ? '★' : ''}</span>
+                            <span className="type">{typeLabel(it)}</span>
+                            <span className="text">{it.title ? `${it.title} — ` : ''}{preview(it.content, 120)}</span>
+                        </li>
+                    ))}
+                </ul>
+                {selected && (
+                    <div className="preview">
+                        {selected.title && <h3>{selected.title}</h3>}
+                        {selected.note && <p className="note">{selected.note}</p>}
+                        <pre>{selected.content}</pre>
+                    </div>
+                )}
+            </div>
+
+            <div className="status">
+                {status || 'enter paste · shift+enter copy · ctrl+p pin · del trash · ctrl+z undo · esc close'}
             </div>
         </div>
     )
@@ -0,0 +1,119 @@
+export namespace core {
+	
+	export class Source {
+	    app_id?: string;
+	    window_class?: string;
+	    process?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Source(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.app_id = source["app_id"];
+	        this.window_class = source["window_class"];
+	        this.process = source["process"];
+	    }
+	}
+	export class Item {
+	    id: string;
+	    content: string;
+	    type: string;
+	    fingerprint: string;
+	    // Go type: time
+	    created_at: any;
+	    // Go type: time
+	    last_seen_at: any;
+	    pinned: boolean;
+	    title?: string;
+	    note?: string;
+	    source?: Source;
+	    // Go type: time
+	    expires_at?: any;
+	    // Go type: time
+	    deleted_at?: any;
+	    meta?: Record<string, string>;
+	    tags?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Item(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.content = source["content"];
+	        this.type = source["type"];
+	        this.fingerprint = source["fingerprint"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.last_seen_at = this.convertValues(source["last_seen_at"], null);
+	        this.pinned = source["pinned"];
+	        this.title = source["title"];
+	        this.note = source["note"];
+	        this.source = this.convertValues(source["source"], Source);
+	        this.expires_at = this.convertValues(source["expires_at"], null);
+	        this.deleted_at = this.convertValues(source["deleted_at"], null);
+	        this.meta = source["meta"];
+	        this.tags = source["tags"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace desktop {
+	
+	export class Settings {
+	    paused: boolean;
+	    ignorePatterns: string[];
+	    ignoreRegex: boolean;
+	    hotkey: string;
+	    pasteChords: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.paused = source["paused"];
+	        this.ignorePatterns = source["ignorePatterns"];
+	        this.ignoreRegex = source["ignoreRegex"];
+	        this.hotkey = source["hotkey"];
+	        this.pasteChords = source["pasteChords"];
+	    }
+	}
+	export class TransformInfo {
+	    name: string;
+	    description: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TransformInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	    }
+	}
+
+}